@@ -0,0 +1,115 @@
+// Command backfill-commanders resolves the commander names of existing decks and rankings to canonical commanders
+// It reads the card database from a Scryfall bulk data file and reports the names it can't match
+// Only names matching a card apart from case and punctuation are rewritten, a dry run also suggests
+// commanders for short names and typos as -mapping flags, which resolve those names once reviewed
+//
+//	export POSTGRES_DSN="host=localhost user=postgres password=postgres dbname=mtgtracker port=5432 sslmode=disable"
+//	export SCRYFALL_BULK_FILE=oracle-cards.json
+//	go run ./cmd/backfill-commanders -dry-run
+//	go run ./cmd/backfill-commanders -mapping "Atraxa=Atraxa, Praetors' Voice"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"mtgtracker/internal/core"
+	"mtgtracker/pkg/cards"
+	"os"
+	"sort"
+	"strings"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// nameMapping maps commander names written by hand to canonical card names, one name=canonical flag at a time
+type nameMapping map[string]string
+
+func (m nameMapping) String() string {
+	return fmt.Sprint(map[string]string(m))
+}
+
+func (m nameMapping) Set(value string) error {
+	name, canonical, ok := strings.Cut(value, "=")
+	if !ok || name == "" || canonical == "" {
+		return fmt.Errorf("expected name=canonical, got %q", value)
+	}
+	m[name] = canonical
+	return nil
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be resolved without changing anything")
+	mapping := nameMapping{}
+	flag.Var(mapping, "mapping", "resolve a name to a card, as name=canonical, can be repeated")
+	flag.Parse()
+
+	path := os.Getenv("SCRYFALL_BULK_FILE")
+	if path == "" {
+		log.Fatal("SCRYFALL_BULK_FILE is required")
+	}
+	log.Println("loading card database")
+	cardDB, err := cards.Load(path)
+	if err != nil {
+		log.Fatal("failed to load card database", err)
+	}
+	log.Printf("loaded %d cards", cardDB.Len())
+	for name, canonical := range mapping {
+		if _, ok := cardDB.Get(canonical); !ok {
+			log.Fatalf("mapping %q: no card named %q", name, canonical)
+		}
+	}
+
+	log.Println("initializing database")
+	db, err := gorm.Open(postgres.Open(os.Getenv("POSTGRES_DSN")), &gorm.Config{})
+	if err != nil {
+		log.Fatal("failed to connect to database", err)
+	}
+	repo := core.NewRepository(db)
+
+	report, err := repo.BackfillCommanders(func(name string) (*core.Commander, bool) {
+		if canonical, ok := mapping[name]; ok {
+			name = canonical
+		}
+		card, ok := cardDB.Get(name)
+		if !ok {
+			return nil, false
+		}
+		return &core.Commander{
+			OracleID:       card.OracleID,
+			Name:           card.Name,
+			ColorIdentity:  card.ColorIdentity,
+			Image:          card.Image,
+			SecondaryImage: card.SecondaryImage,
+			Crop:           card.Crop,
+		}, true
+	}, *dryRun)
+	if err != nil {
+		log.Fatal("failed to backfill commanders", err)
+	}
+
+	resolved := make([]string, 0, len(report.Resolved))
+	for name := range report.Resolved {
+		resolved = append(resolved, name)
+	}
+	sort.Strings(resolved)
+	for _, name := range resolved {
+		if report.Resolved[name] != name {
+			fmt.Printf("resolved  %q -> %q\n", name, report.Resolved[name])
+		}
+	}
+	for _, name := range report.UnmatchedNames() {
+		if card, ok := cardDB.ResolveCommander(name); ok && *dryRun {
+			fmt.Printf("unmatched %q (%d), did you mean -mapping %q?\n", name, report.Unmatched[name], name+"="+card.Name)
+			continue
+		}
+		fmt.Printf("unmatched %q (%d)\n", name, report.Unmatched[name])
+	}
+
+	action := "resolved"
+	if *dryRun {
+		action = "would resolve"
+	}
+	fmt.Printf("%s %d names, %d names could not be matched\n", action, len(report.Resolved), len(report.Unmatched))
+}
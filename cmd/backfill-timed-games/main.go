@@ -0,0 +1,57 @@
+// Command backfill-timed-games counts the timed games of statistics recorded before they were counted
+// It rebuilds the statistics of the players whose game durations can't be averaged yet from their confirmed games
+//
+//	export POSTGRES_DSN="host=localhost user=postgres password=postgres dbname=mtgtracker port=5432 sslmode=disable"
+//	go run ./cmd/backfill-timed-games -dry-run
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"mtgtracker/internal/core"
+	"mtgtracker/internal/statistics"
+	"os"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "list the players whose statistics would be rebuilt without changing anything")
+	flag.Parse()
+
+	log.Println("initializing database")
+	db, err := gorm.Open(postgres.Open(os.Getenv("POSTGRES_DSN")), &gorm.Config{})
+	if err != nil {
+		log.Fatal("failed to connect to database", err)
+	}
+	statsRepo := statistics.NewRepository(db)
+	// Rebuilding only reads games, nothing is published
+	statsHandlers := statistics.NewEventHandlers(statsRepo, core.NewService(core.NewRepository(db), nil, nil))
+
+	playerIDs, err := statsRepo.GetPlayersWithoutTimedGames()
+	if err != nil {
+		log.Fatal("failed to find statistics without timed games", err)
+	}
+
+	failed := 0
+	for _, playerID := range playerIDs {
+		if *dryRun {
+			fmt.Printf("would rebuild %s\n", playerID)
+			continue
+		}
+		if err := statsHandlers.RebuildPlayerStats(playerID); err != nil {
+			log.Printf("failed to rebuild the statistics of %s: %v", playerID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("rebuilt %s\n", playerID)
+	}
+
+	action := "rebuilt"
+	if *dryRun {
+		action = "would rebuild"
+	}
+	fmt.Printf("%s the statistics of %d players, %d failed\n", action, len(playerIDs)-failed, failed)
+}
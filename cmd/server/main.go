@@ -6,11 +6,13 @@ import (
 	"mtgtracker/internal/core"
 	"mtgtracker/internal/events"
 	"mtgtracker/internal/feed"
+	"mtgtracker/internal/live"
 	"mtgtracker/internal/middleware"
 	"mtgtracker/internal/notification"
 	"mtgtracker/internal/opponents"
 	"mtgtracker/internal/push"
 	"mtgtracker/internal/statistics"
+	"mtgtracker/pkg/cards"
 	"mtgtracker/pkg/moxfield"
 	"net/http"
 	"os"
@@ -78,11 +80,27 @@ func main() {
 
 	// // Initialize the services
 	coreService := core.NewService(coreRepo, storage, eventBus)
+
+	// Load the card database from a Scryfall bulk data file, see https://scryfall.com/docs/api/bulk-data
+	// export SCRYFALL_BULK_FILE=oracle-cards.json
+	var cardDB *cards.Database
+	if path := os.Getenv("SCRYFALL_BULK_FILE"); path != "" {
+		log.Println("loading card database")
+		cardDB, err = cards.Load(path)
+		if err != nil {
+			log.Fatal("failed to load card database", err)
+		}
+		log.Printf("loaded %d cards", cardDB.Len())
+		coreService.Cards = cardDB
+	}
+	cardsService := cards.NewService(cardDB)
 	notificationsSvc := notification.NewService(notificationsRepo, coreService)
 	opponentService := opponents.NewService(opponentRepo, coreService)
 	feedService := feed.NewService(opponentRepo, coreRepo, coreService)
 	moxfieldService := moxfield.NewService()
 	statsService := statistics.NewService(statsRepo)
+	liveHub := live.NewHub()
+	liveService := live.NewService(liveHub, coreService)
 
 	// Register event handlers
 	log.Println("registering event handlers")
@@ -95,16 +113,26 @@ func main() {
 	statsHandlers := statistics.NewEventHandlers(statsRepo, coreService)
 	statsHandlers.RegisterHandlers(eventBus)
 
+	liveHandlers := live.NewEventHandlers(liveHub, coreService)
+	liveHandlers.RegisterHandlers(eventBus)
+
+	// Confirm the results nobody answered before the timeout
+	coreService.StartResultConfirmation()
+	// Eliminate the players who run out of time without passing their turn
+	coreService.StartClockTimeouts()
+
 	// // Create a new HTTP server
 	mux := http.NewServeMux()
 
 	coreService.RegisterRoutes(mux)
 	moxfieldService.RegisterRoutes(mux)
+	cardsService.RegisterRoutes(mux)
 	notificationsSvc.RegisterRoutes(mux)
 	opponentService.RegisterRoutes(mux)
 	feedService.RegisterRoutes(mux)
 	pushService.RegisterRoutes(mux)
 	statsService.RegisterRoutes(mux)
+	liveService.RegisterRoutes(mux)
 
 	// add middleware chain
 	handler := middleware.ApacheLogMw(mux)
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+)
+
+// Entities whose changes are recorded in the audit log
+const (
+	AuditEntityGame      = "game"
+	AuditEntityRanking   = "ranking"
+	AuditEntityGameEvent = "game_event"
+	AuditEntityDeck      = "deck"
+)
+
+// Actions of audit entries
+const (
+	AuditActionCreated           = "created"
+	AuditActionUpdated           = "updated"
+	AuditActionDeleted           = "deleted"
+	AuditActionRestored          = "restored"
+	AuditActionPaused            = "paused"
+	AuditActionResumed           = "resumed"
+	AuditActionMerged            = "merged"
+	AuditActionStatsRecalculated = "stats_recalculated" // The game's result was counted for or removed from the player statistics
+)
+
+// auditIgnoredFields are bookkeeping fields and preloaded relations, they are not part of a change
+var auditIgnoredFields = map[string]bool{
+	"ID": true, "CreatedAt": true, "UpdatedAt": true, "DeletedAt": true, "PlayerName": true,
+	"Rankings": true, "GameEvents": true, "pauses": true, "result_votes": true,
+	"creator": true, "player": true, "deck": true, "deck_version": true, "SourceRanking": true, "TargetRanking": true,
+}
+
+// auditFields returns the fields of an entity as they are serialized, nil for a missing entity
+func auditFields(entity interface{}) map[string]interface{} {
+	if value := reflect.ValueOf(entity); entity == nil || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		log.Printf("Failed to serialize %T for the audit log: %v", entity, err)
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		log.Printf("Failed to serialize %T for the audit log: %v", entity, err)
+		return nil
+	}
+	for field := range auditIgnoredFields {
+		delete(fields, field)
+	}
+	return fields
+}
+
+// auditDiff returns the fields that differ between two states of an entity
+func auditDiff(before, after map[string]interface{}) map[string]AuditChange {
+	changes := make(map[string]AuditChange)
+	for field, value := range before {
+		if !reflect.DeepEqual(value, after[field]) {
+			changes[field] = AuditChange{Before: value, After: after[field]}
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok && value != nil {
+			changes[field] = AuditChange{After: value}
+		}
+	}
+	return changes
+}
+
+// newAuditEntry records the change of an entity from before to after, either of which is nil when it was created or deleted
+// It returns false when nothing changed
+func newAuditEntry(actorID string, gameID uint, entityType string, entityID uint, before, after interface{}) (AuditEntry, bool) {
+	beforeFields, afterFields := auditFields(before), auditFields(after)
+	action := AuditActionUpdated
+	switch {
+	case beforeFields == nil && afterFields == nil:
+		return AuditEntry{}, false
+	case beforeFields == nil:
+		action = AuditActionCreated
+	case afterFields == nil:
+		action = AuditActionDeleted
+	}
+
+	changes := auditDiff(beforeFields, afterFields)
+	if action == AuditActionUpdated && len(changes) == 0 {
+		return AuditEntry{}, false
+	}
+	entry := auditAction(actorID, gameID, entityType, entityID, action, changes)
+	return entry, true
+}
+
+// auditAction records an action that isn't described by the fields of an entity
+func auditAction(actorID string, gameID uint, entityType string, entityID uint, action string, changes map[string]AuditChange) AuditEntry {
+	entry := AuditEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    changes,
+	}
+	if gameID != 0 {
+		entry.GameID = &gameID
+	}
+	if actorID != "" {
+		entry.ActorID = &actorID
+	}
+	return entry
+}
+
+// gameAuditEntries records the changes between two states of a game and its rankings
+// before is nil for a created game and after is nil for a deleted one
+func gameAuditEntries(actorID string, before, after *Game) []AuditEntry {
+	var entries []AuditEntry
+	var gameID uint
+	beforeRankings := make(map[uint]*Ranking)
+	afterRankings := make(map[uint]*Ranking)
+	if before != nil {
+		gameID = before.ID
+		for i := range before.Rankings {
+			beforeRankings[before.Rankings[i].ID] = &before.Rankings[i]
+		}
+	}
+	if after != nil {
+		gameID = after.ID
+		for i := range after.Rankings {
+			afterRankings[after.Rankings[i].ID] = &after.Rankings[i]
+		}
+	}
+
+	if entry, ok := newAuditEntry(actorID, gameID, AuditEntityGame, gameID, before, after); ok {
+		entries = append(entries, entry)
+	}
+
+	// Rankings in the order of the game, created rankings last
+	var rankings []Ranking
+	if before != nil {
+		rankings = append(rankings, before.Rankings...)
+	}
+	if after != nil {
+		for _, ranking := range after.Rankings {
+			if beforeRankings[ranking.ID] == nil {
+				rankings = append(rankings, ranking)
+			}
+		}
+	}
+	for _, ranking := range rankings {
+		if entry, ok := newAuditEntry(actorID, gameID, AuditEntityRanking, ranking.ID, beforeRankings[ranking.ID], afterRankings[ranking.ID]); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// gameEventAuditEntries records the changes between two states of the events of a game
+// Events are matched by ID, so events moved between games and life totals replayed after an undo are recorded too
+func gameEventAuditEntries(actorID string, before, after []GameEvent) []AuditEntry {
+	afterEvents := make(map[uint]*GameEvent)
+	for i := range after {
+		afterEvents[after[i].ID] = &after[i]
+	}
+
+	var entries []AuditEntry
+	seen := make(map[uint]bool)
+	for i := range before {
+		event := &before[i]
+		seen[event.ID] = true
+		gameID := event.GameID
+		if moved := afterEvents[event.ID]; moved != nil {
+			gameID = moved.GameID
+		}
+		if entry, ok := newAuditEntry(actorID, gameID, AuditEntityGameEvent, event.ID, event, afterEvents[event.ID]); ok {
+			entries = append(entries, entry)
+		}
+	}
+	for i := range after {
+		if seen[after[i].ID] {
+			continue
+		}
+		if entry, ok := newAuditEntry(actorID, after[i].GameID, AuditEntityGameEvent, after[i].ID, nil, &after[i]); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// statsAuditEntry records who triggered a recalculation of the statistics of a game's players
+func statsAuditEntry(actorID string, gameID uint, playerIDs []string) AuditEntry {
+	return auditAction(actorID, gameID, AuditEntityGame, gameID, AuditActionStatsRecalculated, map[string]AuditChange{
+		"players": {After: playerIDs},
+	})
+}
+
+// recordAudit appends entries to the audit log, failures are logged and don't fail the change itself
+func (s *Service) recordAudit(entries ...AuditEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := s.Repository.InsertAuditEntries(entries); err != nil {
+		log.Printf("Failed to record %d audit entries: %v", len(entries), err)
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// commanderColumn is a commander name written by hand and the oracle ID it resolves to
+type commanderColumn struct {
+	table    string
+	name     string
+	oracleID string
+}
+
+// commanderColumns lists every place a commander name is stored
+// Deck versions are included so a backfilled deck doesn't look changed compared to its version
+var commanderColumns = []commanderColumn{
+	{"decks", "commander", "commander_oracle_id"},
+	{"decks", "partner", "partner_oracle_id"},
+	{"deck_versions", "commander", "commander_oracle_id"},
+	{"deck_versions", "partner", "partner_oracle_id"},
+	{"rankings", "commander", "commander_oracle_id"},
+	{"rankings", "partner", "partner_oracle_id"},
+}
+
+// CommanderBackfillReport lists what a backfill of commander names did
+type CommanderBackfillReport struct {
+	Resolved  map[string]string // Names as they were written to the canonical name
+	Unmatched map[string]int64  // Names that couldn't be resolved to the number of decks and rankings using them
+}
+
+// UnmatchedNames returns the names that couldn't be resolved, the most used first
+func (report *CommanderBackfillReport) UnmatchedNames() []string {
+	names := make([]string, 0, len(report.Unmatched))
+	for name := range report.Unmatched {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if report.Unmatched[names[i]] != report.Unmatched[names[j]] {
+			return report.Unmatched[names[i]] > report.Unmatched[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// resolveFilterCommanders replaces the commanders of a search with their canonical names, which decks and rankings store
+// Names the card database doesn't know are searched as they are
+func (s *Service) resolveFilterCommanders(filter *GameFilter) {
+	for _, names := range [][]string{filter.Commanders, filter.AllCommanders} {
+		for i := range names {
+			var oracleID *string
+			s.resolveCommander(&names[i], &oracleID)
+		}
+	}
+}
+
+// SaveCommanders stores resolved commanders, updating the card data of known ones
+func (r *Repository) SaveCommanders(commanders []Commander) error {
+	if len(commanders) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "oracle_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "color_identity", "image", "secondary_image", "crop", "updated_at"}),
+	}).Create(&commanders).Error
+}
+
+// BackfillCommanders resolves the commander names of decks and rankings without an oracle ID
+// Resolved names are replaced with the canonical name, deleted decks included, unless dryRun is set
+// It isn't recorded in the audit log: it is run by an operator, not a user, and its report lists every rewritten name
+func (r *Repository) BackfillCommanders(resolve func(name string) (*Commander, bool), dryRun bool) (*CommanderBackfillReport, error) {
+	counts := make(map[string]int64)
+	for _, column := range commanderColumns {
+		var rows []struct {
+			Name  string
+			Count int64
+		}
+		err := r.DB.Raw(fmt.Sprintf(`SELECT %[2]s AS name, COUNT(*) AS count FROM %[1]s
+			WHERE %[3]s IS NULL AND COALESCE(%[2]s, '') <> '' GROUP BY %[2]s`, column.table, column.name, column.oracleID)).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			counts[row.Name] += row.Count
+		}
+	}
+
+	report := &CommanderBackfillReport{Resolved: make(map[string]string), Unmatched: make(map[string]int64)}
+	for name, count := range counts {
+		commander, ok := resolve(name)
+		if !ok {
+			report.Unmatched[name] = count
+			continue
+		}
+		report.Resolved[name] = commander.Name
+		if dryRun {
+			continue
+		}
+		if err := r.resolveCommanderName(name, commander); err != nil {
+			return nil, fmt.Errorf("failed to resolve %q: %w", name, err)
+		}
+	}
+	return report, nil
+}
+
+// resolveCommanderName stores a commander and points every use of a name written by hand to it
+func (r *Repository) resolveCommanderName(name string, commander *Commander) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		txRepo := &Repository{DB: tx}
+		if err := txRepo.SaveCommanders([]Commander{*commander}); err != nil {
+			return err
+		}
+		for _, column := range commanderColumns {
+			err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ?, %s = ? WHERE %s IS NULL AND %s = ?",
+				column.table, column.name, column.oracleID, column.oracleID, column.name),
+				commander.Name, commander.OracleID, name).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -10,15 +10,55 @@ func (svc *Service) ConvertGameToDto(game *Game, addEvents bool) GameResponse {
 	result := GameResponse{
 		ID:         game.ID,
 		CreatorID:  game.CreatorID,
+		JoinCode:   game.JoinCode,
+		Format:     rulesForGame(game).Format,
 		Duration:   game.Duration,
 		Date:       game.Date,
 		EndDate:    game.EndDate,
 		Comments:   game.Comments,
 		Finished:   game.Finished,
-		Rankings:   convertRankingsWithLifeTotal(game.Rankings, game.GameEvents),
+		Rankings:   convertRankingsWithLifeTotal(game.Rankings, game.GameEvents, rulesForGame(game)),
 		GameEvents: make([]GameEventResponse, len(game.GameEvents)),
 	}
 
+	if pause := activePause(game.Pauses); pause != nil {
+		result.Paused = true
+		result.PausedAt = &pause.PausedAt
+	}
+
+	if game.DeletedAt.Valid {
+		result.DeletedAt = &game.DeletedAt.Time
+	}
+
+	result.ResultStatus = game.ResultStatus
+	for _, vote := range game.ResultVotes {
+		result.ResultVotes = append(result.ResultVotes, ResultVoteResponse{
+			PlayerID:  vote.PlayerID,
+			Confirmed: vote.Confirmed,
+			Comment:   vote.Comment,
+			CreatedAt: vote.UpdatedAt,
+		})
+	}
+
+	if game.ClockInitialSeconds != nil {
+		result.Clock = &ClockConfig{
+			InitialSeconds:     *game.ClockInitialSeconds,
+			IncrementSeconds:   game.ClockIncrementSeconds,
+			EliminateOnTimeout: game.ClockEliminateOnTimeout,
+		}
+	}
+
+	turns := deriveTurnState(game, time.Now())
+	result.CurrentTurn = turns.TurnNumber
+	if !game.Finished {
+		result.ActiveRankingID = turns.ActiveRankingID
+		result.TurnStartedAt = turns.StartedAt
+	}
+	for i := range result.Rankings {
+		result.Rankings[i].TurnCount = turns.TurnCounts[result.Rankings[i].ID]
+		result.Rankings[i].TurnSeconds = turns.TurnSeconds[result.Rankings[i].ID]
+	}
+
 	// Include creator information if available
 	if game.Creator != nil && game.Creator.FirebaseID != "" {
 		creator := svc.ConvertPlayerToResponse(game.Creator)
@@ -35,15 +75,58 @@ func (svc *Service) ConvertGameToDto(game *Game, addEvents bool) GameResponse {
 	return result
 }
 
+func (svc *Service) convertRankingClaim(claim *RankingClaim) RankingClaimResponse {
+	result := RankingClaimResponse{
+		ID:        claim.ID,
+		RankingID: claim.RankingID,
+		GameID:    claim.GameID,
+		PlayerID:  claim.PlayerID,
+		Status:    claim.Status,
+		CreatedAt: claim.CreatedAt,
+	}
+	if claim.Ranking != nil {
+		result.Ranking = convertRankingToDto(claim.Ranking)
+	}
+	if claim.ProposedBy != nil {
+		proposedBy := ConvertPlayerToDtoSimple(claim.ProposedBy)
+		result.ProposedBy = &proposedBy
+	}
+	return result
+}
+
+func convertAuditEntry(entry *AuditEntry) AuditEntryResponse {
+	result := AuditEntryResponse{
+		ID:         entry.ID,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		Changes:    entry.Changes,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if entry.Actor != nil {
+		actor := ConvertPlayerToDtoSimple(entry.Actor)
+		result.Actor = &actor
+	}
+	return result
+}
+
 func convertSimpleDeck(deck Deck) SimpleDeck {
 	return SimpleDeck{
 		Commander:      deck.Commander,
 		Image:          deck.Image,
 		SecondaryImage: deck.SecondaryImage,
 		Crop:           deck.Crop,
+		Partner:        deck.Partner,
+		PartnerImage:   deck.PartnerImage,
+		PartnerCrop:    deck.PartnerCrop,
 	}
 }
 
+// ConvertGameEventToDto converts a single game event for consumers outside the core package
+func (svc *Service) ConvertGameEventToDto(event *GameEvent) GameEventResponse {
+	return convertGameEvent(event, "")
+}
+
 func convertGameEvent(event *GameEvent, uploadUrl string) GameEventResponse {
 	var sourceRanking, targetRanking *RankingResponse
 
@@ -58,6 +141,7 @@ func convertGameEvent(event *GameEvent, uploadUrl string) GameEventResponse {
 	}
 
 	return GameEventResponse{
+		ID:                   event.ID,
 		GameID:               event.GameID,
 		EventType:            event.EventType,
 		DamageDelta:          event.DamageDelta,
@@ -68,38 +152,82 @@ func convertGameEvent(event *GameEvent, uploadUrl string) GameEventResponse {
 		TargetRanking:        targetRanking,
 		ImageUrl:             event.ImageUrl,
 		UploadImageUrl:       uploadUrl,
+		CommanderIndex:       event.CommanderIndex,
+		CounterType:          event.CounterType,
+		Voided:               event.VoidedAt != nil,
+		VoidedAt:             event.VoidedAt,
+		TurnNumber:           event.TurnNumber,
+		TurnDurationSeconds:  event.TurnDurationSeconds,
 	}
 }
 
 func convertDeckFromRanking(rank *Ranking) DeckResponse {
+	// Show the deck as it was played
+	if rank.Deck != nil && rank.DeckVersion != nil {
+		version := rank.DeckVersion
+		return DeckResponse{
+			ID:                &rank.Deck.ID,
+			Commander:         version.Commander,
+			Colors:            version.Colors,
+			Crop:              version.Crop,
+			SecondaryImg:      version.SecondaryImage,
+			Image:             version.Image,
+			MoxfieldURL:       version.MoxfieldURL,
+			Bracket:           version.Bracket,
+			Partner:           version.Partner,
+			PartnerImage:      version.PartnerImage,
+			PartnerCrop:       version.PartnerCrop,
+			CommanderOracleID: version.CommanderOracleID,
+			PartnerOracleID:   version.PartnerOracleID,
+			Version:           version.Version,
+			Retired:           rank.Deck.RetiredAt != nil,
+		}
+	}
 	if rank.Deck != nil {
 		return DeckResponse{
-			ID:           &rank.Deck.ID,
-			Commander:    rank.Deck.Commander,
-			Colors:       rank.Deck.Colors,
-			Crop:         rank.Deck.Crop,
-			SecondaryImg: rank.Deck.SecondaryImage,
-			Image:        rank.Deck.Image,
-			MoxfieldURL:  rank.Deck.MoxfieldURL,
-			Bracket:      rank.Deck.Bracket,
+			ID:                &rank.Deck.ID,
+			Commander:         rank.Deck.Commander,
+			Colors:            rank.Deck.Colors,
+			Crop:              rank.Deck.Crop,
+			SecondaryImg:      rank.Deck.SecondaryImage,
+			Image:             rank.Deck.Image,
+			MoxfieldURL:       rank.Deck.MoxfieldURL,
+			Bracket:           rank.Deck.Bracket,
+			Partner:           rank.Deck.Partner,
+			PartnerImage:      rank.Deck.PartnerImage,
+			PartnerCrop:       rank.Deck.PartnerCrop,
+			CommanderOracleID: rank.Deck.CommanderOracleID,
+			PartnerOracleID:   rank.Deck.PartnerOracleID,
+			Version:           rank.Deck.Version,
+			Retired:           rank.Deck.RetiredAt != nil,
 		}
 	}
 	return DeckResponse{
-		Commander:    rank.DeckEmbedded.Commander,
-		Colors:       nil,
-		Crop:         rank.DeckEmbedded.Crop,
-		SecondaryImg: rank.DeckEmbedded.SecondaryImage,
-		Image:        rank.DeckEmbedded.Image,
+		Commander:         rank.DeckEmbedded.Commander,
+		Colors:            nil,
+		Crop:              rank.DeckEmbedded.Crop,
+		SecondaryImg:      rank.DeckEmbedded.SecondaryImage,
+		Image:             rank.DeckEmbedded.Image,
+		Partner:           rank.DeckEmbedded.Partner,
+		PartnerImage:      rank.DeckEmbedded.PartnerImage,
+		PartnerCrop:       rank.DeckEmbedded.PartnerCrop,
+		CommanderOracleID: rank.DeckEmbedded.CommanderOracleID,
+		PartnerOracleID:   rank.DeckEmbedded.PartnerOracleID,
 	}
 }
 
 func convertRankingToDto(rank *Ranking) RankingResponse {
 	return RankingResponse{
-		ID:          rank.ID,
-		PlayerID:    rank.PlayerID,
-		Position:    rank.Position,
-		Deck:        convertDeckFromRanking(rank),
-		Description: rank.Description,
+		ID:                    rank.ID,
+		PlayerID:              rank.PlayerID,
+		Position:              rank.Position,
+		Team:                  rank.Team,
+		Eliminated:            rank.Eliminated,
+		EliminatedAt:          rank.EliminatedAt,
+		EliminatedByRankingID: rank.EliminatedByRankingID,
+		EliminationReason:     rank.EliminationReason,
+		Deck:                  convertDeckFromRanking(rank),
+		Description:           rank.Description,
 		Player: func() *PlayerResponse {
 			if rank.Player != nil {
 				return &PlayerResponse{
@@ -113,21 +241,33 @@ func convertRankingToDto(rank *Ranking) RankingResponse {
 	}
 }
 
-func convertRankingsWithLifeTotal(rankings []Ranking, gameEvents []GameEvent) []RankingResponse {
+func convertRankingsWithLifeTotal(rankings []Ranking, gameEvents []GameEvent, rules FormatRules) []RankingResponse {
 	result := make([]RankingResponse, len(rankings))
 
 	// Build a map of ranking ID to most recent life total event
 	lifeTotalMap := make(map[uint]*GameEvent)
 	for i := range gameEvents {
 		event := &gameEvents[i]
-		if event.TargetRankingID != nil {
+		// Counter events and turn passes don't change the life total and voided events are ignored
+		if event.TargetRankingID != nil && event.CounterType == "" && event.VoidedAt == nil && event.EventType != EventTypeTurnPass {
 			// Keep the most recent event (events are assumed to be sorted by CreatedAt)
 			// If not sorted, we'll take the last one which should be most recent
 			lifeTotalMap[*event.TargetRankingID] = event
 		}
 	}
 
+	// Replay the events to get commander damage totals per ranking
+	lifeSharing := lifeSharingRankings(rankings, rules)
+	states := deriveRankingStates(gameEvents, rules, lifeSharing)
+
 	for i, rank := range rankings {
+		// Teammates sharing a life total take the most recent event of the whole team
+		for _, teammateID := range lifeSharing[rank.ID] {
+			if event, exists := lifeTotalMap[teammateID]; exists && (lifeTotalMap[rank.ID] == nil || event.ID > lifeTotalMap[rank.ID].ID) {
+				lifeTotalMap[rank.ID] = event
+			}
+		}
+
 		// Get last life total from most recent event for this ranking
 		var lastLifeTotal *int
 		var lastLifeTotalTimestamp *time.Time
@@ -141,9 +281,17 @@ func convertRankingsWithLifeTotal(rankings []Ranking, gameEvents []GameEvent) []
 			ID:                     rank.ID,
 			PlayerID:               rank.PlayerID,
 			Position:               rank.Position,
+			Team:                   rank.Team,
 			Deck:                   convertDeckFromRanking(&rank),
 			LastLifeTotal:          lastLifeTotal,
 			LastLifeTotalTimestamp: lastLifeTotalTimestamp,
+			CommanderDamage:        convertCommanderDamage(states[rank.ID]),
+			Counters:               convertCounters(states[rank.ID]),
+			Eliminated:             rank.Eliminated,
+			EliminatedAt:           rank.EliminatedAt,
+			EliminatedByRankingID:  rank.EliminatedByRankingID,
+			EliminationReason:      rank.EliminationReason,
+			ClockRemainingSeconds:  rank.ClockRemainingSeconds,
 			Description:            rank.Description,
 			Player: func() *PlayerResponse {
 				if rank.Player != nil {
@@ -174,15 +322,20 @@ func (svc *Service) ConvertPlayerToResponse(player *Player) PlayerResponse {
 	for _, deck := range player.Decks {
 		decks = append(decks, DeckWithCount{
 			Deck: DeckResponse{
-				ID:           &deck.ID,
-				Commander:    deck.Commander,
-				Colors:       deck.Colors,
-				Crop:         deck.Crop,
-				SecondaryImg: deck.SecondaryImage,
-				Image:        deck.Image,
-				MoxfieldURL:  deck.MoxfieldURL,
-				Bracket:      deck.Bracket,
-				Themes:       deck.Themes,
+				ID:                &deck.ID,
+				Commander:         deck.Commander,
+				Colors:            deck.Colors,
+				Crop:              deck.Crop,
+				SecondaryImg:      deck.SecondaryImage,
+				Image:             deck.Image,
+				MoxfieldURL:       deck.MoxfieldURL,
+				Bracket:           deck.Bracket,
+				Themes:            deck.Themes,
+				Partner:           deck.Partner,
+				PartnerImage:      deck.PartnerImage,
+				PartnerCrop:       deck.PartnerCrop,
+				CommanderOracleID: deck.CommanderOracleID,
+				PartnerOracleID:   deck.PartnerOracleID,
 			},
 			Count: deck.GameCount,
 			Wins:  deck.WinCount,
@@ -257,15 +410,42 @@ func ConvertPlayerToDtoSimple(player *Player) PlayerResponse {
 }
 func convertDeckToDto(deck *Deck) DeckResponse {
 	return DeckResponse{
-		ID:           &deck.ID,
-		Commander:    deck.Commander,
-		Colors:       deck.Colors,
-		Crop:         deck.Crop,
-		SecondaryImg: deck.SecondaryImage,
-		Image:        deck.Image,
-		MoxfieldURL:  deck.MoxfieldURL,
-		Bracket:      deck.Bracket,
-		Themes:       deck.Themes,
+		ID:                &deck.ID,
+		Commander:         deck.Commander,
+		Colors:            deck.Colors,
+		Crop:              deck.Crop,
+		SecondaryImg:      deck.SecondaryImage,
+		Image:             deck.Image,
+		MoxfieldURL:       deck.MoxfieldURL,
+		Bracket:           deck.Bracket,
+		Themes:            deck.Themes,
+		Partner:           deck.Partner,
+		PartnerImage:      deck.PartnerImage,
+		PartnerCrop:       deck.PartnerCrop,
+		CommanderOracleID: deck.CommanderOracleID,
+		PartnerOracleID:   deck.PartnerOracleID,
+		Version:           deck.Version,
+		Retired:           deck.RetiredAt != nil,
+	}
+}
+
+func convertDeckVersion(version *DeckVersion) DeckResponse {
+	return DeckResponse{
+		ID:                &version.DeckID,
+		Commander:         version.Commander,
+		Colors:            version.Colors,
+		Crop:              version.Crop,
+		SecondaryImg:      version.SecondaryImage,
+		Image:             version.Image,
+		MoxfieldURL:       version.MoxfieldURL,
+		Bracket:           version.Bracket,
+		Themes:            version.Themes,
+		Partner:           version.Partner,
+		PartnerImage:      version.PartnerImage,
+		PartnerCrop:       version.PartnerCrop,
+		CommanderOracleID: version.CommanderOracleID,
+		PartnerOracleID:   version.PartnerOracleID,
+		Version:           version.Version,
 	}
 }
 
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+)
+
+// Well-known player counters. Any other name matching customCounterPattern is tracked as a custom counter
+const (
+	CounterPoison     = "poison"
+	CounterEnergy     = "energy"
+	CounterExperience = "experience"
+	CounterRad        = "rad"
+)
+
+// poisonLethal is the number of poison counters that eliminates a player
+const poisonLethal = 10
+
+var customCounterPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// validateCounterType checks that a counter event targets a known or well-formed custom counter
+func validateCounterType(eventType, counterType string) error {
+	if counterType == "" {
+		return nil
+	}
+	if eventType != EventTypeIncrement && eventType != EventTypeDecrement {
+		return errors.New("counter_type is only allowed on increment and decrement events")
+	}
+	switch counterType {
+	case CounterPoison, CounterEnergy, CounterExperience, CounterRad:
+		return nil
+	}
+	if !customCounterPattern.MatchString(counterType) {
+		return errors.New("custom counter_type must be lowercase letters, digits or underscores (max 32)")
+	}
+	return nil
+}
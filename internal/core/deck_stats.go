@@ -0,0 +1,176 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// deckRecentGames is the number of recent games on the deck page
+const deckRecentGames = 10
+
+// deckTopMatchups is the number of commanders and players per matchup list
+const deckTopMatchups = 5
+
+// deckGame is a game a deck was played in, with the deck's ranking
+type deckGame struct {
+	game    *Game
+	ranking *Ranking
+}
+
+// playedDeckGames pairs games with the ranking the deck was played in, games without it are skipped
+func playedDeckGames(deckID uint, games []Game) []deckGame {
+	played := make([]deckGame, 0, len(games))
+	for i := range games {
+		for j := range games[i].Rankings {
+			ranking := &games[i].Rankings[j]
+			if ranking.DeckID != nil && *ranking.DeckID == deckID {
+				played = append(played, deckGame{game: &games[i], ranking: ranking})
+				break
+			}
+		}
+	}
+	return played
+}
+
+// playedAt is when a game was played, for ordering the deck's history
+func playedAt(game *Game) time.Time {
+	switch {
+	case game.EndDate != nil:
+		return *game.EndDate
+	case game.Date != nil:
+		return *game.Date
+	default:
+		return game.CreatedAt
+	}
+}
+
+// computeDeckStats fills the statistics of a deck page from the confirmed games the deck was played in, oldest first
+func computeDeckStats(deckID uint, games []Game) DeckDetailResponse {
+	result := DeckDetailResponse{
+		WinrateHistory:       []DeckWinratePoint{},
+		RecentGames:          []GameResponse{},
+		MostBeatenCommanders: []DeckMatchupResult{},
+		MostLostToCommanders: []DeckMatchupResult{},
+		MostBeatenPlayers:    []DeckMatchupResult{},
+		MostLostToPlayers:    []DeckMatchupResult{},
+	}
+
+	commanders := make(map[string]*DeckMatchupResult)
+	players := make(map[string]*DeckMatchupResult)
+	positions, duration, timedGames := 0, 0, 0
+
+	for _, played := range playedDeckGames(deckID, games) {
+		result.GameCount++
+		if played.ranking.Position == 1 {
+			result.WinCount++
+		}
+		positions += played.ranking.Position
+		if played.game.Duration != nil {
+			duration += *played.game.Duration
+			timedGames++
+		}
+		result.WinrateHistory = append(result.WinrateHistory, DeckWinratePoint{
+			Date:      playedAt(played.game),
+			GameCount: result.GameCount,
+			Winrate:   float64(result.WinCount) / float64(result.GameCount),
+		})
+
+		for i := range played.game.Rankings {
+			other := &played.game.Rankings[i]
+			if other == played.ranking || isSameTeam(played.ranking, other) {
+				continue
+			}
+			won := played.ranking.Position < other.Position
+			lost := played.ranking.Position > other.Position
+
+			// The commanders the opponent played at the time, their deck may have changed since
+			commander, partner := playedCommanders(other)
+			if commander.name != "" {
+				countCommanderMatchup(commanders, commander, partner, won, lost)
+				if partner.name != "" {
+					countCommanderMatchup(commanders, commander, playedCommander{}, won, lost)
+					countCommanderMatchup(commanders, partner, playedCommander{}, won, lost)
+				}
+			}
+			if other.PlayerID != nil && other.Player != nil {
+				if players[*other.PlayerID] == nil {
+					player := ConvertPlayerToDtoSimple(other.Player)
+					players[*other.PlayerID] = &DeckMatchupResult{Player: &player}
+				}
+				countMatchup(players[*other.PlayerID], won, lost)
+			}
+		}
+	}
+
+	if result.GameCount > 0 {
+		result.Winrate = float64(result.WinCount) / float64(result.GameCount)
+		result.AveragePosition = float64(positions) / float64(result.GameCount)
+	}
+	if timedGames > 0 {
+		average := duration / timedGames
+		result.AverageGameDuration = &average
+	}
+
+	result.MostBeatenCommanders = topMatchups(commanders, func(m *DeckMatchupResult) int { return m.Wins })
+	result.MostLostToCommanders = topMatchups(commanders, func(m *DeckMatchupResult) int { return m.Losses })
+	result.MostBeatenPlayers = topMatchups(players, func(m *DeckMatchupResult) int { return m.Wins })
+	result.MostLostToPlayers = topMatchups(players, func(m *DeckMatchupResult) int { return m.Losses })
+	return result
+}
+
+func isSameTeam(a, b *Ranking) bool {
+	return a.Team != nil && b.Team != nil && *a.Team == *b.Team
+}
+
+// countCommanderMatchup counts a game against a commander, or against a pairing when partner is set
+// A pairing is the same in either order, commanders are told apart by oracle ID however their names were written
+func countCommanderMatchup(commanders map[string]*DeckMatchupResult, commander, partner playedCommander, won, lost bool) {
+	keys := []string{commander.key(), partner.key()}
+	sort.Strings(keys)
+	key := strings.Join(keys, "\n")
+	if commanders[key] == nil {
+		commanders[key] = &DeckMatchupResult{Commander: commander.name, Partner: partner.name}
+	}
+	countMatchup(commanders[key], won, lost)
+}
+
+func countMatchup(matchup *DeckMatchupResult, won, lost bool) {
+	matchup.Games++
+	if won {
+		matchup.Wins++
+	}
+	if lost {
+		matchup.Losses++
+	}
+}
+
+// topMatchups returns the matchups with the highest count, ties broken by fewer games and then by name
+// Matchups with a count of zero are left out
+func topMatchups(matchups map[string]*DeckMatchupResult, count func(*DeckMatchupResult) int) []DeckMatchupResult {
+	keys := make([]string, 0, len(matchups))
+	for key, matchup := range matchups {
+		if count(matchup) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := matchups[keys[i]], matchups[keys[j]]
+		if count(a) != count(b) {
+			return count(a) > count(b)
+		}
+		if a.Games != b.Games {
+			return a.Games < b.Games
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) > deckTopMatchups {
+		keys = keys[:deckTopMatchups]
+	}
+	result := make([]DeckMatchupResult, len(keys))
+	for i, key := range keys {
+		result[i] = *matchups[key]
+	}
+	return result
+}
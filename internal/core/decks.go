@@ -0,0 +1,268 @@
+package core
+
+import (
+	"mtgtracker/pkg/cards"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// withDeletedDecks preloads decks even after they were deleted, so historical games keep showing them
+func withDeletedDecks(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// snapshotDeck captures what a deck is at its current version
+func snapshotDeck(deck *Deck) DeckVersion {
+	return DeckVersion{
+		DeckID:         deck.ID,
+		Version:        deck.Version,
+		Commander:      deck.Commander,
+		MoxfieldURL:    deck.MoxfieldURL,
+		Themes:         deck.Themes,
+		Bracket:        deck.Bracket,
+		Colors:         deck.Colors,
+		Image:          deck.Image,
+		SecondaryImage: deck.SecondaryImage,
+		Crop:           deck.Crop,
+		Partner:        deck.Partner,
+		PartnerImage:   deck.PartnerImage,
+		PartnerCrop:    deck.PartnerCrop,
+
+		CommanderOracleID: deck.CommanderOracleID,
+		PartnerOracleID:   deck.PartnerOracleID,
+	}
+}
+
+// sameDeckVersion reports whether two snapshots describe the same deck, ignoring their version numbers
+func sameDeckVersion(a, b DeckVersion) bool {
+	a.Version, b.Version = 0, 0
+	return reflect.DeepEqual(a, b)
+}
+
+// applyDeckUpdate applies the fields set in an update request to a deck
+func applyDeckUpdate(deck *Deck, request UpdateDeckRequest) {
+	if request.Commander != nil {
+		deck.Commander = *request.Commander
+		deck.CommanderOracleID = request.CommanderOracleID
+	}
+	if request.MoxfieldURL != nil {
+		deck.MoxfieldURL = request.MoxfieldURL
+	}
+	if request.Themes != nil {
+		deck.Themes = *request.Themes
+	}
+	if request.Bracket != nil {
+		deck.Bracket = request.Bracket
+	}
+	if request.Colors != nil {
+		deck.Colors = *request.Colors
+	}
+	if request.Image != nil {
+		deck.Image = *request.Image
+	}
+	if request.SecondaryImage != nil {
+		deck.SecondaryImage = *request.SecondaryImage
+	}
+	if request.Crop != nil {
+		deck.Crop = *request.Crop
+	}
+	if request.Partner != nil {
+		deck.Partner = *request.Partner
+		deck.PartnerOracleID = request.PartnerOracleID
+	}
+	if request.PartnerImage != nil {
+		deck.PartnerImage = *request.PartnerImage
+	}
+	if request.PartnerCrop != nil {
+		deck.PartnerCrop = *request.PartnerCrop
+	}
+}
+
+// completeDeckRequest resolves the commanders of a new deck and fills in the colors and images it was created without
+// It returns the commanders to store, unknown commanders are left as they are
+func (s *Service) completeDeckRequest(request *CreateDeckRequest) []Commander {
+	commander := s.resolveCommander(&request.Commander, &request.CommanderOracleID)
+	partner := s.resolveCommander(&request.Partner, &request.PartnerOracleID)
+	if commander == nil {
+		return commandersOf(partner)
+	}
+	if request.Image == "" {
+		request.Image = commander.Image
+		request.SecondaryImage = commander.SecondaryImage
+	}
+	if request.Crop == "" {
+		request.Crop = commander.Crop
+	}
+
+	if request.Partner != "" && partner == nil {
+		return commandersOf(commander) // The colors of the pairing aren't known without the partner
+	}
+	if partner != nil {
+		if request.PartnerImage == "" {
+			request.PartnerImage = partner.Image
+		}
+		if request.PartnerCrop == "" {
+			request.PartnerCrop = partner.Crop
+		}
+	}
+	if len(request.Colors) == 0 {
+		request.Colors = cards.CombinedColorIdentity(commander, partner)
+	}
+	return commandersOf(commander, partner)
+}
+
+// completeDeckUpdate resolves the commanders an update changes and fills in the colors and images of a new commander
+// or partner the update was sent without, it returns the commanders to store
+func (s *Service) completeDeckUpdate(deck *Deck, request *UpdateDeckRequest) []Commander {
+	var resolved []*cards.Card
+	commander, partner := s.knownCommander(deck.Commander), s.knownCommander(deck.Partner)
+	commanderChanged, partnerChanged := false, false
+	if request.Commander != nil {
+		commander = s.resolveCommander(request.Commander, &request.CommanderOracleID)
+		resolved = append(resolved, commander)
+		commanderChanged = *request.Commander != deck.Commander
+	}
+	hasPartner := deck.Partner != ""
+	if request.Partner != nil {
+		partner = s.resolveCommander(request.Partner, &request.PartnerOracleID)
+		resolved = append(resolved, partner)
+		partnerChanged = *request.Partner != deck.Partner
+		hasPartner = *request.Partner != ""
+	}
+	if !commanderChanged && !partnerChanged {
+		return commandersOf(resolved...)
+	}
+
+	if commanderChanged && commander != nil {
+		if request.Image == nil {
+			image, secondaryImage := commander.Image, commander.SecondaryImage
+			request.Image = &image
+			request.SecondaryImage = &secondaryImage
+		}
+		if request.Crop == nil {
+			crop := commander.Crop
+			request.Crop = &crop
+		}
+	}
+	if partnerChanged {
+		var image, crop string // A removed or unknown partner takes the images of the old one with it
+		if partner != nil {
+			image, crop = partner.Image, partner.Crop
+		}
+		if request.PartnerImage == nil {
+			request.PartnerImage = &image
+		}
+		if request.PartnerCrop == nil {
+			request.PartnerCrop = &crop
+		}
+	}
+	// The colors of the pairing aren't known while one of its commanders isn't
+	if request.Colors == nil && commander != nil && (!hasPartner || partner != nil) {
+		colors := cards.CombinedColorIdentity(commander, partner)
+		request.Colors = &colors
+	}
+	return commandersOf(resolved...)
+}
+
+// knownCommander looks up a commander a deck already has, it returns nil if the card database doesn't know it
+func (s *Service) knownCommander(name string) *cards.Card {
+	if s.Cards == nil || name == "" {
+		return nil
+	}
+	card, ok := s.Cards.Get(name)
+	if !ok {
+		return nil
+	}
+	return card
+}
+
+// completeSimpleDeck resolves the commanders of a deck entered in a ranking and fills in the images it was entered without
+// It returns the commanders to store
+func (s *Service) completeSimpleDeck(deck *SimpleDeck) []Commander {
+	request := CreateDeckRequest{
+		Commander:      deck.Commander,
+		Image:          deck.Image,
+		SecondaryImage: deck.SecondaryImage,
+		Crop:           deck.Crop,
+		Partner:        deck.Partner,
+		PartnerImage:   deck.PartnerImage,
+		PartnerCrop:    deck.PartnerCrop,
+	}
+	commanders := s.completeDeckRequest(&request)
+	deck.Commander, deck.CommanderOracleID = request.Commander, request.CommanderOracleID
+	deck.Image, deck.SecondaryImage, deck.Crop = request.Image, request.SecondaryImage, request.Crop
+	deck.Partner, deck.PartnerOracleID = request.Partner, request.PartnerOracleID
+	deck.PartnerImage, deck.PartnerCrop = request.PartnerImage, request.PartnerCrop
+	return commanders
+}
+
+// resolveCommander replaces a commander name with its canonical name and sets its oracle ID
+// Only names matching a card apart from case and punctuation are replaced, short names and typos are left to autocomplete
+// Names the card database doesn't know keep their spelling and get no oracle ID, it returns nil for them
+func (s *Service) resolveCommander(name *string, oracleID **string) *cards.Card {
+	*oracleID = nil
+	if s.Cards == nil || *name == "" {
+		return nil
+	}
+	card, ok := s.Cards.Get(*name)
+	if !ok {
+		return nil
+	}
+	*name = card.Name
+	*oracleID = &card.OracleID
+	return card
+}
+
+// commandersOf converts the resolved cards to commanders, skipping the ones that weren't resolved
+func commandersOf(resolved ...*cards.Card) []Commander {
+	var result []Commander
+	for _, card := range resolved {
+		if card != nil {
+			result = append(result, Commander{
+				OracleID:       card.OracleID,
+				Name:           card.Name,
+				ColorIdentity:  card.ColorIdentity,
+				Image:          card.Image,
+				SecondaryImage: card.SecondaryImage,
+				Crop:           card.Crop,
+			})
+		}
+	}
+	return result
+}
+
+// playedCommander is a commander as a ranking played it
+type playedCommander struct {
+	name     string
+	oracleID *string
+}
+
+// key identifies the commander: its oracle ID, or the lowercased name of a commander the card database doesn't know
+func (c playedCommander) key() string {
+	return commanderKey(c.name, c.oracleID)
+}
+
+func commanderKey(name string, oracleID *string) string {
+	if oracleID != nil {
+		return *oracleID
+	}
+	return strings.ToLower(name)
+}
+
+// playedCommanders returns the commander a ranking played and its partner, as the deck was at the time
+// The partner is empty for a single commander
+func playedCommanders(ranking *Ranking) (playedCommander, playedCommander) {
+	switch {
+	case ranking.DeckVersion != nil:
+		return playedCommander{ranking.DeckVersion.Commander, ranking.DeckVersion.CommanderOracleID},
+			playedCommander{ranking.DeckVersion.Partner, ranking.DeckVersion.PartnerOracleID}
+	case ranking.Deck != nil:
+		return playedCommander{ranking.Deck.Commander, ranking.Deck.CommanderOracleID},
+			playedCommander{ranking.Deck.Partner, ranking.Deck.PartnerOracleID}
+	default:
+		return playedCommander{ranking.DeckEmbedded.Commander, ranking.DeckEmbedded.CommanderOracleID},
+			playedCommander{ranking.DeckEmbedded.Partner, ranking.DeckEmbedded.PartnerOracleID}
+	}
+}
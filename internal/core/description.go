@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"mtgtracker/pkg/cards"
+)
+
+// checkDescription validates the references of a ranking's description in a game and fills them in from server data
+// Card references must be known to the card database, their text, images and colors are taken from it
+// Without a card database card references are kept as they were sent
+// Player references must play in the game, names maps the game's player IDs to their current names
+// Its errors are about the description, they are the client's
+func (s *Service) checkDescription(description *GameDescription, names map[string]string) error {
+	if description == nil {
+		return nil
+	}
+	if err := checkPlayerReferences(description, names); err != nil {
+		return err
+	}
+
+	if s.Cards == nil {
+		return nil
+	}
+	return checkCardReferences(description, s.Cards.Get)
+}
+
+// participantNames maps the players of a game's rankings to their current names
+// Players assigned in an update aren't loaded with the game, they are looked up together
+func (s *Service) participantNames(rankings []Ranking) (map[string]string, error) {
+	names := make(map[string]string)
+	var missing []string
+	for _, ranking := range rankings {
+		if ranking.PlayerID == nil {
+			continue
+		}
+		if ranking.Player != nil {
+			names[*ranking.PlayerID] = ranking.Player.Name
+			continue
+		}
+		missing = append(missing, *ranking.PlayerID)
+	}
+	if len(missing) == 0 {
+		return names, nil
+	}
+
+	players, err := s.Repository.GetPlayersByFirebaseIDs(missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, player := range players {
+		names[player.FirebaseID] = player.Name
+	}
+	return names, nil
+}
+
+// participantsOf returns updated rankings of a game with the players that were loaded with the game
+func participantsOf(game *Game, rankings []Ranking) []Ranking {
+	players := make(map[string]*Player)
+	for _, ranking := range game.Rankings {
+		if ranking.PlayerID != nil && ranking.Player != nil {
+			players[*ranking.PlayerID] = ranking.Player
+		}
+	}
+	result := make([]Ranking, len(rankings))
+	for i, ranking := range rankings {
+		if ranking.PlayerID != nil && ranking.Player == nil {
+			ranking.Player = players[*ranking.PlayerID]
+		}
+		result[i] = ranking
+	}
+	return result
+}
+
+// checkPlayerReferences keeps each referenced participant once with their name, names maps the game's player IDs to names
+func checkPlayerReferences(description *GameDescription, names map[string]string) error {
+	seen := make(map[string]bool)
+	references := make([]PlayerReference, 0, len(description.PlayerReferences))
+	for _, reference := range description.PlayerReferences {
+		name, ok := names[reference.ID]
+		if !ok {
+			return fmt.Errorf("player %s didn't play in this game", reference.ID)
+		}
+		if seen[reference.ID] {
+			continue
+		}
+		seen[reference.ID] = true
+		references = append(references, PlayerReference{ID: reference.ID, Name: name})
+	}
+	description.PlayerReferences = references
+	return nil
+}
+
+// checkCardReferences replaces the card data of each reference with the card database's
+// References keep their key, which is how the text refers to them, and are looked up by their name or else their key
+func checkCardReferences(description *GameDescription, lookup func(name string) (*cards.Card, bool)) error {
+	for key, reference := range description.CardReferences {
+		name := reference.Name
+		if name == "" {
+			name = key
+		}
+		card, ok := lookup(name)
+		if !ok {
+			return fmt.Errorf("unknown card: %s", name)
+		}
+		description.CardReferences[key] = cardReferenceOf(card)
+	}
+	return nil
+}
+
+func cardReferenceOf(card *cards.Card) CardReference {
+	reference := CardReference{
+		Name:          card.Name,
+		OracleText:    card.OracleText,
+		ColorIdentity: card.ColorIdentity,
+		ImageURI:      optionalString(card.Image),
+		ArtCropURI:    optionalString(card.Crop),
+	}
+	reference.SecondaryImageURI = optionalString(card.SecondaryImage)
+	reference.SecondaryArtCropURI = optionalString(card.SecondaryCrop)
+	return reference
+}
+
+// optionalString returns nil for an empty string
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -10,21 +10,74 @@ type SignupPlayerRequest struct {
 
 // duration int, comments, image string, rankings []Ranking
 type CreateGameRequest struct {
+	Format   string                 `json:"format,omitempty"` // Defaults to commander
 	Date     *time.Time             `json:"date"`
+	Duration *int                   `json:"duration,omitempty"` // Optional: duration in seconds for games logged after they were played
 	Comments string                 `json:"comments"`
 	Image    string                 `json:"image"`
 	Finished bool                   `json:"finished"`
 	Rankings []CreateRankingRequest `json:"rankings"`
+	Clock    *ClockConfig           `json:"clock,omitempty"` // Optional: play with a chess clock per ranking
+}
+
+// ClockConfig configures the chess clock of a timed game
+type ClockConfig struct {
+	InitialSeconds     int  `json:"initial_seconds"`      // Time per ranking at the start of the game
+	IncrementSeconds   int  `json:"increment_seconds"`    // Time added after each of the ranking's turns
+	EliminateOnTimeout bool `json:"eliminate_on_timeout"` // Eliminate rankings whose clock runs out
 }
 
 type CreateRankingRequest struct {
 	PlayerID *string `json:"player_id,omitempty"`
 	DeckID   *uint   `json:"deck_id,omitempty"` // Optional: reference to existing deck
 	Deck     *Deck   `json:"deck,omitempty"`    // Optional: inline deck info (used if deck_id not provided)
+	Team     *int    `json:"team,omitempty"`    // Required in team formats: rankings with the same team play together
+}
+
+// JoinGameRequest joins a game by its join code
+// Players either claim a ranking without a player or add a new ranking for themselves
+type JoinGameRequest struct {
+	RankingID *uint `json:"ranking_id,omitempty"` // Optional: the guest ranking to claim
+	DeckID    *uint `json:"deck_id,omitempty"`    // Optional: one of the player's own decks
+	Deck      *Deck `json:"deck,omitempty"`       // Optional: inline deck info when adding a new ranking
+	Team      *int  `json:"team,omitempty"`       // Required when adding a new ranking in team formats
+}
+
+// ProposeRankingClaimRequest proposes that a guest ranking was played by a player
+type ProposeRankingClaimRequest struct {
+	PlayerID string `json:"player_id"`
+}
+
+// AcceptRankingClaimRequest accepts a ranking claim
+type AcceptRankingClaimRequest struct {
+	DeckID *uint `json:"deck_id,omitempty"` // Optional: the deck that was played, matched by commander if omitted
+}
+
+type RankingClaimResponse struct {
+	ID         uint            `json:"id"`
+	RankingID  uint            `json:"ranking_id"`
+	GameID     uint            `json:"game_id"`
+	PlayerID   string          `json:"player_id"`
+	Status     string          `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Ranking    RankingResponse `json:"ranking"`
+	ProposedBy *PlayerResponse `json:"proposed_by,omitempty"`
+}
+
+type AuditEntryResponse struct {
+	ID         uint                   `json:"id"`
+	EntityType string                 `json:"entity_type"` // game, ranking, game_event or deck
+	EntityID   uint                   `json:"entity_id"`
+	Action     string                 `json:"action"`
+	Actor      *PlayerResponse        `json:"actor,omitempty"` // Unset for changes made by the server
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
 }
+
 type UpdateGameRequest struct {
 	GameID   uint            `json:"game_id"`
 	Finished *bool           `json:"finished"`
+	Duration *int            `json:"duration,omitempty"` // Optional: manual duration in seconds
 	Rankings []UpdateRanking `json:"rankings"`
 }
 
@@ -45,12 +98,22 @@ type UpdateRankingRequest struct {
 
 type GameEventRequest struct {
 	EventType            string  `json:"event_type"`
-	EventImageName       *string `json:"event_image_name,omitempty"` // Optional field for image upload
-	Comment              *string `json:"comment,omitempty"`          // Optional field for image upload
-	DamageDelta          int     `json:"damage_delta"`
-	TargetLifeTotalAfter int     `json:"life_total_after"`
+	EventImageName       *string `json:"event_image_name,omitempty"`  // Optional field for image upload
+	Comment              *string `json:"comment,omitempty"`           // Optional field for image upload
+	DamageDelta          int     `json:"damage_delta"`                // Signed change of the life total, damage is negative
+	TargetLifeTotalAfter *int    `json:"life_total_after,omitempty"`  // Optional: checked against the server-computed life total, a mismatch returns 409 with a LifeTotalConflictResponse
 	SourceRankingId      *uint   `json:"source_ranking_id,omitempty"` // Made nullable with pointer
 	TargetRankingId      *uint   `json:"target_ranking_id,omitempty"` // Made nullable with pointer
+	CommanderIndex       int     `json:"commander_index"`             // For commander damage: 0 = commander, 1 = partner
+	CounterType          string  `json:"counter_type,omitempty"`      // For increment/decrement: the counter to change instead of life
+}
+
+// LifeTotalConflictResponse is returned when a client's life total doesn't match the server's
+type LifeTotalConflictResponse struct {
+	Error                  string `json:"error"`
+	RankingID              uint   `json:"ranking_id"`
+	CurrentLifeTotal       int    `json:"current_life_total"`
+	ExpectedLifeTotalAfter int    `json:"expected_life_total_after"`
 }
 
 type PlayerOpponentWithCount struct {
@@ -79,19 +142,54 @@ type UpdatePlayerRequest struct {
 }
 
 type GameResponse struct {
-	ID         uint                `json:"id"`
-	CreatorID  *string             `json:"creator_id,omitempty"`
-	Duration   *int                `json:"duration,omitempty"`
-	Date       *time.Time          `json:"date,omitempty"`
-	EndDate    *time.Time          `json:"end_date,omitempty"`
-	Comments   string              `json:"comments,omitempty"`
-	Rankings   []RankingResponse   `json:"rankings,omitempty"`
-	Finished   bool                `json:"finished"`
-	GameEvents []GameEventResponse `json:"game_events,omitempty"`
-	Creator    *PlayerResponse     `json:"creator,omitempty"`
+	ID                   uint                 `json:"id"`
+	CreatorID            *string              `json:"creator_id,omitempty"`
+	JoinCode             *string              `json:"join_code,omitempty"`
+	Format               string               `json:"format"`
+	Duration             *int                 `json:"duration,omitempty"`
+	Date                 *time.Time           `json:"date,omitempty"`
+	EndDate              *time.Time           `json:"end_date,omitempty"`
+	Comments             string               `json:"comments,omitempty"`
+	Rankings             []RankingResponse    `json:"rankings,omitempty"`
+	Finished             bool                 `json:"finished"`
+	CurrentTurn          int                  `json:"current_turn,omitempty"`      // Round of the current turn, the final round once finished
+	ActiveRankingID      *uint                `json:"active_ranking_id,omitempty"` // Ranking whose turn it is, unset once finished
+	TurnStartedAt        *time.Time           `json:"turn_started_at,omitempty"`
+	Paused               bool                 `json:"paused"`
+	PausedAt             *time.Time           `json:"paused_at,omitempty"`
+	Clock                *ClockConfig         `json:"clock,omitempty"`
+	ResultStatus         string               `json:"result_status,omitempty"` // pending, confirmed or disputed once finished
+	ResultVotes          []ResultVoteResponse `json:"result_votes,omitempty"`
+	PossibleDuplicateIDs []uint               `json:"possible_duplicate_ids,omitempty"` // Games that look like the same game logged twice
+	DeletedAt            *time.Time           `json:"deleted_at,omitempty"`             // Set for games in the trash
+	GameEvents           []GameEventResponse  `json:"game_events,omitempty"`
+	Creator              *PlayerResponse      `json:"creator,omitempty"`
+}
+
+// MergeGameRequest merges a duplicate into the game of the request path
+type MergeGameRequest struct {
+	DuplicateGameID uint `json:"duplicate_game_id"`
+}
+
+// DiscardGameRequest deletes the game of the request path because it duplicates another game
+type DiscardGameRequest struct {
+	DuplicateOf uint `json:"duplicate_of"`
+}
+
+type ResultVoteResponse struct {
+	PlayerID  string    `json:"player_id"`
+	Confirmed bool      `json:"confirmed"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DisputeResultRequest disputes the final positions of a game
+type DisputeResultRequest struct {
+	Comment string `json:"comment"`
 }
 
 type GameEventResponse struct {
+	ID                   uint             `json:"id"`
 	GameID               uint             `json:"game_id"`
 	EventType            string           `json:"event_type"`
 	DamageDelta          int              `json:"damage_delta"`
@@ -102,17 +200,40 @@ type GameEventResponse struct {
 	ImageUrl             string           `json:"image_url"`                  // URL of the uploaded image
 	UploadImageUrl       string           `json:"upload_image_url,omitempty"` // Presigned URL for image upload
 	Comment              *string          `json:"comment,omitempty"`          // New field for text description
+	CommanderIndex       int              `json:"commander_index"`
+	CounterType          string           `json:"counter_type,omitempty"`
+	Voided               bool             `json:"voided"`
+	VoidedAt             *time.Time       `json:"voided_at,omitempty"`
+	TurnNumber           int              `json:"turn_number,omitempty"`
+	TurnDurationSeconds  *int             `json:"turn_duration_seconds,omitempty"`
 }
 
 type RankingResponse struct {
-	ID                     uint             `json:"id"`
-	PlayerID               *string          `json:"player_id,omitempty"`
-	Position               int              `json:"position"`
-	LastLifeTotal          *int             `json:"last_life_total,omitempty"`
-	LastLifeTotalTimestamp *time.Time       `json:"last_life_total_timestamp,omitempty"`
-	Deck                   DeckResponse     `json:"deck"`
-	Player                 *PlayerResponse  `json:"player,omitempty"` // Optional, can be omitted if not needed
-	Description            *GameDescription `json:"description,omitempty"`
+	ID                     uint                      `json:"id"`
+	PlayerID               *string                   `json:"player_id,omitempty"`
+	Position               int                       `json:"position"`
+	Team                   *int                      `json:"team,omitempty"`
+	LastLifeTotal          *int                      `json:"last_life_total,omitempty"`
+	LastLifeTotalTimestamp *time.Time                `json:"last_life_total_timestamp,omitempty"`
+	CommanderDamage        []CommanderDamageResponse `json:"commander_damage,omitempty"`
+	Counters               map[string]int            `json:"counters,omitempty"`
+	Eliminated             bool                      `json:"eliminated"`
+	EliminatedAt           *time.Time                `json:"eliminated_at,omitempty"`
+	EliminatedByRankingID  *uint                     `json:"eliminated_by_ranking_id,omitempty"`
+	EliminationReason      string                    `json:"elimination_reason,omitempty"`
+	TurnCount              int                       `json:"turn_count,omitempty"`              // Finished turns of this ranking
+	TurnSeconds            int                       `json:"turn_seconds,omitempty"`            // Total duration of the finished turns
+	ClockRemainingSeconds  *int                      `json:"clock_remaining_seconds,omitempty"` // At the start of the current turn for the active ranking
+	Deck                   DeckResponse              `json:"deck"`
+	Player                 *PlayerResponse           `json:"player,omitempty"` // Optional, can be omitted if not needed
+	Description            *GameDescription          `json:"description,omitempty"`
+}
+
+// CommanderDamageResponse is the running commander damage total one commander dealt to a ranking
+type CommanderDamageResponse struct {
+	SourceRankingID uint `json:"source_ranking_id"`
+	CommanderIndex  int  `json:"commander_index"`
+	Total           int  `json:"total"`
 }
 
 type DeckResponse struct {
@@ -125,6 +246,14 @@ type DeckResponse struct {
 	MoxfieldURL  *string  `json:"moxfield_url,omitempty"`
 	Bracket      *uint    `json:"bracket,omitempty"`
 	Themes       []string `json:"themes,omitempty"`
+	Partner      string   `json:"partner,omitempty"` // Second commander of a pairing, with its own images
+	PartnerImage string   `json:"partner_image,omitempty"`
+	PartnerCrop  string   `json:"partner_crop,omitempty"`
+	// The commanders as cards, for looking them up in /cards/v1
+	CommanderOracleID *string `json:"commander_oracle_id,omitempty"`
+	PartnerOracleID   *string `json:"partner_oracle_id,omitempty"`
+	Version           int     `json:"version,omitempty"` // For rankings the version that was played
+	Retired           bool    `json:"retired,omitempty"`
 }
 
 type CreateDeckRequest struct {
@@ -136,6 +265,66 @@ type CreateDeckRequest struct {
 	Image          string   `json:"image"`
 	SecondaryImage string   `json:"secondary_image"`
 	Crop           string   `json:"crop"`
+	Partner        string   `json:"partner,omitempty"`
+	PartnerImage   string   `json:"partner_image,omitempty"`
+	PartnerCrop    string   `json:"partner_crop,omitempty"`
+
+	// Set from the card database, not by clients
+	CommanderOracleID *string `json:"-"`
+	PartnerOracleID   *string `json:"-"`
+}
+
+type DeckDetailResponse struct {
+	Deck                 DeckResponse        `json:"deck"`
+	Player               *PlayerResponse     `json:"player,omitempty"`
+	GameCount            int                 `json:"game_count"` // Games with a confirmed result
+	WinCount             int                 `json:"win_count"`
+	Winrate              float64             `json:"winrate"`
+	AveragePosition      float64             `json:"average_position,omitempty"`
+	AverageGameDuration  *int                `json:"average_game_duration,omitempty"` // Only games with a duration count
+	WinrateHistory       []DeckWinratePoint  `json:"winrate_history"`                 // Winrate after each game, oldest first
+	RecentGames          []GameResponse      `json:"recent_games"`                    // Newest first
+	MostBeatenCommanders []DeckMatchupResult `json:"most_beaten_commanders"`
+	MostLostToCommanders []DeckMatchupResult `json:"most_lost_to_commanders"`
+	MostBeatenPlayers    []DeckMatchupResult `json:"most_beaten_players"`
+	MostLostToPlayers    []DeckMatchupResult `json:"most_lost_to_players"`
+}
+
+type DeckWinratePoint struct {
+	Date      time.Time `json:"date"`
+	GameCount int       `json:"game_count"`
+	Winrate   float64   `json:"winrate"`
+}
+
+// DeckMatchupResult counts the games against a commander or a player
+// Wins are games the deck finished ahead of them, losses games it finished behind them
+// A pairing is counted with its partner and once more for each of its cards on their own
+type DeckMatchupResult struct {
+	Commander string          `json:"commander,omitempty"`
+	Partner   string          `json:"partner,omitempty"`
+	Player    *PlayerResponse `json:"player,omitempty"`
+	Games     int             `json:"games"`
+	Wins      int             `json:"wins"`
+	Losses    int             `json:"losses"`
+}
+
+// UpdateDeckRequest changes the fields that are set, the others keep their values
+type UpdateDeckRequest struct {
+	MoxfieldURL    *string   `json:"moxfield_url,omitempty"`
+	Themes         *[]string `json:"themes,omitempty"`
+	Bracket        *uint     `json:"bracket,omitempty"`
+	Commander      *string   `json:"commander,omitempty"`
+	Colors         *[]string `json:"colors,omitempty"`
+	Image          *string   `json:"image,omitempty"`
+	SecondaryImage *string   `json:"secondary_image,omitempty"`
+	Crop           *string   `json:"crop,omitempty"`
+	Partner        *string   `json:"partner,omitempty"` // An empty partner removes it
+	PartnerImage   *string   `json:"partner_image,omitempty"`
+	PartnerCrop    *string   `json:"partner_crop,omitempty"`
+
+	// Set from the card database with the commander and partner, not by clients
+	CommanderOracleID *string `json:"-"`
+	PartnerOracleID   *string `json:"-"`
 }
 
 type SearchGamesRequest struct {
@@ -143,6 +332,7 @@ type SearchGamesRequest struct {
 	Commanders    []string `json:"commanders,omitempty"`     // Games where ANY of these commanders were played (OR)
 	AllPlayers    []string `json:"all_players,omitempty"`    // Games where ALL of these players participated (AND)
 	AllCommanders []string `json:"all_commanders,omitempty"` // Games where ALL of these commanders were played (AND)
+	Formats       []string `json:"formats,omitempty"`        // Games played in ANY of these formats (OR)
 }
 
 func (req SearchGamesRequest) ToFilter() GameFilter {
@@ -151,5 +341,6 @@ func (req SearchGamesRequest) ToFilter() GameFilter {
 		Commanders:    req.Commanders,
 		AllPlayers:    req.AllPlayers,
 		AllCommanders: req.AllCommanders,
+		Formats:       req.Formats,
 	}
 }
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// duplicateWindow is how long a game is assumed to last when neither a duration nor an end date is known
+const duplicateWindow = 3 * time.Hour
+
+// duplicateTolerance allows the clocks of two phones logging the same game to disagree
+const duplicateTolerance = 30 * time.Minute
+
+// isDuplicateCandidate reports whether two games look like the same game logged twice:
+// the same registered players, the same commanders and overlapping time windows
+func isDuplicateCandidate(a, b *Game) bool {
+	if a.ID == b.ID {
+		return false
+	}
+	playersA, playersB := registeredPlayers(a), registeredPlayers(b)
+	if len(playersA) == 0 || !equalStrings(playersA, playersB) {
+		return false
+	}
+	if !equalStrings(commanders(a), commanders(b)) {
+		return false
+	}
+
+	startA, endA := gameWindow(a)
+	startB, endB := gameWindow(b)
+	return !startA.After(endB.Add(duplicateTolerance)) && !startB.After(endA.Add(duplicateTolerance))
+}
+
+// gameWindow returns when a game was played
+func gameWindow(game *Game) (time.Time, time.Time) {
+	start := game.CreatedAt
+	if game.Date != nil {
+		start = *game.Date
+	}
+	switch {
+	case game.Duration != nil:
+		return start, start.Add(time.Duration(*game.Duration) * time.Second)
+	case game.EndDate != nil && game.EndDate.After(start) && game.EndDate.Sub(start) < backdatedThreshold:
+		return start, *game.EndDate
+	default:
+		return start, start.Add(duplicateWindow)
+	}
+}
+
+// registeredPlayers returns the sorted IDs of the players in a game, guests excluded
+func registeredPlayers(game *Game) []string {
+	players := make([]string, 0, len(game.Rankings))
+	for _, ranking := range game.Rankings {
+		if ranking.PlayerID != nil {
+			players = append(players, *ranking.PlayerID)
+		}
+	}
+	sort.Strings(players)
+	return players
+}
+
+// commanders returns the sorted commanders of all rankings of a game, guests included
+func commanders(game *Game) []string {
+	keys := make([]string, len(game.Rankings))
+	for i := range game.Rankings {
+		keys[i] = commanderOf(&game.Rankings[i])
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// commanderOf identifies the commander a ranking played, from its deck or its embedded deck
+// Commanders are compared by oracle ID, names the card database doesn't know by their lowercased spelling
+func commanderOf(ranking *Ranking) string {
+	if ranking.Deck != nil {
+		return commanderKey(ranking.Deck.Commander, ranking.Deck.CommanderOracleID)
+	}
+	return commanderKey(ranking.DeckEmbedded.Commander, ranking.DeckEmbedded.CommanderOracleID)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchRankings maps the rankings of a duplicate game to the rankings of the game it is merged into
+// Players are matched by player, guests by commander; rankings without a match are missing from the map
+func matchRankings(duplicate, survivor []Ranking) map[uint]uint {
+	matches := make(map[uint]uint)
+	taken := make(map[uint]bool)
+	for i := range duplicate {
+		for j := range survivor {
+			if taken[survivor[j].ID] {
+				continue
+			}
+			samePlayer := duplicate[i].PlayerID != nil && survivor[j].PlayerID != nil && *duplicate[i].PlayerID == *survivor[j].PlayerID
+			sameGuest := duplicate[i].PlayerID == nil && survivor[j].PlayerID == nil &&
+				commanderOf(&duplicate[i]) == commanderOf(&survivor[j])
+			if samePlayer || sameGuest {
+				matches[duplicate[i].ID] = survivor[j].ID
+				taken[survivor[j].ID] = true
+				break
+			}
+		}
+	}
+	return matches
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	FormatCommander      = "commander"
+	FormatDuel           = "duel"
+	FormatTwoHeadedGiant = "two_headed_giant"
+	FormatBrawl          = "brawl"
+	FormatOathbreaker    = "oathbreaker"
+	FormatArchenemy      = "archenemy"
+	FormatTwoVsTwo       = "two_vs_two"
+)
+
+// FormatRules describes how a game of a format is set up and when players are eliminated
+type FormatRules struct {
+	Format            string `json:"format"`
+	StartingLife      int    `json:"starting_life"`
+	CommanderDamage   int    `json:"commander_damage"`   // Damage from a single commander that eliminates, 0 if the rule doesn't apply
+	MinRankings       int    `json:"min_rankings"`       // Minimum number of rankings in a game
+	MaxRankings       int    `json:"max_rankings"`       // Maximum number of rankings in a game, 0 for no limit
+	RequiresCommander bool   `json:"requires_commander"` // Every ranking must play a deck with a commander
+	Teams             int    `json:"teams"`              // Number of teams every ranking is assigned to, 0 for free-for-all
+	TeamSize          int    `json:"team_size"`          // Number of rankings per team, 0 for any size
+	SharedTeamLife    bool   `json:"shared_team_life"`   // Teammates share a single life total
+}
+
+// Formats lists the rules of every supported format
+var Formats = map[string]FormatRules{
+	FormatCommander: {
+		Format:            FormatCommander,
+		StartingLife:      40,
+		CommanderDamage:   21,
+		MinRankings:       2,
+		RequiresCommander: true,
+	},
+	FormatDuel: {
+		Format:            FormatDuel,
+		StartingLife:      20,
+		CommanderDamage:   21,
+		MinRankings:       2,
+		MaxRankings:       2,
+		RequiresCommander: true,
+	},
+	FormatTwoHeadedGiant: {
+		Format:         FormatTwoHeadedGiant,
+		StartingLife:   30,
+		MinRankings:    4,
+		MaxRankings:    4,
+		Teams:          2,
+		TeamSize:       2,
+		SharedTeamLife: true,
+	},
+	FormatTwoVsTwo: {
+		Format:            FormatTwoVsTwo,
+		StartingLife:      40,
+		CommanderDamage:   21,
+		MinRankings:       4,
+		MaxRankings:       4,
+		RequiresCommander: true,
+		Teams:             2,
+		TeamSize:          2,
+	},
+	// The archenemy plays alone against a team of everyone else
+	FormatArchenemy: {
+		Format:            FormatArchenemy,
+		StartingLife:      40,
+		CommanderDamage:   21,
+		MinRankings:       3,
+		RequiresCommander: true,
+		Teams:             2,
+	},
+	FormatBrawl: {
+		Format:            FormatBrawl,
+		StartingLife:      25,
+		MinRankings:       2,
+		RequiresCommander: true,
+	},
+	FormatOathbreaker: {
+		Format:            FormatOathbreaker,
+		StartingLife:      20,
+		MinRankings:       2,
+		RequiresCommander: true,
+	},
+}
+
+// GetFormatRules returns the rules of a format, treating an empty format as Commander
+func GetFormatRules(format string) (FormatRules, bool) {
+	if format == "" {
+		format = FormatCommander
+	}
+	rules, ok := Formats[format]
+	return rules, ok
+}
+
+// rulesForGame returns the rules of a game's format, falling back to Commander for unknown formats
+func rulesForGame(game *Game) FormatRules {
+	rules, ok := GetFormatRules(game.Format)
+	if !ok {
+		return Formats[FormatCommander]
+	}
+	return rules
+}
+
+// validateRankingsForFormat checks the number of rankings, their teams and their decks against the rules of a format
+// Decks are optional in formats that don't require a commander, but a ranking can't have both a deck_id and a deck
+func validateRankingsForFormat(rules FormatRules, rankings []CreateRankingRequest) error {
+	if len(rankings) < rules.MinRankings {
+		return fmt.Errorf("%s requires at least %d rankings", rules.Format, rules.MinRankings)
+	}
+	if rules.MaxRankings > 0 && len(rankings) > rules.MaxRankings {
+		return fmt.Errorf("%s allows at most %d rankings", rules.Format, rules.MaxRankings)
+	}
+
+	for i, rank := range rankings {
+		if rank.DeckID != nil && rank.Deck != nil {
+			return errors.New("Each ranking must have either deck_id OR deck, not both")
+		}
+		if !rules.RequiresCommander {
+			continue
+		}
+		if rank.DeckID == nil && rank.Deck == nil {
+			return errors.New("Each ranking must have either deck_id or deck provided")
+		}
+		// If inline deck is provided, validate required fields
+		if rank.Deck != nil && rank.Deck.Commander == "" {
+			return fmt.Errorf("Ranking %d: deck.commander is required", i)
+		}
+	}
+	return validateTeams(rules, rankings)
+}
+
+// validateTeams checks that every ranking of a team format is on a team and that the teams have the right size
+// Free-for-all formats don't accept teams
+func validateTeams(rules FormatRules, rankings []CreateRankingRequest) error {
+	teamSizes := make(map[int]int)
+	for i, rank := range rankings {
+		if rank.Team == nil {
+			if rules.Teams > 0 {
+				return fmt.Errorf("Ranking %d: team is required in %s", i, rules.Format)
+			}
+			continue
+		}
+		if rules.Teams == 0 {
+			return fmt.Errorf("%s is played without teams", rules.Format)
+		}
+		teamSizes[*rank.Team]++
+	}
+
+	if rules.Teams > 0 && len(teamSizes) != rules.Teams {
+		return fmt.Errorf("%s requires %d teams", rules.Format, rules.Teams)
+	}
+	// Check the teams in order so the error names the same team every time
+	teams := make([]int, 0, len(teamSizes))
+	for team := range teamSizes {
+		teams = append(teams, team)
+	}
+	sort.Ints(teams)
+	for _, team := range teams {
+		if rules.TeamSize > 0 && teamSizes[team] != rules.TeamSize {
+			return fmt.Errorf("team %d must have %d rankings", team, rules.TeamSize)
+		}
+	}
+	if rules.Format == FormatArchenemy && !hasTeamOfSize(teamSizes, 1) {
+		return errors.New("archenemy requires a team with only the archenemy")
+	}
+	return nil
+}
+
+func hasTeamOfSize(teamSizes map[int]int, size int) bool {
+	for _, teamSize := range teamSizes {
+		if teamSize == size {
+			return true
+		}
+	}
+	return false
+}
+
+// lifeSharingRankings returns the IDs of the rankings that share their life total with each ranking
+// Only rankings on a team in a format with shared team life are included
+func lifeSharingRankings(rankings []Ranking, rules FormatRules) map[uint][]uint {
+	if !rules.SharedTeamLife {
+		return nil
+	}
+
+	teams := make(map[int][]uint)
+	for _, ranking := range rankings {
+		if ranking.Team != nil {
+			teams[*ranking.Team] = append(teams[*ranking.Team], ranking.ID)
+		}
+	}
+
+	result := make(map[uint][]uint)
+	for _, ranking := range rankings {
+		if ranking.Team != nil {
+			result[ranking.ID] = teams[*ranking.Team]
+		}
+	}
+	return result
+}
@@ -8,10 +8,13 @@ type GameFilter struct {
 	Commanders    []string // Games where ANY of these commanders were played (OR)
 	AllPlayers    []string // Games where ALL of these players participated (AND)
 	AllCommanders []string // Games where ALL of these commanders were played (AND)
+	Formats       []string // Games played in ANY of these formats (OR)
 }
 
 // ApplyGameFilters applies the filter criteria to a GORM query
 func ApplyGameFilters(db *gorm.DB, filter GameFilter) *gorm.DB {
+	// A session keeps the conditions of one filter out of the subqueries of the next
+	db = db.Session(&gorm.Session{})
 	query := db
 
 	// Filter by player IDs (OR condition - any of these players)
@@ -24,14 +27,7 @@ func ApplyGameFilters(db *gorm.DB, filter GameFilter) *gorm.DB {
 
 	// Filter by commanders (OR condition - any of these commanders)
 	if len(filter.Commanders) > 0 {
-		// Search in both DeckEmbedded.Commander (embedded field) and referenced Deck.Commander
-		// When using gorm:"embedded", fields are flattened with no prefix
-		subQuery := db.Session(&gorm.Session{}).Model(&Ranking{}).
-			Select("DISTINCT game_id").
-			Joins("LEFT JOIN decks ON rankings.deck_id = decks.id").
-			Where("rankings.commander IN ? OR decks.commander IN ?",
-				filter.Commanders, filter.Commanders)
-		query = query.Where("id IN (?)", subQuery)
+		query = query.Where("id IN (?)", commanderGames(db, filter.Commanders))
 	}
 
 	// Filter by all players (AND condition - all of these players must be in the game)
@@ -49,17 +45,34 @@ func ApplyGameFilters(db *gorm.DB, filter GameFilter) *gorm.DB {
 	if len(filter.AllCommanders) > 0 {
 		// For each commander, ensure they're in the game
 		for _, commander := range filter.AllCommanders {
-			subQuery := db.Session(&gorm.Session{}).Model(&Ranking{}).
-				Select("DISTINCT game_id").
-				Joins("LEFT JOIN decks ON rankings.deck_id = decks.id").
-				Where("rankings.commander = ? OR decks.commander = ?", commander, commander)
-			query = query.Where("id IN (?)", subQuery)
+			query = query.Where("id IN (?)", commanderGames(db, []string{commander}))
 		}
 	}
 
+	// Filter by formats (OR condition - any of these formats)
+	if len(filter.Formats) > 0 {
+		query = query.Where("format IN ?", filter.Formats)
+	}
+
 	return query
 }
 
+// commanderGames selects the games where any of the commanders was played
+// Search in both DeckEmbedded (embedded fields) and the referenced Deck, either commander of a pairing matches
+// Known commanders match by oracle ID, whatever spelling was stored, the others by their name
+func commanderGames(db *gorm.DB, commanders []string) *gorm.DB {
+	oracleIDs := db.Session(&gorm.Session{}).Model(&Commander{}).
+		Select("oracle_id").
+		Where("name IN ?", commanders)
+	// When using gorm:"embedded", fields are flattened with no prefix
+	return db.Session(&gorm.Session{}).Model(&Ranking{}).
+		Select("DISTINCT game_id").
+		Joins("LEFT JOIN decks ON rankings.deck_id = decks.id").
+		Where("rankings.commander IN ? OR rankings.partner IN ? OR decks.commander IN ? OR decks.partner IN ? OR "+
+			"rankings.commander_oracle_id IN (?) OR rankings.partner_oracle_id IN (?) OR decks.commander_oracle_id IN (?) OR decks.partner_oracle_id IN (?)",
+			commanders, commanders, commanders, commanders, oracleIDs, oracleIDs, oracleIDs, oracleIDs)
+}
+
 // SearchGamesWithFilters searches games with complex filtering
 func (r *Repository) SearchGamesWithFilters(filter GameFilter, limit, offset int) ([]Game, int64, error) {
 	var games []Game
@@ -80,9 +93,9 @@ func (r *Repository) SearchGamesWithFilters(filter GameFilter, limit, offset int
 
 	err := query.
 		Preload("Rankings", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("Player").Preload("Deck")
+			return db.Preload("Player").Preload("Deck", withDeletedDecks).Preload("DeckVersion")
 		}).
-		Preload("GameEvents").
+		Preload("GameEvents", eventsInOrder).
 		Preload("Creator").
 		Order("date DESC").
 		Limit(limit).
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// Reasons a ranking can be eliminated from a game
+const (
+	EliminationReasonLife            = "life"
+	EliminationReasonCommanderDamage = "commander_damage"
+	EliminationReasonPoison          = "poison"
+	EliminationReasonScoop           = "scoop"
+	EliminationReasonTimeout         = "timeout"
+)
+
+// commanderDamageKey identifies a single commander dealing damage to a ranking
+// Partner commanders of the same ranking are tracked separately by their index
+type commanderDamageKey struct {
+	SourceRankingID uint
+	CommanderIndex  int
+}
+
+// rankingState is the state of a ranking derived by replaying the events of a game
+type rankingState struct {
+	Life            *int
+	CommanderDamage map[commanderDamageKey]int
+	Counters        map[string]int
+	Scooped         bool
+
+	Eliminated            bool
+	EliminatedAt          *time.Time
+	EliminatedByRankingID *uint
+	EliminationReason     string
+}
+
+func newRankingState() *rankingState {
+	return &rankingState{
+		CommanderDamage: make(map[commanderDamageKey]int),
+		Counters:        make(map[string]int),
+	}
+}
+
+// eliminationReason returns why the ranking is currently eliminated, or an empty string if it isn't
+// Commander damage is only lethal if the format has a commander damage rule
+func (state *rankingState) eliminationReason(commanderDamageLethal int) string {
+	switch {
+	case state.Scooped:
+		return EliminationReasonScoop
+	case state.Counters[CounterPoison] >= poisonLethal:
+		return EliminationReasonPoison
+	case state.Life != nil && *state.Life <= 0:
+		return EliminationReasonLife
+	}
+	if commanderDamageLethal == 0 {
+		return ""
+	}
+	for _, total := range state.CommanderDamage {
+		if total >= commanderDamageLethal {
+			return EliminationReasonCommanderDamage
+		}
+	}
+	return ""
+}
+
+// deriveRankingStates replays the game events in order and returns the state per ranking ID
+// Commander damage events carry the life change in DamageDelta, so damage is recorded as a negative delta
+// Counter events carry the counter change in DamageDelta and never take a counter below zero
+// Voided events are skipped
+// lifeSharing maps rankings to the rankings sharing their life total, see lifeSharingRankings; it may be nil
+// A ranking is eliminated by the event that takes it to 0 life, lethal commander damage, 10 poison or a scoop
+func deriveRankingStates(gameEvents []GameEvent, rules FormatRules, lifeSharing map[uint][]uint) map[uint]*rankingState {
+	states := make(map[uint]*rankingState)
+	stateFor := func(rankingID uint) *rankingState {
+		state, ok := states[rankingID]
+		if !ok {
+			state = newRankingState()
+			states[rankingID] = state
+		}
+		return state
+	}
+
+	for _, event := range gameEvents {
+		if event.VoidedAt != nil {
+			continue
+		}
+		targetID := event.TargetRankingID
+		if event.EventType == EventTypeScoop && targetID == nil {
+			// A player scooping may only be recorded as the source of the event
+			targetID = event.SourceRankingID
+		}
+		if targetID == nil {
+			continue
+		}
+		target := stateFor(*targetID)
+
+		switch event.EventType {
+		case EventTypeCommanderDamage:
+			if event.SourceRankingID == nil {
+				break
+			}
+			key := commanderDamageKey{SourceRankingID: *event.SourceRankingID, CommanderIndex: event.CommanderIndex}
+			total := target.CommanderDamage[key] - event.DamageDelta
+			if total < 0 {
+				total = 0
+			}
+			target.CommanderDamage[key] = total
+		case EventTypeIncrement, EventTypeDecrement:
+			if event.CounterType == "" {
+				break
+			}
+			total := target.Counters[event.CounterType] + event.DamageDelta
+			if total < 0 {
+				total = 0
+			}
+			target.Counters[event.CounterType] = total
+		case EventTypeScoop:
+			target.Scooped = true
+		}
+
+		// The life total of a team with shared life changes for every teammate
+		affected := []uint{*targetID}
+		if teammates, ok := lifeSharing[*targetID]; ok {
+			affected = teammates
+		}
+		if event.CounterType == "" && event.EventType != EventTypeScoop {
+			for _, rankingID := range affected {
+				life := event.TargetLifeTotalAfter
+				stateFor(rankingID).Life = &life
+			}
+		} else {
+			affected = []uint{*targetID}
+		}
+
+		for _, rankingID := range affected {
+			updateElimination(stateFor(rankingID), rankingID, &event, rules)
+		}
+	}
+
+	return states
+}
+
+// updateElimination records the event that eliminated a ranking, or clears the elimination if it no longer holds
+func updateElimination(state *rankingState, rankingID uint, event *GameEvent, rules FormatRules) {
+	reason := state.eliminationReason(rules.CommanderDamage)
+	switch {
+	case reason == "":
+		state.Eliminated = false
+		state.EliminatedAt = nil
+		state.EliminatedByRankingID = nil
+		state.EliminationReason = ""
+	case !state.Eliminated:
+		eliminatedAt := event.CreatedAt
+		state.Eliminated = true
+		state.EliminatedAt = &eliminatedAt
+		state.EliminationReason = reason
+		// Players eliminate themselves by scooping or by their own damage
+		if event.SourceRankingID != nil && *event.SourceRankingID != rankingID {
+			source := *event.SourceRankingID
+			state.EliminatedByRankingID = &source
+		}
+	}
+}
+
+// side identifies the rankings playing together: a team, or a single ranking in free-for-all
+type side struct {
+	Team      int
+	RankingID uint
+}
+
+func sideOf(ranking Ranking) side {
+	if ranking.Team != nil {
+		return side{Team: *ranking.Team}
+	}
+	return side{RankingID: ranking.ID}
+}
+
+// proposeFinalPositions orders rankings once at most one side is left standing
+// The surviving side finishes first and the others follow in reverse order of elimination
+// Teammates share a position; a team is eliminated once all of its rankings are
+// Returns false if more than one side is still in the game
+func proposeFinalPositions(rankings []Ranking) ([]Ranking, bool) {
+	type sideState struct {
+		Eliminated   bool
+		EliminatedAt *time.Time
+	}
+	sides := make(map[side]*sideState)
+	for _, ranking := range rankings {
+		state, ok := sides[sideOf(ranking)]
+		if !ok {
+			state = &sideState{Eliminated: true}
+			sides[sideOf(ranking)] = state
+		}
+		if !ranking.Eliminated {
+			state.Eliminated = false
+			continue
+		}
+		// A team is out at the time its last ranking was eliminated
+		if ranking.EliminatedAt != nil && (state.EliminatedAt == nil || ranking.EliminatedAt.After(*state.EliminatedAt)) {
+			state.EliminatedAt = ranking.EliminatedAt
+		}
+	}
+
+	remaining := 0
+	for _, state := range sides {
+		if !state.Eliminated {
+			remaining++
+		}
+	}
+	if len(sides) < 2 || remaining > 1 {
+		return nil, false
+	}
+
+	ordered := make([]Ranking, len(rankings))
+	copy(ordered, rankings)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		sideA, sideB := sideOf(ordered[i]), sideOf(ordered[j])
+		a, b := sides[sideA], sides[sideB]
+		if a.Eliminated != b.Eliminated {
+			return !a.Eliminated
+		}
+		if a.EliminatedAt == nil || b.EliminatedAt == nil {
+			if (a.EliminatedAt == nil) != (b.EliminatedAt == nil) {
+				return a.EliminatedAt != nil
+			}
+		} else if !a.EliminatedAt.Equal(*b.EliminatedAt) {
+			return a.EliminatedAt.After(*b.EliminatedAt)
+		}
+		// Keep teammates next to each other
+		return sideA.Team < sideB.Team
+	})
+
+	position := 0
+	for i := range ordered {
+		if i == 0 || sideOf(ordered[i]) != sideOf(ordered[i-1]) {
+			position++
+		}
+		ordered[i].Position = position
+	}
+	return ordered, true
+}
+
+// convertCounters returns the non-zero counters of a ranking state
+func convertCounters(state *rankingState) map[string]int {
+	if state == nil {
+		return nil
+	}
+
+	var result map[string]int
+	for counter, total := range state.Counters {
+		if total == 0 {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]int)
+		}
+		result[counter] = total
+	}
+	return result
+}
+
+// convertCommanderDamage converts the commander damage of a ranking state to a stable, sorted response
+func convertCommanderDamage(state *rankingState) []CommanderDamageResponse {
+	if state == nil || len(state.CommanderDamage) == 0 {
+		return nil
+	}
+
+	result := make([]CommanderDamageResponse, 0, len(state.CommanderDamage))
+	for key, total := range state.CommanderDamage {
+		result = append(result, CommanderDamageResponse{
+			SourceRankingID: key.SourceRankingID,
+			CommanderIndex:  key.CommanderIndex,
+			Total:           total,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SourceRankingID != result[j].SourceRankingID {
+			return result[i].SourceRankingID < result[j].SourceRankingID
+		}
+		return result[i].CommanderIndex < result[j].CommanderIndex
+	})
+	return result
+}
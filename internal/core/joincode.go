@@ -0,0 +1,72 @@
+package core
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// joinCodeAlphabet leaves out characters that are easily confused when read aloud or typed: 0/O and 1/I/L
+const joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const joinCodeLength = 6
+
+// generateJoinCode returns a random code players can use to join an unfinished game
+func generateJoinCode() (string, error) {
+	code := make([]byte, joinCodeLength)
+	max := big.NewInt(int64(len(joinCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = joinCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// normalizeJoinCode makes join codes case-insensitive and tolerant of surrounding whitespace
+func normalizeJoinCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validateJoiningRanking checks that a new ranking can be added to a game without breaking its format's rules
+func validateJoiningRanking(game *Game, request JoinGameRequest) error {
+	rules := rulesForGame(game)
+	if rules.MaxRankings > 0 && len(game.Rankings) >= rules.MaxRankings {
+		return errors.New("game is full")
+	}
+
+	// The deck rules apply to the new ranking on its own, the ranking counts and teams to the whole game
+	deckRules := rules
+	deckRules.MinRankings, deckRules.MaxRankings, deckRules.Teams = 0, 0, 0
+	if err := validateRankingsForFormat(deckRules, []CreateRankingRequest{{DeckID: request.DeckID, Deck: request.Deck}}); err != nil {
+		return err
+	}
+
+	if rules.Teams == 0 {
+		if request.Team != nil {
+			return fmt.Errorf("%s is played without teams", rules.Format)
+		}
+		return nil
+	}
+	if request.Team == nil {
+		return fmt.Errorf("team is required in %s", rules.Format)
+	}
+	teams := make(map[int]int)
+	for _, ranking := range game.Rankings {
+		if ranking.Team != nil {
+			teams[*ranking.Team]++
+		}
+	}
+	teamSize, exists := teams[*request.Team]
+	if !exists && len(teams) >= rules.Teams {
+		return fmt.Errorf("%s requires %d teams", rules.Format, rules.Teams)
+	}
+	if rules.TeamSize > 0 && teamSize >= rules.TeamSize {
+		return fmt.Errorf("team %d is full", *request.Team)
+	}
+	return nil
+}
@@ -15,6 +15,9 @@ const (
 	EventTypeDecrement = "decrement"
 	EventTypeImage     = "image"
 	EventTypeScoop     = "scoop"
+
+	EventTypeCommanderDamage = "commander_damage"
+	EventTypeTurnPass        = "turn_pass"
 )
 
 type Deck struct {
@@ -27,11 +30,23 @@ type Deck struct {
 	Image          string   `json:"image"`
 	SecondaryImage string   `json:"secondary_image"`
 	Crop           string   `json:"crop"`
-	PlayerID       *string  `json:"player_id,omitempty"`
-	GameCount      int      `gorm:"default:0" json:"game_count"`
-	WinCount       int      `gorm:"default:0" json:"win_count"`
+	// The commanders as cards, nil for names the card database doesn't know
+	CommanderOracleID *string `gorm:"index" json:"commander_oracle_id,omitempty"`
+	PartnerOracleID   *string `gorm:"index" json:"partner_oracle_id,omitempty"`
+	// The second commander of a pairing: partner, background, Friends Forever or Doctor's companion
+	Partner      string  `json:"partner,omitempty"`
+	PartnerImage string  `json:"partner_image,omitempty"`
+	PartnerCrop  string  `json:"partner_crop,omitempty"`
+	PlayerID     *string `json:"player_id,omitempty"`
+	GameCount    int     `gorm:"default:0" json:"game_count"`
+	WinCount     int     `gorm:"default:0" json:"win_count"`
+	Version      int     `gorm:"default:1" json:"version"` // Increases with every change of what the deck is, see DeckVersion
+	// Retired decks are hidden from deck pickers but keep counting in statistics
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
 
-	Player *Player `gorm:"foreignKey:PlayerID;references:FirebaseID" json:"player,omitempty"`
+	Player        *Player    `gorm:"foreignKey:PlayerID;references:FirebaseID" json:"player,omitempty"`
+	CommanderCard *Commander `gorm:"foreignKey:CommanderOracleID;references:OracleID" json:"-"`
+	PartnerCard   *Commander `gorm:"foreignKey:PartnerOracleID;references:OracleID" json:"-"`
 }
 
 type SimpleDeck struct {
@@ -39,6 +54,12 @@ type SimpleDeck struct {
 	Image          string `json:"image"`
 	SecondaryImage string `json:"secondary_image"`
 	Crop           string `json:"crop"`
+	Partner        string `json:"partner,omitempty"`
+	PartnerImage   string `json:"partner_image,omitempty"`
+	PartnerCrop    string `json:"partner_crop,omitempty"`
+	// Embedded in rankings, so the columns keep these names
+	CommanderOracleID *string `gorm:"index" json:"commander_oracle_id,omitempty"`
+	PartnerOracleID   *string `gorm:"index" json:"partner_oracle_id,omitempty"`
 }
 
 type Player struct {
@@ -47,6 +68,7 @@ type Player struct {
 	Email            string `gorm:"unique;not null" json:"email"`
 	Image            string
 	MoxfieldUsername string `json:"moxfield_username"`
+	Admin            bool   `gorm:"default:false" json:"admin"` // Admins can delete any game
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	DeletedAt        gorm.DeletedAt `gorm:"index"`
@@ -56,6 +78,8 @@ type Player struct {
 type Game struct {
 	gorm.Model
 	CreatorID  *string `json:"creator_id,omitempty"`
+	JoinCode   *string `gorm:"uniqueIndex" json:"join_code,omitempty"` // Set while the game is unfinished
+	Format     string  `gorm:"default:'commander'" json:"format"`
 	Duration   *int
 	Date       *time.Time
 	EndDate    *time.Time
@@ -64,10 +88,62 @@ type Game struct {
 	Rankings   []Ranking
 	Finished   bool
 	GameEvents []GameEvent // Add relation: a game has many game events
+	Pauses     []GamePause `json:"pauses,omitempty"`
+
+	// Confirmation of the final positions by the participants, see ResultStatusPending
+	ResultStatus      string           `gorm:"default:''" json:"result_status,omitempty"`
+	ResultConfirmedAt *time.Time       `json:"result_confirmed_at,omitempty"`
+	ResultVotes       []GameResultVote `json:"result_votes,omitempty"`
+
+	MergedIntoID *uint `json:"merged_into_id,omitempty"` // Set on a duplicate merged into another game, it can't be restored
+
+	// Chess clock, ClockInitialSeconds is nil for untimed games
+	ClockInitialSeconds     *int `json:"clock_initial_seconds,omitempty"`
+	ClockIncrementSeconds   int  `gorm:"default:0" json:"clock_increment_seconds"`
+	ClockEliminateOnTimeout bool `gorm:"default:false" json:"clock_eliminate_on_timeout"`
 
 	Creator *Player `gorm:"foreignKey:CreatorID;references:FirebaseID" json:"creator,omitempty"`
 }
 
+// GamePause is an interval in which a game was paused, ResumedAt is nil while it still is
+type GamePause struct {
+	gorm.Model
+	GameID     uint       `gorm:"index" json:"game_id"`
+	PausedAt   time.Time  `json:"paused_at"`
+	ResumedAt  *time.Time `json:"resumed_at,omitempty"`
+	PausedByID *string    `json:"paused_by_id,omitempty"`
+}
+
+// GameResultVote is a participant's confirmation or dispute of the final positions of a game
+type GameResultVote struct {
+	gorm.Model
+	GameID    uint   `gorm:"uniqueIndex:idx_result_vote" json:"game_id"`
+	PlayerID  string `gorm:"uniqueIndex:idx_result_vote" json:"player_id"`
+	Confirmed bool   `json:"confirmed"`
+	Comment   string `json:"comment,omitempty"` // What a disputing player thinks is wrong
+}
+
+// Status of a ranking claim
+const (
+	ClaimStatusPending  = "pending"
+	ClaimStatusAccepted = "accepted"
+	ClaimStatusRejected = "rejected"
+)
+
+// RankingClaim proposes that a guest ranking was played by a player
+// The creator or a participant of the game proposes it and the player accepts or rejects it
+type RankingClaim struct {
+	gorm.Model
+	RankingID    uint   `gorm:"index" json:"ranking_id"`
+	GameID       uint   `json:"game_id"`
+	PlayerID     string `gorm:"index" json:"player_id"` // The player the ranking is proposed to
+	ProposedByID string `json:"proposed_by_id"`
+	Status       string `gorm:"default:'pending'" json:"status"`
+
+	Ranking    *Ranking `gorm:"foreignKey:RankingID;references:ID" json:"ranking,omitempty"`
+	ProposedBy *Player  `gorm:"foreignKey:ProposedByID;references:FirebaseID" json:"proposed_by,omitempty"`
+}
+
 type CardReference struct {
 	Name                string   `json:"name"`
 	OracleText          string   `json:"oracle_text"`
@@ -84,9 +160,9 @@ type PlayerReference struct {
 }
 
 type GameDescription struct {
-	Text             string                    `json:"text"`
-	CardReferences   map[string]CardReference  `json:"card_references"`   // keyed by card name
-	PlayerReferences []PlayerReference         `json:"player_references"` // player IDs and names referenced
+	Text             string                   `json:"text"`
+	CardReferences   map[string]CardReference `json:"card_references"`   // keyed by card name
+	PlayerReferences []PlayerReference        `json:"player_references"` // player IDs and names referenced
 }
 
 // Scan implements sql.Scanner interface for GameDescription
@@ -110,19 +186,31 @@ func (gd GameDescription) Value() (driver.Value, error) {
 
 type Ranking struct {
 	gorm.Model
-	GameID         uint             `json:"game_id"`
-	PlayerID       *string          `json:"player_id,omitempty"`
-	DeckID         *uint            `json:"deck_id,omitempty"` // Reference to player's deck (optional)
-	Position       int              `json:"position"`
-	CouldHaveWon   bool             `json:"could_have_won"`
-	EarlySolRing   bool             `json:"early_sol_ring"`
-	StartingPlayer bool             `json:"starting_player"`
-	Description    *GameDescription `json:"description,omitempty" gorm:"type:jsonb"`
-	PlayerName     string           `gorm:"-"`
+	GameID         uint       `json:"game_id"`
+	PlayerID       *string    `json:"player_id,omitempty"`
+	DeckID         *uint      `json:"deck_id,omitempty"` // Reference to player's deck (optional)
+	Position       int        `json:"position"`          // Teammates share the same position
+	Team           *int       `json:"team,omitempty"`    // Rankings with the same team play on one side, nil for free-for-all
+	CouldHaveWon   bool       `json:"could_have_won"`
+	EarlySolRing   bool       `json:"early_sol_ring"`
+	StartingPlayer bool       `json:"starting_player"`
+	Eliminated     bool       `gorm:"default:false" json:"eliminated"`
+	EliminatedAt   *time.Time `json:"eliminated_at,omitempty"`
+	// Ranking whose event eliminated this one, nil for scoops and self-inflicted eliminations
+	EliminatedByRankingID *uint            `json:"eliminated_by_ranking_id,omitempty"`
+	EliminationReason     string           `gorm:"default:''" json:"elimination_reason,omitempty"`
+	ClockRemainingSeconds *int             `json:"clock_remaining_seconds,omitempty"` // Time left on the chess clock when the ranking's turn starts
+	DeckVersionID         *uint            `json:"deck_version_id,omitempty"`         // Version of the deck that was played
+	Description           *GameDescription `json:"description,omitempty" gorm:"type:jsonb"`
+	PlayerName            string           `gorm:"-"`
 
-	Player       *Player    `gorm:"foreignKey:PlayerID;references:FirebaseID" json:"player,omitempty"`
-	Deck         *Deck      `gorm:"foreignKey:DeckID;references:ID" json:"deck,omitempty"` // Reference to Deck model
-	DeckEmbedded SimpleDeck `gorm:"embedded" json:"deck_embedded,omitempty"`               // Embedded deck info for games without deck reference
+	Player       *Player      `gorm:"foreignKey:PlayerID;references:FirebaseID" json:"player,omitempty"`
+	Deck         *Deck        `gorm:"foreignKey:DeckID;references:ID" json:"deck,omitempty"` // Reference to Deck model
+	DeckEmbedded SimpleDeck   `gorm:"embedded" json:"deck_embedded,omitempty"`               // Embedded deck info for games without deck reference
+	DeckVersion  *DeckVersion `gorm:"foreignKey:DeckVersionID;references:ID" json:"deck_version,omitempty"`
+	// The commanders of the embedded deck
+	CommanderCard *Commander `gorm:"foreignKey:CommanderOracleID;references:OracleID" json:"-"`
+	PartnerCard   *Commander `gorm:"foreignKey:PartnerOracleID;references:OracleID" json:"-"`
 }
 
 type DeckWin struct {
@@ -144,11 +232,76 @@ type GameEvent struct {
 	EventType            string
 	DamageDelta          int
 	TargetLifeTotalAfter int
-	SourceRankingID      *uint   // Made nullable with pointer
-	TargetRankingID      *uint   // Made nullable with pointer
-	ImageUrl             string  // New field for uploaded image URL
-	Comment              *string // New field for text description
+	SourceRankingID      *uint      // Made nullable with pointer
+	TargetRankingID      *uint      // Made nullable with pointer
+	ImageUrl             string     // New field for uploaded image URL
+	Comment              *string    // New field for text description
+	CommanderIndex       int        `gorm:"default:0"`  // Which commander of the source dealt the damage: 0 = commander, 1 = partner
+	CounterType          string     `gorm:"default:''"` // Counter changed by DamageDelta instead of the life total (poison, energy, ...), empty for life
+	VoidedAt             *time.Time // Set when the event was undone; voided events are ignored when deriving state
+	VoidedByID           *string
+	TurnNumber           int  `gorm:"default:0"` // For turn passes: the round of the turn that starts
+	TurnDurationSeconds  *int // For turn passes: how long the turn that ended took
 
 	SourceRanking *Ranking `gorm:"foreignKey:SourceRankingID;references:ID"` // Made nullable with pointer
 	TargetRanking *Ranking `gorm:"foreignKey:TargetRankingID;references:ID"` // Made nullable with pointer
 }
+
+// AuditEntry is an append-only record of a change to a game, ranking, game event or deck
+// Entries are never updated or deleted, they outlive the entities they describe
+type AuditEntry struct {
+	ID         uint                   `gorm:"primarykey"`
+	CreatedAt  time.Time              `gorm:"index"`
+	GameID     *uint                  `gorm:"index"` // Game the change belongs to, nil for decks
+	EntityType string                 `gorm:"not null;index:idx_audit_entity"`
+	EntityID   uint                   `gorm:"index:idx_audit_entity"`
+	Action     string                 `gorm:"not null"`
+	ActorID    *string                // nil for changes made by the server, e.g. results confirmed by the timeout
+	Changes    map[string]AuditChange `gorm:"serializer:json"`
+
+	Actor *Player `gorm:"foreignKey:ActorID;references:FirebaseID"`
+}
+
+// AuditChange is the value of a field before and after a change
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DeckVersion is a snapshot of a deck, a new version is stored whenever what the deck is changes
+// Rankings keep the version that was played, versions are never deleted
+type DeckVersion struct {
+	gorm.Model
+	DeckID         uint     `gorm:"uniqueIndex:idx_deck_version" json:"deck_id"`
+	Version        int      `gorm:"uniqueIndex:idx_deck_version" json:"version"`
+	Commander      string   `json:"commander"`
+	MoxfieldURL    *string  `json:"moxfield_url"`
+	Themes         []string `gorm:"serializer:json" json:"themes"`
+	Bracket        *uint    `json:"bracket"`
+	Colors         []string `gorm:"serializer:json" json:"colors"`
+	Image          string   `json:"image"`
+	SecondaryImage string   `json:"secondary_image"`
+	Crop           string   `json:"crop"`
+	Partner        string   `json:"partner,omitempty"`
+	PartnerImage   string   `json:"partner_image,omitempty"`
+	PartnerCrop    string   `json:"partner_crop,omitempty"`
+
+	CommanderOracleID *string `json:"commander_oracle_id,omitempty"`
+	PartnerOracleID   *string `json:"partner_oracle_id,omitempty"`
+
+	CommanderCard *Commander `gorm:"foreignKey:CommanderOracleID;references:OracleID" json:"-"`
+	PartnerCard   *Commander `gorm:"foreignKey:PartnerOracleID;references:OracleID" json:"-"`
+}
+
+// Commander is a card that was played as a commander, keyed by its Scryfall oracle ID
+// Decks and rankings reference it by oracle ID, so differently written names count as one commander
+type Commander struct {
+	OracleID       string   `gorm:"primaryKey" json:"oracle_id"`
+	Name           string   `gorm:"uniqueIndex;not null" json:"name"`
+	ColorIdentity  []string `gorm:"serializer:json" json:"color_identity"`
+	Image          string   `json:"image"`
+	SecondaryImage string   `json:"secondary_image"`
+	Crop           string   `json:"crop"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
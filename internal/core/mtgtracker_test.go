@@ -1,7 +1,15 @@
 package core
 
 import (
+	"fmt"
+	"mtgtracker/pkg/cards"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -9,6 +17,7 @@ import (
 func TestValidateAndReorderRankings(t *testing.T) {
 	// Helper function to create string pointers
 	strPtr := func(s string) *string { return &s }
+	team := func(team int) *int { return &team }
 
 	tests := []struct {
 		name              string
@@ -90,6 +99,38 @@ func TestValidateAndReorderRankings(t *testing.T) {
 			expectError:       false,
 			expectedPositions: []int{1},
 		},
+		{
+			name: "teammates share a position",
+			requestRankings: []UpdateRanking{
+				{RankingID: 3, Position: 0},
+				{RankingID: 4, Position: 0},
+				{RankingID: 1, Position: 0},
+				{RankingID: 2, Position: 0},
+			},
+			existingRankings: []Ranking{
+				{Model: gorm.Model{ID: 1}, PlayerID: strPtr("player1"), Team: team(1)},
+				{Model: gorm.Model{ID: 2}, PlayerID: strPtr("player2"), Team: team(1)},
+				{Model: gorm.Model{ID: 3}, PlayerID: strPtr("player3"), Team: team(2)},
+				{Model: gorm.Model{ID: 4}, PlayerID: strPtr("player4"), Team: team(2)},
+			},
+			expectError:       false,
+			expectedPositions: []int{1, 1, 2, 2},
+		},
+		{
+			name: "teammates split up",
+			requestRankings: []UpdateRanking{
+				{RankingID: 1, Position: 0},
+				{RankingID: 3, Position: 0},
+				{RankingID: 2, Position: 0},
+			},
+			existingRankings: []Ranking{
+				{Model: gorm.Model{ID: 1}, PlayerID: strPtr("player1"), Team: team(1)},
+				{Model: gorm.Model{ID: 2}, PlayerID: strPtr("player2"), Team: team(1)},
+				{Model: gorm.Model{ID: 3}, PlayerID: strPtr("player3"), Team: team(2)},
+			},
+			expectError:  true,
+			errorMessage: "teammates must be listed next to each other",
+		},
 	}
 
 	for _, tt := range tests {
@@ -134,3 +175,1824 @@ func TestValidateAndReorderRankings(t *testing.T) {
 		})
 	}
 }
+
+func TestDeriveRankingStatesCommanderDamage(t *testing.T) {
+	uintPtr := func(u uint) *uint { return &u }
+
+	commanderDamage := func(source, target uint, commanderIndex, delta int) GameEvent {
+		return GameEvent{
+			EventType:            EventTypeCommanderDamage,
+			SourceRankingID:      uintPtr(source),
+			TargetRankingID:      uintPtr(target),
+			CommanderIndex:       commanderIndex,
+			DamageDelta:          delta,
+			TargetLifeTotalAfter: 40, // Life isn't under test here
+		}
+	}
+
+	tests := []struct {
+		name               string
+		events             []GameEvent
+		target             uint
+		expectedTotals     map[commanderDamageKey]int
+		expectedEliminated bool
+	}{
+		{
+			name: "running total from one commander",
+			events: []GameEvent{
+				commanderDamage(1, 2, 0, -5),
+				commanderDamage(1, 2, 0, -7),
+			},
+			target:         2,
+			expectedTotals: map[commanderDamageKey]int{{SourceRankingID: 1}: 12},
+		},
+		{
+			name: "21 damage from one commander eliminates",
+			events: []GameEvent{
+				commanderDamage(1, 2, 0, -10),
+				commanderDamage(1, 2, 0, -11),
+			},
+			target:             2,
+			expectedTotals:     map[commanderDamageKey]int{{SourceRankingID: 1}: 21},
+			expectedEliminated: true,
+		},
+		{
+			name: "partners are tracked separately",
+			events: []GameEvent{
+				commanderDamage(1, 2, 0, -15),
+				commanderDamage(1, 2, 1, -15),
+			},
+			target: 2,
+			expectedTotals: map[commanderDamageKey]int{
+				{SourceRankingID: 1, CommanderIndex: 0}: 15,
+				{SourceRankingID: 1, CommanderIndex: 1}: 15,
+			},
+		},
+		{
+			name: "different sources are tracked separately",
+			events: []GameEvent{
+				commanderDamage(1, 3, 0, -20),
+				commanderDamage(2, 3, 0, -20),
+			},
+			target: 3,
+			expectedTotals: map[commanderDamageKey]int{
+				{SourceRankingID: 1}: 20,
+				{SourceRankingID: 2}: 20,
+			},
+		},
+		{
+			name: "positive delta corrects the total",
+			events: []GameEvent{
+				commanderDamage(1, 2, 0, -21),
+				commanderDamage(1, 2, 0, 3),
+			},
+			target:         2,
+			expectedTotals: map[commanderDamageKey]int{{SourceRankingID: 1}: 18},
+		},
+		{
+			name: "voided events are ignored",
+			events: func() []GameEvent {
+				voided := commanderDamage(1, 2, 0, -10)
+				voidedAt := time.Now()
+				voided.VoidedAt = &voidedAt
+				return []GameEvent{commanderDamage(1, 2, 0, -15), voided}
+			}(),
+			target:         2,
+			expectedTotals: map[commanderDamageKey]int{{SourceRankingID: 1}: 15},
+		},
+		{
+			name: "regular damage is not commander damage",
+			events: []GameEvent{
+				{EventType: EventTypeDecrement, SourceRankingID: uintPtr(1), TargetRankingID: uintPtr(2), DamageDelta: -25, TargetLifeTotalAfter: 15},
+			},
+			target:         2,
+			expectedTotals: map[commanderDamageKey]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states := deriveRankingStates(tt.events, Formats[FormatCommander], nil)
+			state, ok := states[tt.target]
+			if !ok {
+				t.Fatalf("expected state for ranking %d", tt.target)
+			}
+
+			if len(state.CommanderDamage) != len(tt.expectedTotals) {
+				t.Errorf("expected %d commander damage totals, got %d", len(tt.expectedTotals), len(state.CommanderDamage))
+			}
+			for key, expected := range tt.expectedTotals {
+				if state.CommanderDamage[key] != expected {
+					t.Errorf("expected total %d for %+v, got %d", expected, key, state.CommanderDamage[key])
+				}
+			}
+			if state.Eliminated != tt.expectedEliminated {
+				t.Errorf("expected eliminated %v, got %v", tt.expectedEliminated, state.Eliminated)
+			}
+		})
+	}
+}
+
+func TestDeriveRankingStatesCounters(t *testing.T) {
+	uintPtr := func(u uint) *uint { return &u }
+
+	counter := func(counterType string, delta int) GameEvent {
+		return GameEvent{
+			EventType:       EventTypeIncrement,
+			TargetRankingID: uintPtr(1),
+			CounterType:     counterType,
+			DamageDelta:     delta,
+		}
+	}
+
+	tests := []struct {
+		name               string
+		events             []GameEvent
+		expectedCounters   map[string]int
+		expectedEliminated bool
+	}{
+		{
+			name:             "counters are tracked per type",
+			events:           []GameEvent{counter(CounterEnergy, 3), counter(CounterExperience, 1), counter(CounterEnergy, -2)},
+			expectedCounters: map[string]int{CounterEnergy: 1, CounterExperience: 1},
+		},
+		{
+			name:               "ten poison eliminates",
+			events:             []GameEvent{counter(CounterPoison, 4), counter(CounterPoison, 6)},
+			expectedCounters:   map[string]int{CounterPoison: 10},
+			expectedEliminated: true,
+		},
+		{
+			name:             "counters never go below zero",
+			events:           []GameEvent{counter("storm", 2), counter("storm", -5)},
+			expectedCounters: map[string]int{"storm": 0},
+		},
+		{
+			name:             "life events don't touch counters",
+			events:           []GameEvent{{EventType: EventTypeDecrement, TargetRankingID: uintPtr(1), DamageDelta: -10, TargetLifeTotalAfter: 30}},
+			expectedCounters: map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := deriveRankingStates(tt.events, Formats[FormatCommander], nil)[1]
+			if len(state.Counters) != len(tt.expectedCounters) {
+				t.Errorf("expected %d counters, got %d", len(tt.expectedCounters), len(state.Counters))
+			}
+			for counterType, expected := range tt.expectedCounters {
+				if state.Counters[counterType] != expected {
+					t.Errorf("expected %s counter %d, got %d", counterType, expected, state.Counters[counterType])
+				}
+			}
+			if state.Eliminated != tt.expectedEliminated {
+				t.Errorf("expected eliminated %v, got %v", tt.expectedEliminated, state.Eliminated)
+			}
+		})
+	}
+}
+
+func TestDeriveRankingStatesElimination(t *testing.T) {
+	uintPtr := func(u uint) *uint { return &u }
+	start := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		format             string
+		lifeSharing        map[uint][]uint
+		events             []GameEvent
+		expectedReason     string
+		expectedBy         *uint
+		expectedAtEventIdx int
+	}{
+		{
+			name: "zero life eliminates by the source",
+			events: []GameEvent{
+				{EventType: EventTypeInit, TargetRankingID: uintPtr(2), TargetLifeTotalAfter: 40},
+				{EventType: EventTypeDecrement, SourceRankingID: uintPtr(1), TargetRankingID: uintPtr(2), DamageDelta: -40, TargetLifeTotalAfter: 0},
+			},
+			expectedReason:     EliminationReasonLife,
+			expectedBy:         uintPtr(1),
+			expectedAtEventIdx: 1,
+		},
+		{
+			name: "scoop is recorded without an eliminating source",
+			events: []GameEvent{
+				{EventType: EventTypeInit, TargetRankingID: uintPtr(2), TargetLifeTotalAfter: 40},
+				{EventType: EventTypeScoop, SourceRankingID: uintPtr(2)},
+			},
+			expectedReason:     EliminationReasonScoop,
+			expectedAtEventIdx: 1,
+		},
+		{
+			name: "first eliminating event is kept",
+			events: []GameEvent{
+				{EventType: EventTypeDecrement, SourceRankingID: uintPtr(1), TargetRankingID: uintPtr(2), DamageDelta: -40, TargetLifeTotalAfter: 0},
+				{EventType: EventTypeDecrement, SourceRankingID: uintPtr(3), TargetRankingID: uintPtr(2), DamageDelta: -5, TargetLifeTotalAfter: -5},
+			},
+			expectedReason:     EliminationReasonLife,
+			expectedBy:         uintPtr(1),
+			expectedAtEventIdx: 0,
+		},
+		{
+			name: "gaining life back undoes the elimination",
+			events: []GameEvent{
+				{EventType: EventTypeDecrement, SourceRankingID: uintPtr(1), TargetRankingID: uintPtr(2), DamageDelta: -40, TargetLifeTotalAfter: 0},
+				{EventType: EventTypeIncrement, SourceRankingID: uintPtr(2), TargetRankingID: uintPtr(2), DamageDelta: 5, TargetLifeTotalAfter: 5},
+			},
+			expectedAtEventIdx: -1,
+		},
+		{
+			name:   "commander damage is not lethal in brawl",
+			format: FormatBrawl,
+			events: []GameEvent{
+				{EventType: EventTypeInit, TargetRankingID: uintPtr(2), TargetLifeTotalAfter: 25},
+				{EventType: EventTypeCommanderDamage, SourceRankingID: uintPtr(1), TargetRankingID: uintPtr(2), DamageDelta: -21, TargetLifeTotalAfter: 4},
+			},
+			expectedAtEventIdx: -1,
+		},
+		{
+			name:   "commander damage is lethal in duel",
+			format: FormatDuel,
+			events: []GameEvent{
+				{EventType: EventTypeInit, TargetRankingID: uintPtr(2), TargetLifeTotalAfter: 20},
+				{EventType: EventTypeIncrement, SourceRankingID: uintPtr(2), TargetRankingID: uintPtr(2), DamageDelta: 10, TargetLifeTotalAfter: 30},
+				{EventType: EventTypeCommanderDamage, SourceRankingID: uintPtr(1), TargetRankingID: uintPtr(2), DamageDelta: -21, TargetLifeTotalAfter: 9},
+			},
+			expectedReason:     EliminationReasonCommanderDamage,
+			expectedBy:         uintPtr(1),
+			expectedAtEventIdx: 2,
+		},
+		{
+			name:        "shared life eliminates the teammate",
+			format:      FormatTwoHeadedGiant,
+			lifeSharing: map[uint][]uint{1: {1, 2}, 2: {1, 2}},
+			events: []GameEvent{
+				{EventType: EventTypeInit, TargetRankingID: uintPtr(1), TargetLifeTotalAfter: 30},
+				{EventType: EventTypeInit, TargetRankingID: uintPtr(2), TargetLifeTotalAfter: 30},
+				{EventType: EventTypeDecrement, SourceRankingID: uintPtr(3), TargetRankingID: uintPtr(1), DamageDelta: -30, TargetLifeTotalAfter: 0},
+			},
+			expectedReason:     EliminationReasonLife,
+			expectedBy:         uintPtr(3),
+			expectedAtEventIdx: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.events {
+				tt.events[i].CreatedAt = start.Add(time.Duration(i) * time.Minute)
+			}
+			rules, ok := GetFormatRules(tt.format)
+			if !ok {
+				t.Fatalf("unknown format %q", tt.format)
+			}
+			state := deriveRankingStates(tt.events, rules, tt.lifeSharing)[2]
+
+			if state.EliminationReason != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, state.EliminationReason)
+			}
+			if state.Eliminated != (tt.expectedReason != "") {
+				t.Errorf("expected eliminated %v, got %v", tt.expectedReason != "", state.Eliminated)
+			}
+			if (tt.expectedBy == nil) != (state.EliminatedByRankingID == nil) ||
+				(tt.expectedBy != nil && *tt.expectedBy != *state.EliminatedByRankingID) {
+				t.Errorf("expected eliminated by %v, got %v", tt.expectedBy, state.EliminatedByRankingID)
+			}
+			if tt.expectedAtEventIdx < 0 {
+				if state.EliminatedAt != nil {
+					t.Errorf("expected no elimination time, got %v", state.EliminatedAt)
+				}
+				return
+			}
+			if state.EliminatedAt == nil || !state.EliminatedAt.Equal(tt.events[tt.expectedAtEventIdx].CreatedAt) {
+				t.Errorf("expected elimination at event %d, got %v", tt.expectedAtEventIdx, state.EliminatedAt)
+			}
+		})
+	}
+}
+
+func TestProposeFinalPositions(t *testing.T) {
+	at := func(minute int) *time.Time {
+		t := time.Date(2025, 1, 1, 20, minute, 0, 0, time.UTC)
+		return &t
+	}
+	team := func(team int) *int { return &team }
+
+	tests := []struct {
+		name              string
+		rankings          []Ranking
+		expectedDecided   bool
+		expectedOrder     []uint
+		expectedPositions []int // Defaults to 1, 2, 3, ...
+	}{
+		{
+			name: "two players still alive",
+			rankings: []Ranking{
+				{Model: gorm.Model{ID: 1}},
+				{Model: gorm.Model{ID: 2}},
+				{Model: gorm.Model{ID: 3}, Eliminated: true, EliminatedAt: at(10)},
+			},
+			expectedDecided: false,
+		},
+		{
+			name: "survivor first, then reverse elimination order",
+			rankings: []Ranking{
+				{Model: gorm.Model{ID: 1}, Eliminated: true, EliminatedAt: at(10)},
+				{Model: gorm.Model{ID: 2}, Eliminated: true, EliminatedAt: at(30)},
+				{Model: gorm.Model{ID: 3}},
+				{Model: gorm.Model{ID: 4}, Eliminated: true, EliminatedAt: at(20)},
+			},
+			expectedDecided: true,
+			expectedOrder:   []uint{3, 2, 4, 1},
+		},
+		{
+			name: "everyone eliminated",
+			rankings: []Ranking{
+				{Model: gorm.Model{ID: 1}, Eliminated: true, EliminatedAt: at(10)},
+				{Model: gorm.Model{ID: 2}, Eliminated: true, EliminatedAt: at(20)},
+			},
+			expectedDecided: true,
+			expectedOrder:   []uint{2, 1},
+		},
+		{
+			name: "team with a surviving teammate is still in the game",
+			rankings: []Ranking{
+				{Model: gorm.Model{ID: 1}, Team: team(1), Eliminated: true, EliminatedAt: at(10)},
+				{Model: gorm.Model{ID: 2}, Team: team(1)},
+				{Model: gorm.Model{ID: 3}, Team: team(2)},
+				{Model: gorm.Model{ID: 4}, Team: team(2), Eliminated: true, EliminatedAt: at(20)},
+			},
+			expectedDecided: false,
+		},
+		{
+			name: "teammates share their position",
+			rankings: []Ranking{
+				{Model: gorm.Model{ID: 1}, Team: team(1), Eliminated: true, EliminatedAt: at(10)},
+				{Model: gorm.Model{ID: 2}, Team: team(2), Eliminated: true, EliminatedAt: at(20)},
+				{Model: gorm.Model{ID: 3}, Team: team(1), Eliminated: true, EliminatedAt: at(30)},
+				{Model: gorm.Model{ID: 4}, Team: team(2)},
+			},
+			expectedDecided:   true,
+			expectedOrder:     []uint{2, 4, 1, 3},
+			expectedPositions: []int{1, 1, 2, 2},
+		},
+		{
+			name:            "single ranking is never decided",
+			rankings:        []Ranking{{Model: gorm.Model{ID: 1}}},
+			expectedDecided: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, decided := proposeFinalPositions(tt.rankings)
+			if decided != tt.expectedDecided {
+				t.Fatalf("expected decided %v, got %v", tt.expectedDecided, decided)
+			}
+			if !decided {
+				return
+			}
+			for i, expectedID := range tt.expectedOrder {
+				if ordered[i].ID != expectedID {
+					t.Errorf("expected ranking %d at index %d, got %d", expectedID, i, ordered[i].ID)
+				}
+				expectedPosition := i + 1
+				if tt.expectedPositions != nil {
+					expectedPosition = tt.expectedPositions[i]
+				}
+				if ordered[i].Position != expectedPosition {
+					t.Errorf("expected position %d at index %d, got %d", expectedPosition, i, ordered[i].Position)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRankingsForFormat(t *testing.T) {
+	uintPtr := func(u uint) *uint { return &u }
+	withDeck := CreateRankingRequest{Deck: &Deck{Commander: "Atraxa, Praetors' Voice"}}
+	withDeckID := CreateRankingRequest{DeckID: uintPtr(1)}
+	withoutDeck := CreateRankingRequest{}
+	onTeam := func(rank CreateRankingRequest, team int) CreateRankingRequest {
+		rank.Team = &team
+		return rank
+	}
+
+	tests := []struct {
+		name         string
+		format       string
+		rankings     []CreateRankingRequest
+		errorMessage string
+	}{
+		{
+			name:     "commander with decks",
+			format:   FormatCommander,
+			rankings: []CreateRankingRequest{withDeck, withDeckID, withDeck},
+		},
+		{
+			name:         "commander requires two rankings",
+			format:       FormatCommander,
+			rankings:     []CreateRankingRequest{withDeck},
+			errorMessage: "commander requires at least 2 rankings",
+		},
+		{
+			name:         "commander requires a deck",
+			format:       FormatCommander,
+			rankings:     []CreateRankingRequest{withDeck, withoutDeck},
+			errorMessage: "Each ranking must have either deck_id or deck provided",
+		},
+		{
+			name:         "commander requires a commander on inline decks",
+			format:       FormatCommander,
+			rankings:     []CreateRankingRequest{withDeck, {Deck: &Deck{}}},
+			errorMessage: "Ranking 1: deck.commander is required",
+		},
+		{
+			name:         "deck_id and deck are exclusive",
+			format:       FormatTwoHeadedGiant,
+			rankings:     []CreateRankingRequest{withoutDeck, withoutDeck, withoutDeck, onTeam(CreateRankingRequest{DeckID: uintPtr(1), Deck: &Deck{Commander: "Kenrith, the Returned King"}}, 2)},
+			errorMessage: "Each ranking must have either deck_id OR deck, not both",
+		},
+		{
+			name:         "duel allows at most two rankings",
+			format:       FormatDuel,
+			rankings:     []CreateRankingRequest{withDeck, withDeck, withDeck},
+			errorMessage: "duel allows at most 2 rankings",
+		},
+		{
+			name:     "two-headed giant without decks",
+			format:   FormatTwoHeadedGiant,
+			rankings: []CreateRankingRequest{onTeam(withoutDeck, 1), onTeam(withoutDeck, 1), onTeam(withoutDeck, 2), onTeam(withoutDeck, 2)},
+		},
+		{
+			name:         "two-headed giant requires four rankings",
+			format:       FormatTwoHeadedGiant,
+			rankings:     []CreateRankingRequest{onTeam(withoutDeck, 1), onTeam(withoutDeck, 2)},
+			errorMessage: "two_headed_giant requires at least 4 rankings",
+		},
+		{
+			name:         "two-headed giant requires teams",
+			format:       FormatTwoHeadedGiant,
+			rankings:     []CreateRankingRequest{onTeam(withoutDeck, 1), onTeam(withoutDeck, 1), onTeam(withoutDeck, 2), withoutDeck},
+			errorMessage: "Ranking 3: team is required in two_headed_giant",
+		},
+		{
+			name:         "two-headed giant requires teams of two",
+			format:       FormatTwoHeadedGiant,
+			rankings:     []CreateRankingRequest{onTeam(withoutDeck, 1), onTeam(withoutDeck, 1), onTeam(withoutDeck, 1), onTeam(withoutDeck, 2)},
+			errorMessage: "team 1 must have 2 rankings",
+		},
+		{
+			name:         "commander is played without teams",
+			format:       FormatCommander,
+			rankings:     []CreateRankingRequest{onTeam(withDeck, 1), onTeam(withDeck, 2)},
+			errorMessage: "commander is played without teams",
+		},
+		{
+			name:     "archenemy against a team of three",
+			format:   FormatArchenemy,
+			rankings: []CreateRankingRequest{onTeam(withDeck, 1), onTeam(withDeck, 2), onTeam(withDeck, 2), onTeam(withDeck, 2)},
+		},
+		{
+			name:         "archenemy requires a single archenemy",
+			format:       FormatArchenemy,
+			rankings:     []CreateRankingRequest{onTeam(withDeck, 1), onTeam(withDeck, 1), onTeam(withDeck, 2), onTeam(withDeck, 2)},
+			errorMessage: "archenemy requires a team with only the archenemy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRankingsForFormat(Formats[tt.format], tt.rankings)
+			if tt.errorMessage == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMessage {
+				t.Errorf("expected error %q, got %v", tt.errorMessage, err)
+			}
+		})
+	}
+}
+
+func TestDeriveTurnState(t *testing.T) {
+	uintPtr := func(u uint) *uint { return &u }
+	start := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+	pass := func(source *uint, target uint, minute int) GameEvent {
+		return GameEvent{
+			Model:           gorm.Model{CreatedAt: start.Add(time.Duration(minute) * time.Minute)},
+			EventType:       EventTypeTurnPass,
+			SourceRankingID: source,
+			TargetRankingID: uintPtr(target),
+		}
+	}
+	rankings := func(startingID uint) []Ranking {
+		result := []Ranking{{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 2}}, {Model: gorm.Model{ID: 3}}}
+		for i := range result {
+			result[i].StartingPlayer = result[i].ID == startingID
+		}
+		return result
+	}
+
+	tests := []struct {
+		name            string
+		rankings        []Ranking
+		events          []GameEvent
+		expectedTurn    int
+		expectedActive  *uint
+		expectedCounts  map[uint]int
+		expectedSeconds map[uint]int
+	}{
+		{
+			name:     "no turns tracked",
+			rankings: rankings(0),
+		},
+		{
+			name:           "starting player starts turn one",
+			rankings:       rankings(2),
+			expectedTurn:   1,
+			expectedActive: uintPtr(2),
+		},
+		{
+			name:     "new round when the starting player is active again",
+			rankings: rankings(1),
+			events: []GameEvent{
+				pass(nil, 2, 2),
+				pass(nil, 3, 5),
+				pass(nil, 1, 6),
+				pass(nil, 2, 10),
+			},
+			expectedTurn:    2,
+			expectedActive:  uintPtr(2),
+			expectedCounts:  map[uint]int{1: 2, 2: 1, 3: 1},
+			expectedSeconds: map[uint]int{1: 360, 2: 180, 3: 60},
+		},
+		{
+			name:     "first pass decides the starting player",
+			rankings: rankings(0),
+			events: []GameEvent{
+				pass(uintPtr(3), 1, 0),
+				pass(nil, 2, 1),
+				pass(nil, 3, 3),
+			},
+			expectedTurn:    2,
+			expectedActive:  uintPtr(3),
+			expectedCounts:  map[uint]int{1: 1, 2: 1},
+			expectedSeconds: map[uint]int{1: 60, 2: 120},
+		},
+		{
+			name:     "voided turn pass is skipped",
+			rankings: rankings(1),
+			events: []GameEvent{
+				pass(nil, 2, 2),
+				func() GameEvent {
+					event := pass(nil, 3, 3)
+					event.VoidedAt = &start
+					return event
+				}(),
+				pass(nil, 3, 4),
+			},
+			expectedTurn:    1,
+			expectedActive:  uintPtr(3),
+			expectedCounts:  map[uint]int{1: 1, 2: 1},
+			expectedSeconds: map[uint]int{1: 120, 2: 120},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := &Game{Model: gorm.Model{CreatedAt: start}, Rankings: tt.rankings, GameEvents: tt.events}
+			state := deriveTurnState(game, start.Add(time.Hour))
+
+			if state.TurnNumber != tt.expectedTurn {
+				t.Errorf("expected turn %d, got %d", tt.expectedTurn, state.TurnNumber)
+			}
+			if (tt.expectedActive == nil) != (state.ActiveRankingID == nil) ||
+				(tt.expectedActive != nil && *tt.expectedActive != *state.ActiveRankingID) {
+				t.Errorf("expected active ranking %v, got %v", tt.expectedActive, state.ActiveRankingID)
+			}
+			for _, ranking := range tt.rankings {
+				if state.TurnCounts[ranking.ID] != tt.expectedCounts[ranking.ID] {
+					t.Errorf("expected %d turns for ranking %d, got %d", tt.expectedCounts[ranking.ID], ranking.ID, state.TurnCounts[ranking.ID])
+				}
+				if state.TurnSeconds[ranking.ID] != tt.expectedSeconds[ranking.ID] {
+					t.Errorf("expected %d seconds for ranking %d, got %d", tt.expectedSeconds[ranking.ID], ranking.ID, state.TurnSeconds[ranking.ID])
+				}
+			}
+		})
+	}
+}
+
+func TestChessClock(t *testing.T) {
+	uintPtr := func(u uint) *uint { return &u }
+	intPtr := func(i int) *int { return &i }
+	start := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+	timePtr := func(at time.Time) *time.Time { return &at }
+	pass := func(target uint, minute int) GameEvent {
+		return GameEvent{
+			Model:           gorm.Model{CreatedAt: start.Add(time.Duration(minute) * time.Minute)},
+			EventType:       EventTypeTurnPass,
+			TargetRankingID: uintPtr(target),
+		}
+	}
+
+	tests := []struct {
+		name              string
+		events            []GameEvent
+		pauses            []GamePause
+		now               int // Minutes after the start
+		expectedRemaining map[uint]int
+		expectedTimedOut  map[uint]int // Minute the clock ran out
+	}{
+		{
+			name:              "clocks start with the initial time",
+			expectedRemaining: map[uint]int{1: 600, 2: 600},
+		},
+		{
+			name:              "turn duration is taken off and the increment added",
+			events:            []GameEvent{pass(2, 4), pass(1, 7)},
+			now:               8,
+			expectedRemaining: map[uint]int{1: 390, 2: 450},
+		},
+		{
+			name:              "clock runs out when it is used up, not when the turn is passed",
+			events:            []GameEvent{pass(2, 11), pass(1, 12)},
+			now:               12,
+			expectedRemaining: map[uint]int{1: 0, 2: 570},
+			expectedTimedOut:  map[uint]int{1: 10},
+		},
+		{
+			name:              "clock of a stalling player runs out without a pass",
+			events:            []GameEvent{pass(2, 4)},
+			now:               15,
+			expectedRemaining: map[uint]int{1: 390, 2: 0},
+			expectedTimedOut:  map[uint]int{2: 14},
+		},
+		{
+			name:              "active clock with time left keeps running",
+			events:            []GameEvent{pass(2, 4)},
+			now:               13,
+			expectedRemaining: map[uint]int{1: 390, 2: 600},
+		},
+		{
+			name:              "paused time doesn't run the clock down",
+			pauses:            []GamePause{{PausedAt: start.Add(2 * time.Minute), ResumedAt: timePtr(start.Add(5 * time.Minute))}},
+			now:               20,
+			expectedRemaining: map[uint]int{1: 0, 2: 600},
+			expectedTimedOut:  map[uint]int{1: 13},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := &Game{
+				Model:                 gorm.Model{CreatedAt: start},
+				Rankings:              []Ranking{{Model: gorm.Model{ID: 1}, StartingPlayer: true}, {Model: gorm.Model{ID: 2}}},
+				GameEvents:            tt.events,
+				Pauses:                tt.pauses,
+				ClockInitialSeconds:   intPtr(600),
+				ClockIncrementSeconds: 30,
+			}
+			turns := deriveTurnState(game, start.Add(time.Duration(tt.now)*time.Minute))
+
+			for rankingID, expected := range tt.expectedRemaining {
+				if turns.ClockRemaining[rankingID] != expected {
+					t.Errorf("expected %d seconds left for ranking %d, got %d", expected, rankingID, turns.ClockRemaining[rankingID])
+				}
+			}
+			if len(turns.TimedOutAt) != len(tt.expectedTimedOut) {
+				t.Fatalf("expected %d timeouts, got %d", len(tt.expectedTimedOut), len(turns.TimedOutAt))
+			}
+
+			states := make(map[uint]*rankingState)
+			applyClockTimeouts(states, turns)
+			for rankingID, minute := range tt.expectedTimedOut {
+				state := states[rankingID]
+				if state == nil || !state.Eliminated || state.EliminationReason != EliminationReasonTimeout {
+					t.Errorf("expected ranking %d to be eliminated by timeout, got %+v", rankingID, state)
+					continue
+				}
+				if expected := start.Add(time.Duration(minute) * time.Minute); !state.EliminatedAt.Equal(expected) {
+					t.Errorf("expected ranking %d to time out at %v, got %v", rankingID, expected, state.EliminatedAt)
+				}
+			}
+		})
+	}
+}
+
+func TestGameDuration(t *testing.T) {
+	start := time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)
+	at := func(minute int) time.Time { return start.Add(time.Duration(minute) * time.Minute) }
+	atPtr := func(minute int) *time.Time {
+		t := at(minute)
+		return &t
+	}
+
+	tests := []struct {
+		name             string
+		date             *time.Time
+		pauses           []GamePause
+		end              time.Time
+		expectedDuration *int
+	}{
+		{
+			name:             "without pauses",
+			end:              at(90),
+			expectedDuration: func() *int { d := 90 * 60; return &d }(),
+		},
+		{
+			name: "paused time is left out",
+			pauses: []GamePause{
+				{PausedAt: at(30), ResumedAt: atPtr(75)},
+				{PausedAt: at(100), ResumedAt: atPtr(105)},
+			},
+			end:              at(120),
+			expectedDuration: func() *int { d := 70 * 60; return &d }(),
+		},
+		{
+			name:             "open pause lasts until the end",
+			pauses:           []GamePause{{PausedAt: at(60)}},
+			end:              at(90),
+			expectedDuration: func() *int { d := 60 * 60; return &d }(),
+		},
+		{
+			name:             "dated shortly before creation",
+			date:             atPtr(-30),
+			end:              at(45),
+			expectedDuration: func() *int { d := 45 * 60; return &d }(),
+		},
+		{
+			name: "backdated game has no automatic duration",
+			date: func() *time.Time { d := start.AddDate(0, 0, -3); return &d }(),
+			end:  at(1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := &Game{Model: gorm.Model{CreatedAt: start}, Date: tt.date, Pauses: tt.pauses}
+			duration := gameDuration(game, tt.end)
+
+			if (tt.expectedDuration == nil) != (duration == nil) {
+				t.Fatalf("expected duration %v, got %v", tt.expectedDuration, duration)
+			}
+			if duration != nil && *duration != *tt.expectedDuration {
+				t.Errorf("expected duration %d, got %d", *tt.expectedDuration, *duration)
+			}
+		})
+	}
+}
+
+func TestValidateJoiningRanking(t *testing.T) {
+	team := func(team int) *int { return &team }
+	deckID := func(id uint) *uint { return &id }
+	rankings := func(teams ...*int) []Ranking {
+		result := make([]Ranking, len(teams))
+		for i, team := range teams {
+			result[i] = Ranking{Model: gorm.Model{ID: uint(i + 1)}, Team: team}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name         string
+		game         *Game
+		request      JoinGameRequest
+		expectError  bool
+		errorMessage string
+	}{
+		{
+			name:    "join a commander game with a deck",
+			game:    &Game{Format: FormatCommander, Rankings: rankings(nil, nil, nil)},
+			request: JoinGameRequest{DeckID: deckID(1)},
+		},
+		{
+			name:         "commander requires a deck",
+			game:         &Game{Format: FormatCommander, Rankings: rankings(nil, nil, nil)},
+			request:      JoinGameRequest{},
+			expectError:  true,
+			errorMessage: "Each ranking must have either deck_id or deck provided",
+		},
+		{
+			name:         "duel is full",
+			game:         &Game{Format: FormatDuel, Rankings: rankings(nil, nil)},
+			request:      JoinGameRequest{DeckID: deckID(1)},
+			expectError:  true,
+			errorMessage: "game is full",
+		},
+		{
+			name:         "no teams in commander",
+			game:         &Game{Format: FormatCommander, Rankings: rankings(nil, nil)},
+			request:      JoinGameRequest{DeckID: deckID(1), Team: team(1)},
+			expectError:  true,
+			errorMessage: "commander is played without teams",
+		},
+		{
+			name:    "join a team with a free spot",
+			game:    &Game{Format: FormatTwoVsTwo, Rankings: rankings(team(1), team(1), team(2))},
+			request: JoinGameRequest{DeckID: deckID(1), Team: team(2)},
+		},
+		{
+			name:         "team is required in team formats",
+			game:         &Game{Format: FormatTwoVsTwo, Rankings: rankings(team(1), team(1), team(2))},
+			request:      JoinGameRequest{DeckID: deckID(1)},
+			expectError:  true,
+			errorMessage: "team is required in two_vs_two",
+		},
+		{
+			name:         "team is full",
+			game:         &Game{Format: FormatTwoVsTwo, Rankings: rankings(team(1), team(1), team(2))},
+			request:      JoinGameRequest{DeckID: deckID(1), Team: team(1)},
+			expectError:  true,
+			errorMessage: "team 1 is full",
+		},
+		{
+			name:         "no additional teams",
+			game:         &Game{Format: FormatTwoVsTwo, Rankings: rankings(team(1), team(1), team(2))},
+			request:      JoinGameRequest{DeckID: deckID(1), Team: team(3)},
+			expectError:  true,
+			errorMessage: "two_vs_two requires 2 teams",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJoiningRanking(tt.game, tt.request)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error containing %q, got nil", tt.errorMessage)
+				}
+				if err.Error() != tt.errorMessage {
+					t.Errorf("expected error %q, got %q", tt.errorMessage, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestJoinCode(t *testing.T) {
+	code, err := generateJoinCode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(code) != joinCodeLength {
+		t.Errorf("expected a code of %d characters, got %q", joinCodeLength, code)
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(joinCodeAlphabet, c) {
+			t.Errorf("unexpected character %q in code %q", c, code)
+		}
+	}
+	if normalized := normalizeJoinCode(" " + strings.ToLower(code) + "\n"); normalized != code {
+		t.Errorf("expected %q after normalizing, got %q", code, normalized)
+	}
+}
+
+func TestGamePolicy(t *testing.T) {
+	creatorID := "creator"
+	game := &Game{
+		CreatorID: &creatorID,
+		Rankings: []Ranking{
+			{PlayerID: func() *string { s := "participant"; return &s }()},
+			{}, // Guest
+		},
+	}
+
+	// Expected outcome per route for the creator who doesn't play, a participant, an admin, a stranger
+	// and the participant playing the ranking the route is about
+	type outcome struct {
+		creator, participant, admin, stranger, rankingPlayer bool
+	}
+	edit := outcome{creator: true, participant: true, rankingPlayer: true}
+	postEvents := outcome{participant: true, rankingPlayer: true}
+	deleteGame := outcome{creator: true, admin: true}
+	voteResult := outcome{participant: true, rankingPlayer: true}
+	leave := outcome{creator: true, admin: true, rankingPlayer: true}
+
+	tests := []struct {
+		route    string
+		expected outcome
+	}{
+		{route: "PUT /game/v1/games/{gameId}", expected: edit},
+		{route: "DELETE /game/v1/games/{gameId}", expected: deleteGame},
+		{route: "POST /game/v1/games/{gameId}/restore", expected: deleteGame},
+		{route: "POST /game/v1/games/{gameId}/events", expected: postEvents},
+		{route: "DELETE /game/v1/games/{gameId}/events/{eventId}", expected: postEvents},
+		{route: "POST /game/v1/games/{gameId}/events/{eventId}/redo", expected: postEvents},
+		{route: "POST /game/v1/games/{gameId}/pause", expected: edit},
+		{route: "POST /game/v1/games/{gameId}/resume", expected: edit},
+		{route: "POST /game/v1/games/{gameId}/result/confirm", expected: voteResult},
+		{route: "POST /game/v1/games/{gameId}/result/dispute", expected: voteResult},
+		{route: "POST /game/v1/games/{gameId}/merge", expected: edit},
+		{route: "POST /game/v1/games/{gameId}/discard", expected: deleteGame},
+		{route: "PUT /ranking/v1/rankings/{rankingId}", expected: edit},
+		{route: "DELETE /ranking/v1/rankings/{rankingId}", expected: leave},
+		{route: "POST /ranking/v1/rankings/{rankingId}/claims", expected: edit},
+	}
+
+	if len(tests) != len(routeActions) {
+		t.Fatalf("expected a test for each of the %d routes with a policy, got %d", len(routeActions), len(tests))
+	}
+
+	// The policy is looked up by the pattern the request was routed with
+	mux := http.NewServeMux()
+	(&Service{}).RegisterRoutes(mux)
+
+	rankingPlayer := roleInGame(game, "participant", false)
+	rankingPlayer.RankingPlayer = true
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			action, ok := routeActions[tt.route]
+			if !ok {
+				t.Fatalf("route %q has no policy", tt.route)
+			}
+
+			method, path, _ := strings.Cut(tt.route, " ")
+			path = strings.NewReplacer("{gameId}", "1", "{eventId}", "2", "{rankingId}", "3").Replace(path)
+			if _, pattern := mux.Handler(httptest.NewRequest(method, path, nil)); pattern != tt.route {
+				t.Errorf("expected %s %s to be routed to %q, got %q", method, path, tt.route, pattern)
+			}
+
+			roles := []struct {
+				name     string
+				role     gameRole
+				expected bool
+			}{
+				{name: "creator", role: roleInGame(game, creatorID, false), expected: tt.expected.creator},
+				{name: "participant", role: roleInGame(game, "participant", false), expected: tt.expected.participant},
+				{name: "admin", role: roleInGame(game, "admin", true), expected: tt.expected.admin},
+				{name: "stranger", role: roleInGame(game, "stranger", false), expected: tt.expected.stranger},
+				{name: "ranking player", role: rankingPlayer, expected: tt.expected.rankingPlayer},
+			}
+			for _, r := range roles {
+				if allowed := canPerform(action, r.role); allowed != r.expected {
+					t.Errorf("expected %s to be allowed: %v, got %v", r.name, r.expected, allowed)
+				}
+			}
+		})
+	}
+}
+
+func TestDecideResult(t *testing.T) {
+	now := time.Date(2025, 1, 3, 18, 0, 0, 0, time.UTC)
+	finishedAgo := func(d time.Duration) *time.Time { end := now.Add(-d); return &end }
+	player := func(id string) Ranking { return Ranking{PlayerID: &id} }
+	// Three registered players and a guest, two confirmations are a majority
+	rankings := []Ranking{player("a"), player("b"), player("c"), {}}
+
+	tests := []struct {
+		name     string
+		game     Game
+		expected string
+	}{
+		{
+			name:     "unfinished game has no result",
+			game:     Game{Rankings: rankings},
+			expected: "",
+		},
+		{
+			name:     "waiting for confirmations",
+			game:     Game{Finished: true, ResultStatus: ResultStatusPending, EndDate: finishedAgo(time.Hour), Rankings: rankings, ResultVotes: []GameResultVote{{PlayerID: "a", Confirmed: true}}},
+			expected: ResultStatusPending,
+		},
+		{
+			name:     "majority confirms",
+			game:     Game{Finished: true, ResultStatus: ResultStatusPending, EndDate: finishedAgo(time.Hour), Rankings: rankings, ResultVotes: []GameResultVote{{PlayerID: "a", Confirmed: true}, {PlayerID: "b", Confirmed: true}}},
+			expected: ResultStatusConfirmed,
+		},
+		{
+			name:     "single dispute disputes",
+			game:     Game{Finished: true, ResultStatus: ResultStatusPending, EndDate: finishedAgo(time.Hour), Rankings: rankings, ResultVotes: []GameResultVote{{PlayerID: "a", Confirmed: true}, {PlayerID: "b", Confirmed: true}, {PlayerID: "c"}}},
+			expected: ResultStatusDisputed,
+		},
+		{
+			name:     "timeout confirms",
+			game:     Game{Finished: true, ResultStatus: ResultStatusPending, EndDate: finishedAgo(resultConfirmationTimeout), Rankings: rankings},
+			expected: ResultStatusConfirmed,
+		},
+		{
+			name:     "timeout doesn't confirm a disputed result",
+			game:     Game{Finished: true, ResultStatus: ResultStatusDisputed, EndDate: finishedAgo(resultConfirmationTimeout), Rankings: rankings},
+			expected: ResultStatusDisputed,
+		},
+		{
+			name:     "single registered player confirms alone",
+			game:     Game{Finished: true, ResultStatus: ResultStatusPending, EndDate: finishedAgo(time.Minute), Rankings: []Ranking{player("a"), {}}, ResultVotes: []GameResultVote{{PlayerID: "a", Confirmed: true}}},
+			expected: ResultStatusConfirmed,
+		},
+		{
+			name:     "confirmed result stays confirmed",
+			game:     Game{Finished: true, ResultStatus: ResultStatusConfirmed, Rankings: rankings, ResultVotes: []GameResultVote{{PlayerID: "a"}}},
+			expected: ResultStatusConfirmed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := decideResult(&tt.game, now); status != tt.expected {
+				t.Errorf("expected result %q, got %q", tt.expected, status)
+			}
+		})
+	}
+}
+
+func TestIsDuplicateCandidate(t *testing.T) {
+	start := time.Date(2025, 1, 3, 18, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time { date := start.Add(d); return &date }
+	seconds := func(d time.Duration) *int { s := int(d.Seconds()); return &s }
+	player := func(id, commander string) Ranking {
+		return Ranking{PlayerID: &id, DeckEmbedded: SimpleDeck{Commander: commander}}
+	}
+	guest := func(commander string) Ranking { return Ranking{DeckEmbedded: SimpleDeck{Commander: commander}} }
+	rankings := []Ranking{player("a", "Atraxa"), player("b", "Krenko"), guest("Edgar Markov")}
+	game := Game{Model: gorm.Model{ID: 1}, Date: at(0), Duration: seconds(time.Hour), Rankings: rankings}
+
+	tests := []struct {
+		name     string
+		other    Game
+		expected bool
+	}{
+		{
+			name:     "same game logged twice",
+			other:    Game{Model: gorm.Model{ID: 2}, Date: at(5 * time.Minute), Rankings: []Ranking{player("b", "krenko"), guest("Edgar Markov"), player("a", "Atraxa")}},
+			expected: true,
+		},
+		{
+			name:     "game is not its own duplicate",
+			other:    game,
+			expected: false,
+		},
+		{
+			name:     "different players",
+			other:    Game{Model: gorm.Model{ID: 2}, Date: at(0), Rankings: []Ranking{player("a", "Atraxa"), player("c", "Krenko"), guest("Edgar Markov")}},
+			expected: false,
+		},
+		{
+			name:     "different commanders",
+			other:    Game{Model: gorm.Model{ID: 2}, Date: at(0), Rankings: []Ranking{player("a", "Atraxa"), player("b", "Krenko"), guest("Yuriko")}},
+			expected: false,
+		},
+		{
+			name:     "rematch after the game ended",
+			other:    Game{Model: gorm.Model{ID: 2}, Date: at(time.Hour + duplicateTolerance + time.Minute), Rankings: rankings},
+			expected: false,
+		},
+		{
+			name:     "clocks disagree within the tolerance",
+			other:    Game{Model: gorm.Model{ID: 2}, Date: at(time.Hour + duplicateTolerance/2), Rankings: rankings},
+			expected: true,
+		},
+		{
+			name:     "only guests",
+			other:    Game{Model: gorm.Model{ID: 2}, Date: at(0), Rankings: []Ranking{guest("Atraxa")}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if duplicate := isDuplicateCandidate(&game, &tt.other); duplicate != tt.expected {
+				t.Errorf("expected duplicate %v, got %v", tt.expected, duplicate)
+			}
+		})
+	}
+}
+
+func TestMatchRankings(t *testing.T) {
+	ranking := func(id uint, playerID, commander string) Ranking {
+		r := Ranking{Model: gorm.Model{ID: id}, DeckEmbedded: SimpleDeck{Commander: commander}}
+		if playerID != "" {
+			r.PlayerID = &playerID
+		}
+		return r
+	}
+	survivor := []Ranking{ranking(1, "a", "Atraxa"), ranking(2, "", "Krenko"), ranking(3, "", "Yuriko")}
+	duplicate := []Ranking{ranking(11, "", "yuriko"), ranking(12, "a", "Atraxa"), ranking(13, "", "Krenko"), ranking(14, "", "Krenko")}
+
+	matches := matchRankings(duplicate, survivor)
+	expected := map[uint]uint{11: 3, 12: 1, 13: 2}
+	if len(matches) != len(expected) {
+		t.Fatalf("expected %d matches, got %v", len(expected), matches)
+	}
+	for from, to := range expected {
+		if matches[from] != to {
+			t.Errorf("expected ranking %d to match %d, got %d", from, to, matches[from])
+		}
+	}
+}
+
+func TestGameAuditEntries(t *testing.T) {
+	player := func(id uint, playerID string, position int) Ranking {
+		return Ranking{Model: gorm.Model{ID: id}, GameID: 1, PlayerID: &playerID, Position: position}
+	}
+	game := func(finished bool, rankings ...Ranking) *Game {
+		return &Game{Model: gorm.Model{ID: 1}, Format: FormatCommander, Finished: finished, Rankings: rankings}
+	}
+
+	tests := []struct {
+		name     string
+		before   *Game
+		after    *Game
+		expected []string // entity:id:action, changed fields
+	}{
+		{
+			name:     "created game records the game and its rankings",
+			after:    game(false, player(1, "a", 0), player(2, "b", 0)),
+			expected: []string{"game:1:created", "ranking:1:created", "ranking:2:created"},
+		},
+		{
+			name:     "nothing changed",
+			before:   game(false, player(1, "a", 0), player(2, "b", 0)),
+			after:    game(false, player(1, "a", 0), player(2, "b", 0)),
+			expected: nil,
+		},
+		{
+			name:     "reordered positions",
+			before:   game(true, player(1, "a", 1), player(2, "b", 2)),
+			after:    game(true, player(1, "a", 2), player(2, "b", 1)),
+			expected: []string{"ranking:1:updated position", "ranking:2:updated position"},
+		},
+		{
+			name:     "finished game and joined ranking",
+			before:   game(false, player(1, "a", 0)),
+			after:    game(true, player(1, "a", 1), player(2, "b", 2)),
+			expected: []string{"game:1:updated Finished", "ranking:1:updated position", "ranking:2:created"},
+		},
+		{
+			name:     "deleted game records the game and its rankings",
+			before:   game(true, player(1, "a", 1)),
+			expected: []string{"game:1:deleted", "ranking:1:deleted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := gameAuditEntries("a", tt.before, tt.after)
+			var got []string
+			for _, entry := range entries {
+				description := fmt.Sprintf("%s:%d:%s", entry.EntityType, entry.EntityID, entry.Action)
+				if entry.Action == AuditActionUpdated {
+					fields := make([]string, 0, len(entry.Changes))
+					for field := range entry.Changes {
+						fields = append(fields, field)
+					}
+					sort.Strings(fields)
+					description += " " + strings.Join(fields, ",")
+				}
+				got = append(got, description)
+				if entry.ActorID == nil || *entry.ActorID != "a" || entry.GameID == nil || *entry.GameID != 1 {
+					t.Errorf("expected actor a and game 1 on %s", description)
+				}
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.expected, "; ") {
+				t.Errorf("expected entries %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGameEventAuditEntries(t *testing.T) {
+	event := func(id, gameID uint, lifeAfter int) GameEvent {
+		return GameEvent{Model: gorm.Model{ID: id}, GameID: gameID, EventType: EventTypeDecrement, DamageDelta: -5, TargetLifeTotalAfter: lifeAfter}
+	}
+	voided := event(2, 1, 30)
+	now := time.Now()
+	voided.VoidedAt = &now
+
+	before := []GameEvent{event(1, 1, 35), event(2, 1, 30), event(3, 1, 25), event(4, 2, 35)}
+	after := []GameEvent{event(1, 1, 35), voided, event(3, 1, 30), event(4, 1, 25), event(5, 1, 20)}
+
+	var got []string
+	for _, entry := range gameEventAuditEntries("a", before, after) {
+		fields := make([]string, 0, len(entry.Changes))
+		for field := range entry.Changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		description := fmt.Sprintf("%d:%s:%d", entry.EntityID, entry.Action, *entry.GameID)
+		if entry.Action == AuditActionUpdated {
+			description += " " + strings.Join(fields, ",")
+		}
+		got = append(got, description)
+	}
+	expected := []string{
+		"2:updated:1 VoidedAt",
+		"3:updated:1 TargetLifeTotalAfter",
+		"4:updated:1 GameID,TargetLifeTotalAfter",
+		"5:created:1",
+	}
+	if strings.Join(got, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("expected entries %v, got %v", expected, got)
+	}
+}
+
+func TestDeckUpdateVersions(t *testing.T) {
+	str := func(s string) *string { return &s }
+	bracket := func(b uint) *uint { return &b }
+	deck := func() *Deck {
+		return &Deck{Model: gorm.Model{ID: 7}, Commander: "Atraxa", Themes: []string{"counters"}, Bracket: bracket(3), Version: 2}
+	}
+
+	tests := []struct {
+		name       string
+		request    UpdateDeckRequest
+		newVersion bool
+	}{
+		{name: "empty update", request: UpdateDeckRequest{}, newVersion: false},
+		{name: "same commander", request: UpdateDeckRequest{Commander: str("Atraxa")}, newVersion: false},
+		{name: "same bracket", request: UpdateDeckRequest{Bracket: bracket(3)}, newVersion: false},
+		{name: "swapped commander", request: UpdateDeckRequest{Commander: str("Krenko")}, newVersion: true},
+		{name: "re-rated bracket", request: UpdateDeckRequest{Bracket: bracket(4)}, newVersion: true},
+		{name: "changed themes", request: UpdateDeckRequest{Themes: &[]string{"counters", "superfriends"}}, newVersion: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := deck()
+			before := snapshotDeck(d)
+			applyDeckUpdate(d, tt.request)
+			if changed := !sameDeckVersion(before, snapshotDeck(d)); changed != tt.newVersion {
+				t.Errorf("expected new version %v, got %v", tt.newVersion, changed)
+			}
+		})
+	}
+}
+
+func TestConvertDeckFromRankingUsesPlayedVersion(t *testing.T) {
+	deckID := uint(7)
+	ranking := Ranking{
+		DeckID:      &deckID,
+		Deck:        &Deck{Model: gorm.Model{ID: deckID}, Commander: "Krenko", Version: 2},
+		DeckVersion: &DeckVersion{DeckID: deckID, Version: 1, Commander: "Atraxa"},
+	}
+
+	deck := convertDeckFromRanking(&ranking)
+	if deck.Commander != "Atraxa" || deck.Version != 1 || deck.ID == nil || *deck.ID != deckID {
+		t.Errorf("expected version 1 of deck %d with Atraxa, got %+v", deckID, deck)
+	}
+
+	ranking.DeckVersion = nil
+	if deck := convertDeckFromRanking(&ranking); deck.Commander != "Krenko" || deck.Version != 2 {
+		t.Errorf("expected the current deck without a played version, got %+v", deck)
+	}
+}
+
+func TestComputeDeckStats(t *testing.T) {
+	deckID, otherDeckID := uint(1), uint(2)
+	team1, team2 := 1, 2
+	duration := 3600
+	alice, bob, carol := "alice", "bob", "carol"
+	players := map[string]*Player{
+		alice: {FirebaseID: alice, Name: "Alice"},
+		bob:   {FirebaseID: bob, Name: "Bob"},
+		carol: {FirebaseID: carol, Name: "Carol"},
+	}
+	ranking := func(playerID string, position int, deck *uint, commander string, team *int) Ranking {
+		r := Ranking{PlayerID: &playerID, Player: players[playerID], Position: position, DeckID: deck, Team: team}
+		r.DeckEmbedded.Commander = commander
+		return r
+	}
+
+	games := []Game{
+		{Duration: &duration, Rankings: []Ranking{
+			ranking(alice, 1, &deckID, "Krenko", nil),
+			ranking(bob, 2, &otherDeckID, "Atraxa", nil),
+			ranking(carol, 3, nil, "Edgar", nil),
+		}},
+		{Rankings: []Ranking{
+			ranking(alice, 2, &deckID, "Krenko", nil),
+			ranking(bob, 1, &otherDeckID, "Atraxa", nil),
+		}},
+		// Teammates are neither beaten nor lost to
+		{Format: FormatTwoVsTwo, Rankings: []Ranking{
+			ranking(alice, 1, &deckID, "Krenko", &team1),
+			ranking(carol, 1, nil, "Edgar", &team1),
+			ranking(bob, 2, &otherDeckID, "Atraxa", &team2),
+		}},
+		// A game without the deck is skipped
+		{Rankings: []Ranking{ranking(bob, 1, &otherDeckID, "Atraxa", nil)}},
+	}
+	games[1].Rankings[1].DeckVersion = &DeckVersion{Commander: "Atraxa"}
+
+	stats := computeDeckStats(deckID, games)
+	if stats.GameCount != 3 || stats.WinCount != 2 {
+		t.Fatalf("expected 2 wins in 3 games, got %d in %d", stats.WinCount, stats.GameCount)
+	}
+	if stats.AveragePosition != 4.0/3 {
+		t.Errorf("expected an average position of 4/3, got %v", stats.AveragePosition)
+	}
+	if stats.AverageGameDuration == nil || *stats.AverageGameDuration != duration {
+		t.Errorf("expected an average duration of %d, got %v", duration, stats.AverageGameDuration)
+	}
+
+	winrates := []float64{1, 0.5, 2.0 / 3}
+	if len(stats.WinrateHistory) != len(winrates) {
+		t.Fatalf("expected %d winrate points, got %d", len(winrates), len(stats.WinrateHistory))
+	}
+	for i, winrate := range winrates {
+		if point := stats.WinrateHistory[i]; point.Winrate != winrate || point.GameCount != i+1 {
+			t.Errorf("point %d: expected winrate %v after %d games, got %+v", i, winrate, i+1, point)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		matchups []DeckMatchupResult
+		expected []string
+	}{
+		{"beaten commanders", stats.MostBeatenCommanders, []string{"Atraxa", "Edgar"}},
+		{"lost to commanders", stats.MostLostToCommanders, []string{"Atraxa"}},
+		{"beaten players", stats.MostBeatenPlayers, []string{"Bob", "Carol"}},
+		{"lost to players", stats.MostLostToPlayers, []string{"Bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, matchup := range tt.matchups {
+				if matchup.Player != nil {
+					names = append(names, matchup.Player.Name)
+				} else {
+					names = append(names, matchup.Commander)
+				}
+			}
+			if !equalStrings(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestComputeDeckStatsPairings(t *testing.T) {
+	deckID := uint(1)
+	ranking := func(position int, commander, partner string) Ranking {
+		return Ranking{Position: position, DeckEmbedded: SimpleDeck{Commander: commander, Partner: partner}}
+	}
+	own := ranking(1, "Krenko", "")
+	own.DeckID = &deckID
+	// The same pairing in either order counts as one
+	games := []Game{
+		{Rankings: []Ranking{own, ranking(2, "Tymna the Weaver", "Thrasios, Triton Hero")}},
+		{Rankings: []Ranking{own, ranking(2, "Thrasios, Triton Hero", "Tymna the Weaver"), ranking(3, "Thrasios, Triton Hero", "")}},
+	}
+
+	stats := computeDeckStats(deckID, games)
+	wins := make(map[string]int)
+	for _, matchup := range stats.MostBeatenCommanders {
+		name := matchup.Commander
+		if matchup.Partner != "" {
+			name += " + " + matchup.Partner
+		}
+		wins[name] = matchup.Wins
+	}
+
+	expected := map[string]int{
+		"Tymna the Weaver + Thrasios, Triton Hero": 2,
+		"Thrasios, Triton Hero":                    3,
+		"Tymna the Weaver":                         2,
+	}
+	if len(wins) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, wins)
+	}
+	for name, count := range expected {
+		if wins[name] != count {
+			t.Errorf("%s: expected %d wins, got %d", name, count, wins[name])
+		}
+	}
+}
+
+type fakeCardDatabase map[string]*cards.Card
+
+func (db fakeCardDatabase) Get(name string) (*cards.Card, bool) {
+	card, ok := db[name]
+	return card, ok
+}
+
+func TestCompleteDeckRequest(t *testing.T) {
+	tymna := &cards.Card{OracleID: "o-tymna", Name: "Tymna the Weaver", ColorIdentity: []string{"W", "B"}, Image: "tymna.jpg", Crop: "tymna-crop.jpg"}
+	thrasios := &cards.Card{OracleID: "o-thrasios", Name: "Thrasios, Triton Hero", ColorIdentity: []string{"G", "U"}, Image: "thrasios.jpg", Crop: "thrasios-crop.jpg"}
+	svc := &Service{Cards: fakeCardDatabase{
+		"Tymna the Weaver":      tymna,
+		"tymna":                 tymna,
+		"Thrasios, Triton Hero": thrasios,
+	}}
+	tymnaID, thrasiosID := "o-tymna", "o-thrasios"
+
+	tests := []struct {
+		name       string
+		request    CreateDeckRequest
+		expected   CreateDeckRequest
+		commanders []string
+	}{
+		{
+			name:    "pairing",
+			request: CreateDeckRequest{Commander: "Tymna the Weaver", Partner: "Thrasios, Triton Hero"},
+			expected: CreateDeckRequest{
+				Commander: "Tymna the Weaver", Partner: "Thrasios, Triton Hero", Colors: []string{"W", "U", "B", "G"},
+				Image: "tymna.jpg", Crop: "tymna-crop.jpg", PartnerImage: "thrasios.jpg", PartnerCrop: "thrasios-crop.jpg",
+				CommanderOracleID: &tymnaID, PartnerOracleID: &thrasiosID,
+			},
+			commanders: []string{"o-tymna", "o-thrasios"},
+		},
+		{
+			name:    "canonical name",
+			request: CreateDeckRequest{Commander: "tymna"},
+			expected: CreateDeckRequest{
+				Commander: "Tymna the Weaver", Image: "tymna.jpg", Crop: "tymna-crop.jpg", Colors: []string{"W", "B"}, CommanderOracleID: &tymnaID,
+			},
+			commanders: []string{"o-tymna"},
+		},
+		{
+			name:    "given images and colors are kept",
+			request: CreateDeckRequest{Commander: "Tymna the Weaver", Image: "own.jpg", Colors: []string{"W"}},
+			expected: CreateDeckRequest{
+				Commander: "Tymna the Weaver", Image: "own.jpg", Crop: "tymna-crop.jpg", Colors: []string{"W"}, CommanderOracleID: &tymnaID,
+			},
+			commanders: []string{"o-tymna"},
+		},
+		{
+			name:    "unknown partner leaves the colors",
+			request: CreateDeckRequest{Commander: "Tymna the Weaver", Partner: "Homebrew"},
+			expected: CreateDeckRequest{
+				Commander: "Tymna the Weaver", Partner: "Homebrew", Image: "tymna.jpg", Crop: "tymna-crop.jpg", CommanderOracleID: &tymnaID,
+			},
+			commanders: []string{"o-tymna"},
+		},
+		{
+			name:     "unknown commander",
+			request:  CreateDeckRequest{Commander: "Homebrew"},
+			expected: CreateDeckRequest{Commander: "Homebrew"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+			commanders := svc.completeDeckRequest(&request)
+			if !reflect.DeepEqual(request, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, request)
+			}
+			var ids []string
+			for _, commander := range commanders {
+				ids = append(ids, commander.OracleID)
+			}
+			if !equalStrings(ids, tt.commanders) {
+				t.Errorf("expected commanders %v, got %v", tt.commanders, ids)
+			}
+		})
+	}
+}
+
+func TestCompleteDeckUpdate(t *testing.T) {
+	tymna := &cards.Card{OracleID: "o-tymna", Name: "Tymna the Weaver", ColorIdentity: []string{"W", "B"}, Image: "tymna.jpg", Crop: "tymna-crop.jpg"}
+	thrasios := &cards.Card{OracleID: "o-thrasios", Name: "Thrasios, Triton Hero", ColorIdentity: []string{"G", "U"}, Image: "thrasios.jpg", Crop: "thrasios-crop.jpg"}
+	atraxa := &cards.Card{OracleID: "o-atraxa", Name: "Atraxa, Praetors' Voice", ColorIdentity: []string{"W", "U", "B", "G"}, Image: "atraxa.jpg", SecondaryImage: "atraxa-back.jpg", Crop: "atraxa-crop.jpg"}
+	svc := &Service{Cards: fakeCardDatabase{
+		"atraxa":                  atraxa,
+		"Atraxa, Praetors' Voice": atraxa,
+		"Tymna the Weaver":        tymna,
+		"Thrasios, Triton Hero":   thrasios,
+	}}
+	oldID, tymnaID := "o-old", "o-tymna"
+	text := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		deck       Deck
+		request    UpdateDeckRequest
+		expected   Deck
+		commanders []string
+	}{
+		{
+			name: "new commander replaces the pairing",
+			deck: Deck{
+				Commander: "Old", CommanderOracleID: &oldID, Image: "old.jpg", Colors: []string{"R"},
+				Partner: "Partner", PartnerOracleID: &oldID, PartnerImage: "partner.jpg", PartnerCrop: "partner-crop.jpg",
+			},
+			request: UpdateDeckRequest{Commander: text("atraxa"), Partner: text("")},
+			expected: Deck{
+				Commander: "Atraxa, Praetors' Voice", CommanderOracleID: &atraxa.OracleID, Colors: []string{"W", "U", "B", "G"},
+				Image: "atraxa.jpg", SecondaryImage: "atraxa-back.jpg", Crop: "atraxa-crop.jpg",
+			},
+			commanders: []string{"o-atraxa"},
+		},
+		{
+			name:    "new partner joins the commander",
+			deck:    Deck{Commander: "Tymna the Weaver", CommanderOracleID: &tymnaID, Image: "tymna.jpg", Colors: []string{"W", "B"}},
+			request: UpdateDeckRequest{Partner: text("Thrasios, Triton Hero")},
+			expected: Deck{
+				Commander: "Tymna the Weaver", CommanderOracleID: &tymnaID, Image: "tymna.jpg", Colors: []string{"W", "U", "B", "G"},
+				Partner: "Thrasios, Triton Hero", PartnerOracleID: &thrasios.OracleID, PartnerImage: "thrasios.jpg", PartnerCrop: "thrasios-crop.jpg",
+			},
+			commanders: []string{"o-thrasios"},
+		},
+		{
+			name:    "own images and colors are kept",
+			deck:    Deck{Commander: "Old", Image: "old.jpg"},
+			request: UpdateDeckRequest{Commander: text("atraxa"), Image: text("own.jpg"), Colors: &[]string{"U"}},
+			expected: Deck{
+				Commander: "Atraxa, Praetors' Voice", CommanderOracleID: &atraxa.OracleID, Colors: []string{"U"}, Image: "own.jpg", Crop: "atraxa-crop.jpg",
+			},
+			commanders: []string{"o-atraxa"},
+		},
+		{
+			name:     "unknown partner leaves the colors",
+			deck:     Deck{Commander: "Tymna the Weaver", CommanderOracleID: &tymnaID, Colors: []string{"W", "B"}},
+			request:  UpdateDeckRequest{Partner: text("Homebrew")},
+			expected: Deck{Commander: "Tymna the Weaver", CommanderOracleID: &tymnaID, Colors: []string{"W", "B"}, Partner: "Homebrew"},
+		},
+		{
+			name:       "unchanged commander keeps the images",
+			deck:       Deck{Commander: "Tymna the Weaver", CommanderOracleID: &tymnaID, Image: "own.jpg", Colors: []string{"W"}},
+			request:    UpdateDeckRequest{Commander: text("Tymna the Weaver")},
+			expected:   Deck{Commander: "Tymna the Weaver", CommanderOracleID: &tymnaID, Image: "own.jpg", Colors: []string{"W"}},
+			commanders: []string{"o-tymna"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deck := tt.deck
+			request := tt.request
+			commanders := svc.completeDeckUpdate(&deck, &request)
+			applyDeckUpdate(&deck, request)
+			if !reflect.DeepEqual(deck, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, deck)
+			}
+			var ids []string
+			for _, commander := range commanders {
+				ids = append(ids, commander.OracleID)
+			}
+			if !equalStrings(ids, tt.commanders) {
+				t.Errorf("expected commanders %v, got %v", tt.commanders, ids)
+			}
+		})
+	}
+}
+
+func TestCompleteSimpleDeck(t *testing.T) {
+	tymna := &cards.Card{OracleID: "o-tymna", Name: "Tymna the Weaver", Image: "tymna.jpg", SecondaryImage: "tymna-back.jpg", Crop: "tymna-crop.jpg"}
+	thrasios := &cards.Card{OracleID: "o-thrasios", Name: "Thrasios, Triton Hero", Image: "thrasios.jpg", Crop: "thrasios-crop.jpg"}
+	svc := &Service{Cards: fakeCardDatabase{"tymna": tymna, "thrasios": thrasios}}
+
+	deck := SimpleDeck{Commander: "tymna", Crop: "own-crop.jpg", Partner: "thrasios"}
+	commanders := svc.completeSimpleDeck(&deck)
+	expected := SimpleDeck{
+		Commander: "Tymna the Weaver", CommanderOracleID: &tymna.OracleID, Image: "tymna.jpg", SecondaryImage: "tymna-back.jpg", Crop: "own-crop.jpg",
+		Partner: "Thrasios, Triton Hero", PartnerOracleID: &thrasios.OracleID, PartnerImage: "thrasios.jpg", PartnerCrop: "thrasios-crop.jpg",
+	}
+	if !reflect.DeepEqual(deck, expected) {
+		t.Errorf("expected %+v, got %+v", expected, deck)
+	}
+	if len(commanders) != 2 {
+		t.Errorf("expected both commanders to be stored, got %+v", commanders)
+	}
+}
+
+func TestResolveCommanderOnlyRewritesExactNames(t *testing.T) {
+	atraxa := &cards.Card{OracleID: "o-atraxa", Name: "Atraxa, Praetors' Voice"}
+	svc := &Service{Cards: fakeCardDatabase{"atraxa praetors voice": atraxa}}
+
+	tests := []struct {
+		name     string
+		expected string
+		resolved bool
+	}{
+		{name: "atraxa praetors voice", expected: "Atraxa, Praetors' Voice", resolved: true},
+		{name: "Atraxa", expected: "Atraxa"},
+		{name: "Atraxxa, Praetors' Voice", expected: "Atraxxa, Praetors' Voice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := tt.name
+			var oracleID *string
+			card := svc.resolveCommander(&name, &oracleID)
+			if name != tt.expected || (card != nil) != tt.resolved || (oracleID != nil) != tt.resolved {
+				t.Errorf("expected %q (resolved %v), got %q (%v)", tt.expected, tt.resolved, name, oracleID)
+			}
+		})
+	}
+}
+
+func TestCommandersComparedByOracleID(t *testing.T) {
+	atraxaID, tymnaID := "o-atraxa", "o-tymna"
+	deckID := uint(1)
+	ranking := func(id uint, position int, commander string, oracleID *string) Ranking {
+		return Ranking{Model: gorm.Model{ID: id}, Position: position, DeckEmbedded: SimpleDeck{Commander: commander, CommanderOracleID: oracleID}}
+	}
+	own := ranking(1, 1, "Krenko", nil)
+	own.DeckID = &deckID
+
+	// Spellings from before the backfill count as the commander they resolved to
+	games := []Game{
+		{Rankings: []Ranking{own, ranking(2, 2, "Atraxa, Praetors' Voice", &atraxaID)}},
+		{Rankings: []Ranking{own, ranking(3, 2, "atraxa", &atraxaID), ranking(4, 3, "Atraxa", nil)}},
+	}
+	stats := computeDeckStats(deckID, games)
+	if len(stats.MostBeatenCommanders) != 2 || stats.MostBeatenCommanders[0].Wins != 2 || stats.MostBeatenCommanders[1].Wins != 1 {
+		t.Errorf("expected Atraxa by oracle ID twice and the unknown spelling once, got %+v", stats.MostBeatenCommanders)
+	}
+
+	duplicate := []Ranking{ranking(11, 0, "atraxa", &atraxaID), ranking(12, 0, "Tymna", &tymnaID)}
+	survivor := []Ranking{ranking(21, 0, "Tymna the Weaver", &tymnaID), ranking(22, 0, "Atraxa, Praetors' Voice", &atraxaID)}
+	if !equalStrings(commanders(&Game{Rankings: duplicate}), commanders(&Game{Rankings: survivor})) {
+		t.Error("expected the same commanders written differently to be the same game")
+	}
+	matches := matchRankings(duplicate, survivor)
+	if matches[11] != 22 || matches[12] != 21 {
+		t.Errorf("expected guests to be matched by oracle ID, got %v", matches)
+	}
+}
+
+func TestCommanderBackfillReport(t *testing.T) {
+	report := CommanderBackfillReport{Unmatched: map[string]int64{"Homebrew": 2, "Atraxxxa": 5, "Custom": 2}}
+	expected := []string{"Atraxxxa", "Custom", "Homebrew"}
+	if names := report.UnmatchedNames(); !equalStrings(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestCheckPlayerReferences(t *testing.T) {
+	names := map[string]string{"alice": "Alice", "bob": "Bob"}
+	tests := []struct {
+		name        string
+		references  []PlayerReference
+		expected    []PlayerReference
+		expectError bool
+	}{
+		{
+			name:       "names come from the players",
+			references: []PlayerReference{{ID: "alice", Name: "Old name"}, {ID: "bob"}},
+			expected:   []PlayerReference{{ID: "alice", Name: "Alice"}, {ID: "bob", Name: "Bob"}},
+		},
+		{
+			name:       "duplicates are dropped",
+			references: []PlayerReference{{ID: "alice"}, {ID: "alice"}},
+			expected:   []PlayerReference{{ID: "alice", Name: "Alice"}},
+		},
+		{
+			name:        "players outside the game are rejected",
+			references:  []PlayerReference{{ID: "alice"}, {ID: "mallory", Name: "Mallory"}},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description := GameDescription{PlayerReferences: tt.references}
+			err := checkPlayerReferences(&description, names)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(description.PlayerReferences, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, description.PlayerReferences)
+			}
+		})
+	}
+}
+
+func TestCheckCardReferences(t *testing.T) {
+	db := fakeCardDatabase{
+		"Sol Ring": {Name: "Sol Ring", OracleText: "{T}: Add {C}{C}.", ColorIdentity: []string{}, Image: "sol.jpg", Crop: "sol-crop.jpg"},
+		"Delver of Secrets": {
+			Name: "Delver of Secrets // Insectile Aberration", OracleText: "Upkeep\n//\nFlying", ColorIdentity: []string{"U"},
+			Image: "delver.jpg", Crop: "delver-crop.jpg", SecondaryImage: "delver-back.jpg", SecondaryCrop: "delver-back-crop.jpg",
+		},
+	}
+	made := "made-up.jpg"
+
+	description := GameDescription{CardReferences: map[string]CardReference{
+		"Sol Ring": {Name: "Sol Ring", OracleText: "Draw seven cards.", ImageURI: &made, ColorIdentity: []string{"R"}},
+		"Delver":   {Name: "Delver of Secrets"},
+	}}
+	if err := checkCardReferences(&description, db.Get); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sol := description.CardReferences["Sol Ring"]
+	if sol.OracleText != "{T}: Add {C}{C}." || sol.ImageURI == nil || *sol.ImageURI != "sol.jpg" || len(sol.ColorIdentity) != 0 {
+		t.Errorf("expected the card data of the database, got %+v", sol)
+	}
+	if sol.SecondaryImageURI != nil {
+		t.Errorf("expected no back face, got %v", *sol.SecondaryImageURI)
+	}
+	delver, ok := description.CardReferences["Delver"]
+	if !ok || delver.Name != "Delver of Secrets // Insectile Aberration" || delver.SecondaryArtCropURI == nil {
+		t.Errorf("expected the reference to keep its key with the full card, got %+v", description.CardReferences)
+	}
+
+	description = GameDescription{CardReferences: map[string]CardReference{"Sol Rang": {}}}
+	if err := checkCardReferences(&description, db.Get); err == nil || !strings.Contains(err.Error(), "Sol Rang") {
+		t.Errorf("expected an error for an unknown card, got %v", err)
+	}
+}
+
+func TestParticipantsOf(t *testing.T) {
+	alice, bob := "alice", "bob"
+	game := &Game{Rankings: []Ranking{{PlayerID: &alice, Player: &Player{FirebaseID: alice, Name: "Alice"}}, {}}}
+	rankings := []Ranking{{PlayerID: &alice}, {PlayerID: &bob}}
+
+	participants := participantsOf(game, rankings)
+	if participants[0].Player == nil || participants[0].Player.Name != "Alice" {
+		t.Errorf("expected the player loaded with the game, got %+v", participants[0].Player)
+	}
+	if participants[1].Player != nil {
+		t.Errorf("expected a newly assigned player to be left for loading, got %+v", participants[1].Player)
+	}
+	if rankings[0].Player != nil {
+		t.Error("expected the rankings of the update to be left unchanged")
+	}
+}
+
+func TestDeriveLifeTotalAfter(t *testing.T) {
+	life := func(total int) *int { return &total }
+	tests := []struct {
+		name          string
+		eventType     string
+		counterType   string
+		damageDelta   int
+		lifeAfter     *int
+		expectedDelta int
+		expectedLife  int
+		expectedOk    bool
+	}{
+		{name: "derived without a client total", eventType: EventTypeDecrement, damageDelta: -5, expectedDelta: -5, expectedLife: 35, expectedOk: true},
+		{name: "matching client total", eventType: EventTypeDecrement, damageDelta: -5, lifeAfter: life(35), expectedDelta: -5, expectedLife: 35, expectedOk: true},
+		{name: "positive delta for damage is negated", eventType: EventTypeDecrement, damageDelta: 5, lifeAfter: life(35), expectedDelta: -5, expectedLife: 35, expectedOk: true},
+		{name: "positive commander damage is negated", eventType: EventTypeCommanderDamage, damageDelta: 7, lifeAfter: life(33), expectedDelta: -7, expectedLife: 33, expectedOk: true},
+		{name: "gain", eventType: EventTypeIncrement, damageDelta: 3, lifeAfter: life(43), expectedDelta: 3, expectedLife: 43, expectedOk: true},
+		{name: "stale gain isn't taken for damage", eventType: EventTypeIncrement, damageDelta: 3, lifeAfter: life(37), expectedDelta: 3, expectedLife: 43, expectedOk: false},
+		{name: "negative damage isn't negated", eventType: EventTypeDecrement, damageDelta: -5, lifeAfter: life(45), expectedDelta: -5, expectedLife: 35, expectedOk: false},
+		{name: "stale client total", eventType: EventTypeDecrement, damageDelta: -5, lifeAfter: life(30), expectedDelta: -5, expectedLife: 35, expectedOk: false},
+		{name: "counter keeps the life total", eventType: EventTypeIncrement, counterType: "poison", damageDelta: 1, lifeAfter: life(40), expectedDelta: 1, expectedLife: 40, expectedOk: true},
+		{name: "counter with another life total", eventType: EventTypeIncrement, counterType: "poison", damageDelta: 1, lifeAfter: life(41), expectedDelta: 1, expectedLife: 40, expectedOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta, lifeAfter, ok := deriveLifeTotalAfter(tt.eventType, tt.counterType, 40, tt.damageDelta, tt.lifeAfter)
+			if delta != tt.expectedDelta || lifeAfter != tt.expectedLife || ok != tt.expectedOk {
+				t.Errorf("expected (%d, %d, %v), got (%d, %d, %v)", tt.expectedDelta, tt.expectedLife, tt.expectedOk, delta, lifeAfter, ok)
+			}
+		})
+	}
+}
+
+func TestReplayLifeTotals(t *testing.T) {
+	event := func(id uint, eventType string, delta, lifeAfter int) GameEvent {
+		return GameEvent{Model: gorm.Model{ID: id}, EventType: eventType, DamageDelta: delta, TargetLifeTotalAfter: lifeAfter}
+	}
+	tests := []struct {
+		name     string
+		events   []GameEvent
+		expected map[uint]int
+	}{
+		{
+			name:     "consistent history",
+			events:   []GameEvent{event(1, EventTypeDecrement, -5, 35), event(2, EventTypeIncrement, 2, 37), event(3, EventTypeCommanderDamage, -7, 30)},
+			expected: map[uint]int{},
+		},
+		{
+			name:     "voided event shifts later totals",
+			events:   []GameEvent{event(1, EventTypeDecrement, -5, 35), event(3, EventTypeDecrement, -3, 27)},
+			expected: map[uint]int{3: 32},
+		},
+		{
+			name:     "init event resets the life total",
+			events:   []GameEvent{event(1, EventTypeDecrement, -5, 35), event(2, EventTypeInit, 0, 20), event(3, EventTypeDecrement, -4, 10)},
+			expected: map[uint]int{3: 16},
+		},
+		{
+			name:     "events without a life change keep the total",
+			events:   []GameEvent{event(1, EventTypeDecrement, -5, 35), event(2, EventTypeImage, 0, 40), event(3, EventTypeScoop, 0, 35)},
+			expected: map[uint]int{2: 35},
+		},
+		{
+			name: "counters don't change the life total",
+			events: []GameEvent{
+				{Model: gorm.Model{ID: 1}, EventType: EventTypeIncrement, CounterType: "poison", DamageDelta: 3, TargetLifeTotalAfter: 43},
+				event(2, EventTypeDecrement, -1, 42),
+			},
+			expected: map[uint]int{1: 40, 2: 39},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := replayLifeTotals(40, tt.events)
+			if !reflect.DeepEqual(changed, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"time"
+)
+
+// backdatedThreshold is how far before its creation a game can be dated and still be timed automatically
+// Games dated further back were logged after they were played, so their creation time says nothing about their duration
+const backdatedThreshold = 12 * time.Hour
+
+// activePause returns the pause a game is currently in, or nil if it isn't paused
+func activePause(pauses []GamePause) *GamePause {
+	for i := range pauses {
+		if pauses[i].ResumedAt == nil {
+			return &pauses[i]
+		}
+	}
+	return nil
+}
+
+// pausedSeconds returns how long the pauses overlap the interval from start to end
+// Pauses that haven't been resumed last until end
+func pausedSeconds(pauses []GamePause, start, end time.Time) int {
+	var paused time.Duration
+	for _, pause := range pauses {
+		from := pause.PausedAt
+		to := end
+		if pause.ResumedAt != nil && pause.ResumedAt.Before(end) {
+			to = *pause.ResumedAt
+		}
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(from) {
+			paused += to.Sub(from)
+		}
+	}
+	return int(paused.Seconds())
+}
+
+// gameDuration returns the playing time of a game in seconds from its creation until end, leaving out pauses
+// Returns nil for backdated games, which need a manual duration instead
+func gameDuration(game *Game, end time.Time) *int {
+	if game.Date != nil && game.Date.Before(game.CreatedAt.Add(-backdatedThreshold)) {
+		return nil
+	}
+
+	duration := int(end.Sub(game.CreatedAt).Seconds()) - pausedSeconds(game.Pauses, game.CreatedAt, end)
+	if duration < 0 {
+		duration = 0
+	}
+	return &duration
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"net/http"
+
+	"mtgtracker/internal/middleware"
+)
+
+// gameAction is something a user does to a game that needs authorization
+type gameAction string
+
+const (
+	actionEditGame    gameAction = "edit"        // Update the game, its rankings, pauses and guest claims
+	actionPostEvents  gameAction = "post_events" // Add, undo and redo game events
+	actionDeleteGame  gameAction = "delete"
+	actionVoteResult  gameAction = "vote_result" // Confirm or dispute the final positions
+	actionRestoreGame gameAction = "restore"     // Undelete a deleted game
+	actionLeaveGame   gameAction = "leave"       // Remove the player from a ranking
+)
+
+// gameRole is how a user relates to a game
+type gameRole struct {
+	Creator       bool
+	Participant   bool
+	Admin         bool
+	RankingPlayer bool // Plays the ranking the route is about
+}
+
+// gamePolicy decides which roles may perform an action
+var gamePolicy = map[gameAction]func(role gameRole) bool{
+	actionEditGame:    func(role gameRole) bool { return role.Creator || role.Participant },
+	actionPostEvents:  func(role gameRole) bool { return role.Participant },
+	actionDeleteGame:  func(role gameRole) bool { return role.Creator || role.Admin },
+	actionVoteResult:  func(role gameRole) bool { return role.Participant },
+	actionRestoreGame: func(role gameRole) bool { return role.Creator || role.Admin },
+	actionLeaveGame:   func(role gameRole) bool { return role.Creator || role.Admin || role.RankingPlayer },
+}
+
+// forbiddenMessages are the bodies of the 403 responses per action
+var forbiddenMessages = map[gameAction]string{
+	actionEditGame:    "Only the creator or participants of the game can change it",
+	actionPostEvents:  "Only game participants can post or undo events",
+	actionDeleteGame:  "Only the creator of the game or an admin can delete it",
+	actionVoteResult:  "Only game participants can confirm or dispute the result",
+	actionRestoreGame: "Only the creator of the game or an admin can restore it",
+	actionLeaveGame:   "Only the creator of the game, an admin or the ranking's player can remove the player",
+}
+
+// routeActions is the action each route that changes a game is authorized for
+// Routes missing here are denied by authorizeGame
+var routeActions = map[string]gameAction{
+	"PUT /game/v1/games/{gameId}":                        actionEditGame,
+	"DELETE /game/v1/games/{gameId}":                     actionDeleteGame,
+	"POST /game/v1/games/{gameId}/restore":               actionRestoreGame,
+	"POST /game/v1/games/{gameId}/events":                actionPostEvents,
+	"DELETE /game/v1/games/{gameId}/events/{eventId}":    actionPostEvents,
+	"POST /game/v1/games/{gameId}/events/{eventId}/redo": actionPostEvents,
+	"POST /game/v1/games/{gameId}/pause":                 actionEditGame,
+	"POST /game/v1/games/{gameId}/resume":                actionEditGame,
+	"POST /game/v1/games/{gameId}/result/confirm":        actionVoteResult,
+	"POST /game/v1/games/{gameId}/result/dispute":        actionVoteResult,
+	"POST /game/v1/games/{gameId}/merge":                 actionEditGame,
+	"POST /game/v1/games/{gameId}/discard":               actionDeleteGame,
+	"PUT /ranking/v1/rankings/{rankingId}":               actionEditGame,
+	"DELETE /ranking/v1/rankings/{rankingId}":            actionLeaveGame,
+	"POST /ranking/v1/rankings/{rankingId}/claims":       actionEditGame,
+}
+
+// roleInGame returns the role of a user in a game
+func roleInGame(game *Game, userID string, admin bool) gameRole {
+	return gameRole{
+		Creator:     game.CreatorID != nil && *game.CreatorID == userID,
+		Participant: isParticipant(game, userID),
+		Admin:       admin,
+	}
+}
+
+// canPerform reports whether a role may perform an action, unknown actions are never allowed
+func canPerform(action gameAction, role gameRole) bool {
+	allowed, ok := gamePolicy[action]
+	return ok && allowed(role)
+}
+
+// authorizeGame checks the current user against the policy of the request's route
+// It writes a 401 or 403 response and returns false if the user may not continue
+func (s *Service) authorizeGame(w http.ResponseWriter, r *http.Request, game *Game) bool {
+	return s.authorizeRole(w, r, func(userID string) gameRole {
+		return roleInGame(game, userID, false)
+	})
+}
+
+// authorizeRanking is authorizeGame for routes about one ranking, the ranking's player has a role of their own
+func (s *Service) authorizeRanking(w http.ResponseWriter, r *http.Request, game *Game, ranking *Ranking) bool {
+	return s.authorizeRole(w, r, func(userID string) gameRole {
+		role := roleInGame(game, userID, false)
+		role.RankingPlayer = ranking.PlayerID != nil && *ranking.PlayerID == userID
+		return role
+	})
+}
+
+// authorizeRole checks the role returned by roleOf for the current user against the policy of the request's route
+func (s *Service) authorizeRole(w http.ResponseWriter, r *http.Request, roleOf func(userID string) gameRole) bool {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	action, ok := routeActions[r.Pattern]
+	if !ok {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+
+	if !s.canUserPerform(action, userID, roleOf(userID)) {
+		http.Error(w, forbiddenMessages[action], http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// canUserPerform is canPerform for a user whose admin flag hasn't been looked up yet
+func (s *Service) canUserPerform(action gameAction, userID string, role gameRole) bool {
+	if canPerform(action, role) {
+		return true
+	}
+	// Only look up the player when being an admin could make a difference
+	player, err := s.Repository.GetPlayerByFirebaseID(userID)
+	if err != nil || !player.Admin {
+		return false
+	}
+	role.Admin = true
+	return canPerform(action, role)
+}
@@ -2,10 +2,12 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository struct {
@@ -22,6 +24,15 @@ func (r *Repository) GetPlayerByFirebaseID(userID string) (*Player, error) {
 	return &player, nil
 }
 
+// GetPlayersByFirebaseIDs returns the players with the IDs, unknown IDs are skipped
+func (r *Repository) GetPlayersByFirebaseIDs(userIDs []string) ([]Player, error) {
+	var players []Player
+	if err := r.DB.Where("firebase_id IN ?", userIDs).Find(&players).Error; err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
 func (r *Repository) GetPlayers(search string, limit, offset int) ([]Player, int64, error) {
 	var players []Player
 	var total int64
@@ -54,10 +65,12 @@ func (r *Repository) GetActiveGameForPlayer(playerID string) (*Game, error) {
 		Where("rankings.player_id = ?", playerID).
 		Where("games.finished = ?", false).
 		Preload("Rankings", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("Player").Preload("Deck")
+			return db.Preload("Player").Preload("Deck", withDeletedDecks).Preload("DeckVersion")
 		}).
+		Preload("GameEvents", eventsInOrder).
 		Preload("GameEvents.SourceRanking.Player").
 		Preload("GameEvents.TargetRanking.Player").
+		Preload("Pauses").
 		Preload("Creator").
 		Order("games.created_at DESC").
 		First(&game).Error
@@ -73,6 +86,11 @@ func (r *Repository) GetActiveGameForPlayer(playerID string) (*Game, error) {
 func (r *Repository) DeleteGame(gameID uint) error {
 	// Use a transaction to ensure all deletions succeed or fail together
 	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// A confirmed result was counted for the decks played in it
+		if err := r.uncountDeckStatistics(tx, gameID); err != nil {
+			return err
+		}
+
 		// First delete game events
 		if err := tx.Where("game_id = ?", gameID).Delete(&GameEvent{}).Error; err != nil {
 			return err
@@ -92,52 +110,177 @@ func (r *Repository) DeleteGame(gameID uint) error {
 	})
 }
 
-func (r *Repository) UpdateGame(gameId uint, rankings []Ranking, finished *bool) (*Game, error) {
-	// update the rankings
+// GetDeletedGames returns the deleted games of a creator that can be restored, most recently deleted first
+func (r *Repository) GetDeletedGames(creatorID string, limit, offset int) ([]Game, int64, error) {
+	var games []Game
+	var total int64
 
-	for _, rank := range rankings {
-		log.Println("updating ranking ", rank.ID, rank.PlayerID, rank.Position)
-		if err := r.DB.Model(&rank).Where("id = ?", rank.ID).Updates(rank).Error; err != nil {
-			return nil, err
+	query := r.DB.Unscoped().Model(&Game{}).
+		Where("creator_id = ? AND deleted_at IS NOT NULL AND merged_into_id IS NULL", creatorID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Rankings", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Rankings.Player").
+		Preload("Rankings.Deck", withDeletedDecks).Preload("Rankings.DeckVersion").
+		Order("deleted_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&games).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return games, total, nil
+}
+
+// GetDeletedGame returns a deleted game with its deleted rankings
+func (r *Repository) GetDeletedGame(gameID uint) (*Game, error) {
+	var game Game
+	err := r.DB.Unscoped().
+		Preload("Rankings", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Rankings.Player").
+		Where("deleted_at IS NOT NULL").
+		First(&game, gameID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("deleted game not found")
 		}
+		return nil, err
 	}
-	// update the game as finished
-	if finished != nil {
-		updates := map[string]interface{}{"finished": *finished}
-		if *finished {
-			// Get the game to access its CreatedAt time
-			var game Game
-			if err := r.DB.Where("id = ?", gameId).First(&game).Error; err != nil {
-				return nil, err
+	return &game, nil
+}
+
+// RestoreGame undeletes a game with its rankings and events
+// A confirmed result is counted for its decks again
+func (r *Repository) RestoreGame(gameID uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var game Game
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&game, gameID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("game not found")
+			}
+			return err
+		}
+		if !game.DeletedAt.Valid {
+			return errors.New("game is not deleted")
+		}
+		if game.MergedIntoID != nil {
+			return fmt.Errorf("game was merged into game %d and can't be restored", *game.MergedIntoID)
+		}
+
+		// Only deleting the whole game soft-deletes its rankings and events
+		if err := tx.Unscoped().Model(&GameEvent{}).Where("game_id = ? AND deleted_at IS NOT NULL", gameID).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&Ranking{}).Where("game_id = ? AND deleted_at IS NOT NULL", gameID).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&Game{}).Where("id = ?", gameID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		if game.ResultStatus != ResultStatusConfirmed {
+			return nil
+		}
+		if err := tx.Preload("Rankings").First(&game, gameID).Error; err != nil {
+			return err
+		}
+		txRepo := &Repository{DB: tx}
+		return txRepo.updateDeckStatisticsOnFinish(&game)
+	})
+}
+
+// UpdateGame updates the rankings of a game and finishes it
+// Finishing a game, or correcting the positions of a result that isn't confirmed yet, makes its result await confirmation
+// A confirmed result stays confirmed when the game is reopened and finished again
+// The returned flag reports whether that happened
+// With onlyUnfinished nothing changes once the game is finished and a nil game is returned, so an automatic finish
+// doesn't overwrite the positions of a manual one it raced
+func (r *Repository) UpdateGame(gameId uint, rankings []Ranking, finished *bool, duration *int, onlyUnfinished bool) (*Game, bool, error) {
+	awaitingConfirmation := false
+	skipped := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the game so concurrent updates, like an automatic finish racing a manual one, finish it only once
+		var game Game
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Pauses").Where("id = ?", gameId).First(&game).Error; err != nil {
+			return err
+		}
+		if onlyUnfinished && game.Finished {
+			skipped = true
+			return nil
+		}
+
+		// update the rankings
+
+		for _, rank := range rankings {
+			log.Println("updating ranking ", rank.ID, rank.PlayerID, rank.Position)
+			if err := tx.Model(&rank).Where("id = ?", rank.ID).Updates(rank).Error; err != nil {
+				return err
+			}
+		}
+		// update the game as finished
+		updates := map[string]interface{}{}
+		if duration != nil {
+			updates["duration"] = *duration
+		}
+		if finished != nil && *finished && !game.Finished {
+			updates["finished"] = true
+			// A reopened game with a confirmed result was counted already, finishing it again doesn't count it twice
+			if game.ResultStatus != ResultStatusConfirmed {
+				updates["result_status"] = ResultStatusPending
+				awaitingConfirmation = true
 			}
 
 			now := time.Now()
 			updates["end_date"] = now
+			// Join codes expire once the game is finished
+			updates["join_code"] = nil
+
+			// Keep a manual duration, otherwise calculate it in seconds without the paused time
+			if duration == nil && game.Duration == nil {
+				if calculated := gameDuration(&game, now); calculated != nil {
+					updates["duration"] = *calculated
+				}
+			}
 
-			// Calculate duration in seconds
-			duration := int(now.Sub(game.CreatedAt).Seconds())
-			updates["duration"] = duration
+			// A finished game can't stay paused
+			if err := tx.Model(&GamePause{}).Where("game_id = ? AND resumed_at IS NULL", gameId).Update("resumed_at", now).Error; err != nil {
+				return err
+			}
+		} else if finished != nil && !*finished {
+			updates["finished"] = false
+			// A confirmed result was already counted, anything else is asked again when the game finishes
+			if game.ResultStatus != ResultStatusConfirmed {
+				if err := tx.Unscoped().Where("game_id = ?", gameId).Delete(&GameResultVote{}).Error; err != nil {
+					return err
+				}
+				updates["result_status"] = ""
+			}
+		} else if game.Finished && game.ResultStatus != ResultStatusConfirmed && len(rankings) > 0 {
+			// Corrected positions have to be confirmed again
+			if err := tx.Unscoped().Where("game_id = ?", gameId).Delete(&GameResultVote{}).Error; err != nil {
+				return err
+			}
+			updates["result_status"] = ResultStatusPending
+			awaitingConfirmation = true
 		}
-		if err := r.DB.Model(&Game{}).Where("id = ?", gameId).Updates(updates).Error; err != nil {
-			return nil, err
+		if len(updates) > 0 {
+			return tx.Model(&Game{}).Where("id = ?", gameId).Updates(updates).Error
 		}
+		return nil
+	})
+	if err != nil || skipped {
+		return nil, false, err
 	}
 	// find the game with rankings and return it
 	res, err := r.GetGameWithEvents(gameId)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-
-	// Update deck statistics if game was just finished
-	if finished != nil && *finished {
-		// Update deck statistics when game is finished
-		if err := r.updateDeckStatisticsOnFinish(res); err != nil {
-			log.Printf("Failed to update deck statistics: %v", err)
-			// Don't fail the game update if deck stats update fails
-		}
-	}
-
-	return res, nil
+	return res, awaitingConfirmation, nil
 }
 
 func (r *Repository) GetGames(limit, offset int) ([]Game, int64, error) {
@@ -151,8 +294,8 @@ func (r *Repository) GetGames(limit, offset int) ([]Game, int64, error) {
 
 	// Get paginated results
 	err := r.DB.Preload("Rankings", func(db *gorm.DB) *gorm.DB {
-		return db.Preload("Player").Preload("Deck")
-	}).Preload("GameEvents").Order("Date desc").Limit(limit).Offset(offset).Find(&games).Error
+		return db.Preload("Player").Preload("Deck", withDeletedDecks).Preload("DeckVersion")
+	}).Preload("GameEvents", eventsInOrder).Order("Date desc").Limit(limit).Offset(offset).Find(&games).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -175,9 +318,9 @@ func (r *Repository) GetPlayerGames(playerID string, limit, offset int) ([]Game,
 	// Get paginated results
 	err := r.DB.Where("id IN (?)", subQuery).
 		Preload("Rankings", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("Player").Preload("Deck")
+			return db.Preload("Player").Preload("Deck", withDeletedDecks).Preload("DeckVersion")
 		}).
-		Preload("GameEvents").
+		Preload("GameEvents", eventsInOrder).
 		Preload("Creator").
 		Order("date DESC").
 		Limit(limit).
@@ -191,7 +334,7 @@ func (r *Repository) GetPlayerGames(playerID string, limit, offset int) ([]Game,
 }
 
 func NewRepository(db *gorm.DB) *Repository {
-	err := db.AutoMigrate(&Player{}, &Game{}, &Ranking{}, &GameEvent{}, &Deck{})
+	err := db.AutoMigrate(&Player{}, &Game{}, &Ranking{}, &GameEvent{}, &Deck{}, &GamePause{}, &RankingClaim{}, &GameResultVote{}, &AuditEntry{}, &DeckVersion{}, &Commander{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -200,9 +343,71 @@ func NewRepository(db *gorm.DB) *Repository {
 	db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_follow_pair
 		ON follows (player1_id, player2_id)`)
 
-	return &Repository{
+	repo := &Repository{
 		DB: db,
 	}
+	if err := repo.backfillJoinCodes(); err != nil {
+		log.Printf("Failed to add join codes to unfinished games: %v", err)
+	}
+	if err := repo.backfillDeckVersions(); err != nil {
+		log.Printf("Failed to add versions to decks: %v", err)
+	}
+	// Games finished before results needed a confirmation already count
+	if err := db.Model(&Game{}).Where("finished = ? AND (result_status = '' OR result_status IS NULL)", true).
+		Update("result_status", ResultStatusConfirmed).Error; err != nil {
+		log.Printf("Failed to confirm the results of finished games: %v", err)
+	}
+	return repo
+}
+
+// backfillDeckVersions stores the first version of decks created before decks had versions
+// Rankings played before are pinned to it
+func (r *Repository) backfillDeckVersions() error {
+	err := r.DB.Exec(`INSERT INTO deck_versions
+		(created_at, updated_at, deck_id, version, commander, moxfield_url, themes, bracket, colors, image, secondary_image, crop)
+		SELECT created_at, NOW(), id, version, commander, moxfield_url, themes, bracket, colors, image, secondary_image, crop
+		FROM decks d
+		WHERE NOT EXISTS (SELECT 1 FROM deck_versions v WHERE v.deck_id = d.id)`).Error
+	if err != nil {
+		return err
+	}
+	return r.pinDeckVersions(r.DB, "deck_version_id IS NULL")
+}
+
+// backfillJoinCodes gives unfinished games created before join codes existed a code
+func (r *Repository) backfillJoinCodes() error {
+	var games []Game
+	if err := r.DB.Select("id").Where("finished = ? AND join_code IS NULL", false).Find(&games).Error; err != nil {
+		return err
+	}
+	for _, game := range games {
+		code, err := r.newJoinCode()
+		if err != nil {
+			return err
+		}
+		if err := r.DB.Model(&Game{}).Where("id = ?", game.ID).Update("join_code", code).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newJoinCode generates a join code that no other game uses
+func (r *Repository) newJoinCode() (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := generateJoinCode()
+		if err != nil {
+			return "", err
+		}
+		var count int64
+		if err := r.DB.Model(&Game{}).Where("join_code = ?", code).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("failed to generate a unique join code")
 }
 func (r *Repository) InsertPlayer(name string, email string, userId string) (*Player, error) {
 	player := Player{Name: name, Email: email, FirebaseID: userId}
@@ -210,7 +415,7 @@ func (r *Repository) InsertPlayer(name string, email string, userId string) (*Pl
 	return &player, result.Error
 }
 
-func (r *Repository) InsertGame(creator *Player, comments, image string, date *time.Time, finished bool, rankings []Ranking) (*Game, error) {
+func (r *Repository) InsertGame(creator *Player, format, comments, image string, date *time.Time, duration *int, finished bool, rankings []Ranking, clock *ClockConfig) (*Game, error) {
 
 	// Ensure each ranking has valid player and deck
 	for i, rank := range rankings {
@@ -238,20 +443,40 @@ func (r *Repository) InsertGame(creator *Player, comments, image string, date *t
 			rank.Deck = &deck
 		}
 
+		if clock != nil {
+			remaining := clock.InitialSeconds
+			rank.ClockRemainingSeconds = &remaining
+		}
+
 		rankings[i] = rank
 	}
 
 	game := Game{
 		CreatorID: &creator.FirebaseID,
+		Format:    format,
 		Date:      date,
+		Duration:  duration,
 		Comments:  comments,
 		Image:     image,
 		Rankings:  rankings,
 	}
+	joinCode, err := r.newJoinCode()
+	if err != nil {
+		return nil, err
+	}
+	game.JoinCode = &joinCode
+	if clock != nil {
+		game.ClockInitialSeconds = &clock.InitialSeconds
+		game.ClockIncrementSeconds = clock.IncrementSeconds
+		game.ClockEliminateOnTimeout = clock.EliminateOnTimeout
+	}
 
 	if err := r.DB.Create(&game).Error; err != nil {
 		return nil, err
 	}
+	if err := r.pinDeckVersions(r.DB, "game_id = ?", game.ID); err != nil {
+		log.Printf("Failed to store the deck versions of game %d: %v", game.ID, err)
+	}
 
 	if err := r.createInitGameEvents(&game); err != nil {
 		log.Printf("Failed to create initial game events: %v", err)
@@ -262,12 +487,13 @@ func (r *Repository) InsertGame(creator *Player, comments, image string, date *t
 }
 
 func (r *Repository) createInitGameEvents(game *Game) error {
+	startingLife := rulesForGame(game).StartingLife
 	for _, ranking := range game.Rankings {
 		event := GameEvent{
 			GameID:               game.ID,
 			EventType:            EventTypeInit,
 			TargetRankingID:      &ranking.ID,
-			TargetLifeTotalAfter: 40, // Default starting life total for Commander
+			TargetLifeTotalAfter: startingLife,
 		}
 		if err := r.DB.Create(&event).Error; err != nil {
 			return err
@@ -276,172 +502,1104 @@ func (r *Repository) createInitGameEvents(game *Game) error {
 	return nil
 }
 
-func (r *Repository) InsertGameEvent(gameId uint, eventType string, damageDelta, lifeAfter int, source, target *uint, imageUrl string, comment *string) (*GameEvent, error) {
-	event := GameEvent{
-		GameID:               gameId,
-		EventType:            eventType,
-		DamageDelta:          damageDelta,
-		TargetLifeTotalAfter: lifeAfter,
-		SourceRankingID:      source,
-		TargetRankingID:      target,
-		ImageUrl:             imageUrl,
-		Comment:              comment,
-	}
-	if err := r.DB.Create(&event).Error; err != nil {
-		return nil, err
-	}
-	return &event, nil
-}
-
-func (r *Repository) GetGameWithEvents(gameID uint) (*Game, error) {
+// GetGameByJoinCode returns the unfinished game with a join code
+func (r *Repository) GetGameByJoinCode(code string) (*Game, error) {
 	var game Game
-	err := r.DB.
-		Preload("Rankings.Player").
-		Preload("Rankings.Deck").
-		Preload("GameEvents.SourceRanking.Player").
-		Preload("GameEvents.SourceRanking.Deck").
-		Preload("GameEvents.TargetRanking.Player").
-		Preload("GameEvents.TargetRanking.Deck").
-		First(&game, gameID).Error
+	err := r.DB.Select("id").Where("join_code = ? AND finished = ?", code, false).First(&game).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("game not found")
+		}
 		return nil, err
 	}
-	return &game, nil
+	return r.GetGameWithEvents(game.ID)
 }
 
-func (r *Repository) UpdatePlayerProfileImage(firebaseID, imageURL string) error {
-	result := r.DB.Model(&Player{}).Where("firebase_id = ?", firebaseID).Update("image", imageURL)
+// ClaimRanking assigns a player, and optionally one of their decks, to a ranking without a player
+func (r *Repository) ClaimRanking(rankingID uint, playerID string, deckID *uint) error {
+	updates := map[string]interface{}{"player_id": playerID}
+	if deckID != nil {
+		if err := r.checkDeckOwner(*deckID, playerID); err != nil {
+			return err
+		}
+		updates["deck_id"] = *deckID
+	}
+
+	// Only claim the ranking if nobody claimed it in the meantime
+	result := r.DB.Model(&Ranking{}).Where("id = ? AND player_id IS NULL", rankingID).Updates(updates)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("player not found")
+		return errors.New("ranking already belongs to a player")
 	}
-	return nil
+	return r.pinDeckVersions(r.DB, "id = ?", rankingID)
 }
 
-func (r *Repository) UpdatePlayer(firebaseID string, updates map[string]interface{}) (*Player, error) {
-	result := r.DB.Model(&Player{}).Where("firebase_id = ?", firebaseID).Updates(updates)
-	if result.Error != nil {
-		return nil, result.Error
-	}
-	if result.RowsAffected == 0 {
-		return nil, errors.New("player not found")
+// AddRanking adds a ranking to an unfinished game with the life total and clock of a new player
+// The join request is validated while the game is locked so concurrent joins can't overfill it
+func (r *Repository) AddRanking(gameID uint, ranking Ranking, request JoinGameRequest) (*Ranking, error) {
+	if ranking.DeckID != nil && ranking.PlayerID != nil {
+		if err := r.checkDeckOwner(*ranking.DeckID, *ranking.PlayerID); err != nil {
+			return nil, err
+		}
 	}
 
-	// Fetch and return the updated player
-	var player Player
-	if err := r.DB.Where("firebase_id = ?", firebaseID).First(&player).Error; err != nil {
-		return nil, err
-	}
-	return &player, nil
-}
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var game Game
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&game, gameID).Error; err != nil {
+			return err
+		}
+		if game.Finished {
+			return errors.New("game is already finished")
+		}
+		if err := tx.Where("game_id = ?", game.ID).Find(&game.Rankings).Error; err != nil {
+			return err
+		}
+		if err := validateJoiningRanking(&game, request); err != nil {
+			return err
+		}
 
-func (r *Repository) UpdateRanking(rankingID uint, updates map[string]interface{}) (*Ranking, error) {
-	result := r.DB.Model(&Ranking{}).Where("id = ?", rankingID).Updates(updates)
-	if result.Error != nil {
-		return nil, result.Error
-	}
-	if result.RowsAffected == 0 {
-		return nil, errors.New("ranking not found")
-	}
+		ranking.GameID = game.ID
+		if game.ClockInitialSeconds != nil {
+			remaining := *game.ClockInitialSeconds
+			ranking.ClockRemainingSeconds = &remaining
+		}
+		if err := tx.Create(&ranking).Error; err != nil {
+			return err
+		}
+		if err := r.pinDeckVersions(tx, "id = ?", ranking.ID); err != nil {
+			return err
+		}
 
-	// Fetch and return the updated ranking with relationships
-	var ranking Ranking
-	if err := r.DB.Preload("Player").Preload("Deck").Where("id = ?", rankingID).First(&ranking).Error; err != nil {
+		// Teammates with a shared life total are joined at the team's current life total
+		rankingIDs, err := r.lifeRankingIDs(tx, game.ID, ranking.ID)
+		if err != nil {
+			return err
+		}
+		life, err := r.currentLifeTotal(tx, game.ID, rankingIDs)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&GameEvent{
+			GameID:               game.ID,
+			EventType:            EventTypeInit,
+			TargetRankingID:      &ranking.ID,
+			TargetLifeTotalAfter: life,
+		}).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &ranking, nil
 }
 
-// GetRankingWithGamePlayers fetches ranking data and other player IDs in the same game
-// Returns: ranking, gameID, otherPlayerIDs (excluding the ranking's player), error
-func (r *Repository) GetRankingWithGamePlayers(rankingID uint) (*Ranking, uint, []string, error) {
-	// Get the ranking
-	var ranking Ranking
-	if err := r.DB.First(&ranking, rankingID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, 0, nil, errors.New("ranking not found")
-		}
-		return nil, 0, nil, err
-	}
+// pinDeckVersions sets the rankings matched by the query to the current version of their deck
+// Rankings keep that version when the deck changes later
+func (r *Repository) pinDeckVersions(db *gorm.DB, query string, args ...interface{}) error {
+	current := db.Model(&DeckVersion{}).Select("deck_versions.id").
+		Joins("JOIN decks ON decks.id = deck_versions.deck_id AND decks.version = deck_versions.version").
+		Where("deck_versions.deck_id = rankings.deck_id")
+	return db.Model(&Ranking{}).
+		Where(query, args...).
+		Where("deck_id IS NOT NULL").
+		Update("deck_version_id", current).Error
+}
 
-	// Check if ranking is already deleted (player_id is nil)
-	if ranking.PlayerID == nil {
-		return nil, 0, nil, errors.New("ranking already deleted")
+// checkDeckOwner verifies that a deck exists and belongs to a player
+func (r *Repository) checkDeckOwner(deckID uint, playerID string) error {
+	var deck Deck
+	if err := r.DB.First(&deck, deckID).Error; err != nil {
+		return errors.New("invalid deck ID")
 	}
-
-	// Get all other rankings in the same game
-	var allRankings []Ranking
-	if err := r.DB.Where("game_id = ?", ranking.GameID).Find(&allRankings).Error; err != nil {
-		return nil, 0, nil, err
+	if deck.PlayerID != nil && *deck.PlayerID != playerID {
+		return errors.New("deck does not belong to player")
 	}
+	return nil
+}
 
-	// Extract other player IDs (excluding the current ranking's player and guests)
-	otherPlayerIDs := make([]string, 0)
-	for _, r := range allRankings {
-		if r.ID != rankingID && r.PlayerID != nil {
-			otherPlayerIDs = append(otherPlayerIDs, *r.PlayerID)
-		}
-	}
+// LifeTotalConflictError is returned when the life total sent by a client doesn't match the server's
+type LifeTotalConflictError struct {
+	RankingID              uint
+	CurrentLifeTotal       int
+	ExpectedLifeTotalAfter int
+}
 
-	return &ranking, ranking.GameID, otherPlayerIDs, nil
+func (e *LifeTotalConflictError) Error() string {
+	return fmt.Sprintf("life total conflict for ranking %d: current life total is %d, expected %d after this event",
+		e.RankingID, e.CurrentLifeTotal, e.ExpectedLifeTotalAfter)
 }
 
-func (r *Repository) DeleteRanking(rankingID uint, userID string) error {
-	// First, get the ranking to verify it exists and get the game info
-	var ranking Ranking
-	if err := r.DB.First(&ranking, rankingID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("ranking not found")
-		}
-		return err
+// changesLifeTotal reports whether an event applies its DamageDelta to the target's life total
+func changesLifeTotal(eventType, counterType string) bool {
+	if counterType != "" {
+		return false
 	}
-
-	// Check if ranking is already deleted (player_id is nil)
-	if ranking.PlayerID == nil {
-		return errors.New("ranking already deleted")
+	switch eventType {
+	case EventTypeIncrement, EventTypeDecrement, EventTypeCommanderDamage:
+		return true
 	}
+	return false
+}
 
-	// Get the game to verify the user is authorized (must be creator or the player in the ranking)
-	var game Game
-	if err := r.DB.First(&game, ranking.GameID).Error; err != nil {
-		return err
+// deriveLifeTotalAfter returns the delta to store and the life total after an event applied to currentLife
+// DamageDelta is the signed change of the life total, so damage is a negative delta
+// For damage sent as a positive delta, a lifeAfter matching currentLife minus the delta is also accepted
+// and the delta is negated, so clients sending the amount lost keep working
+// ok is false if lifeAfter is provided and matches neither
+func deriveLifeTotalAfter(eventType, counterType string, currentLife, damageDelta int, lifeAfter *int) (int, int, bool) {
+	if !changesLifeTotal(eventType, counterType) {
+		return damageDelta, currentLife, lifeAfter == nil || *lifeAfter == currentLife
 	}
-
-	// Check authorization: user must be either the game creator or the player in the ranking
-	isCreator := game.CreatorID != nil && *game.CreatorID == userID
-	isRankingPlayer := ranking.PlayerID != nil && *ranking.PlayerID == userID
-	if !isCreator && !isRankingPlayer {
-		return errors.New("unauthorized to delete this ranking")
+	expected := currentLife + damageDelta
+	if lifeAfter == nil || *lifeAfter == expected {
+		return damageDelta, expected, true
 	}
+	isDamage := eventType == EventTypeDecrement || eventType == EventTypeCommanderDamage
+	if isDamage && damageDelta > 0 && *lifeAfter == currentLife-damageDelta {
+		return -damageDelta, *lifeAfter, true
+	}
+	return damageDelta, expected, false
+}
 
-	// Decrement deck statistics if this ranking has a deck reference
-	if ranking.DeckID != nil && game.Finished {
-		if err := r.decrementDeckStatistics(*ranking.DeckID, ranking.Position == 1); err != nil {
-			log.Printf("Failed to decrement deck statistics: %v", err)
-			// Don't fail the deletion if deck stats update fails
-		}
+// InsertGameEvent stores a new game event with a life total derived from the target's previous event
+// The target ranking rows are locked so concurrent events for the same player or team are applied one after another
+// If lifeAfter is provided and doesn't match the derived total, a LifeTotalConflictError is returned
+func (r *Repository) InsertGameEvent(gameId uint, eventType string, damageDelta int, lifeAfter *int, source, target *uint, imageUrl string, comment *string, commanderIndex int, counterType string) (*GameEvent, error) {
+	event := GameEvent{
+		GameID:          gameId,
+		EventType:       eventType,
+		DamageDelta:     damageDelta,
+		SourceRankingID: source,
+		TargetRankingID: target,
+		ImageUrl:        imageUrl,
+		Comment:         comment,
+		CommanderIndex:  commanderIndex,
+		CounterType:     counterType,
 	}
 
-	// Set player_id to nil instead of deleting the ranking
-	return r.DB.Model(&ranking).Update("player_id", nil).Error
-}
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if target != nil {
+			rankingIDs, err := r.lifeRankingIDs(tx, gameId, *target)
+			if err != nil {
+				return err
+			}
+			if err := r.lockRankings(tx, gameId, rankingIDs); err != nil {
+				return err
+			}
 
-func (r *Repository) updateDeckStatisticsOnFinish(game *Game) error {
-	for _, ranking := range game.Rankings {
-		// Only update if the ranking has a deck reference
-		if ranking.DeckID != nil {
-			isWinner := ranking.Position == 1
-			if err := r.incrementDeckStatistics(*ranking.DeckID, isWinner); err != nil {
+			currentLife, err := r.currentLifeTotal(tx, gameId, rankingIDs)
+			if err != nil {
 				return err
 			}
+
+			delta, expected, ok := deriveLifeTotalAfter(eventType, counterType, currentLife, damageDelta, lifeAfter)
+			if !ok {
+				return &LifeTotalConflictError{
+					RankingID:              *target,
+					CurrentLifeTotal:       currentLife,
+					ExpectedLifeTotalAfter: expected,
+				}
+			}
+			event.DamageDelta = delta
+			event.TargetLifeTotalAfter = expected
 		}
+
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &event, nil
 }
 
-func (r *Repository) incrementDeckStatistics(deckID uint, isWinner bool) error {
+// InsertTurnPassEvent ends the current turn and starts the turn of the target ranking
+// The game row is locked so concurrent turn passes are numbered one after another
+// The event keeps the target's life total so it doesn't change the derived life totals
+func (r *Repository) InsertTurnPassEvent(gameID uint, source *uint, target uint) (*GameEvent, error) {
+	event := GameEvent{
+		GameID:          gameID,
+		EventType:       EventTypeTurnPass,
+		SourceRankingID: source,
+		TargetRankingID: &target,
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var game Game
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Rankings").
+			Preload("GameEvents", "event_type = ?", EventTypeTurnPass, func(db *gorm.DB) *gorm.DB {
+				return db.Order("id ASC")
+			}).
+			Preload("Pauses").
+			First(&game, gameID).Error
+		if err != nil {
+			return err
+		}
+
+		state := deriveTurnState(&game, time.Now())
+		hadActive := state.ActiveRankingID != nil
+		if source == nil {
+			source = state.ActiveRankingID
+			event.SourceRankingID = source
+		}
+		// Use the same time for the event so the stored duration matches the derived one
+		event.CreatedAt = time.Now()
+		state.pass(source, target, event.CreatedAt)
+		event.TurnNumber = state.TurnNumber
+		if hadActive {
+			event.TurnDurationSeconds = state.LastDuration
+		}
+
+		rankingIDs, err := r.lifeRankingIDs(tx, gameID, target)
+		if err != nil {
+			return err
+		}
+		event.TargetLifeTotalAfter, err = r.currentLifeTotal(tx, gameID, rankingIDs)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// lifeRankingIDs returns the IDs of the rankings sharing a life total with a ranking, including the ranking itself
+func (r *Repository) lifeRankingIDs(tx *gorm.DB, gameID, rankingID uint) ([]uint, error) {
+	var game Game
+	if err := tx.Select("id", "format").First(&game, gameID).Error; err != nil {
+		return nil, err
+	}
+	var rankings []Ranking
+	if err := tx.Where("game_id = ?", gameID).Find(&rankings).Error; err != nil {
+		return nil, err
+	}
+	if rankingIDs, ok := lifeSharingRankings(rankings, rulesForGame(&game))[rankingID]; ok {
+		return rankingIDs, nil
+	}
+	return []uint{rankingID}, nil
+}
+
+// lockRankings locks the rows of rankings in ID order so concurrent transactions can't deadlock
+func (r *Repository) lockRankings(tx *gorm.DB, gameID uint, rankingIDs []uint) error {
+	var rankings []Ranking
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ? AND game_id = ?", rankingIDs, gameID).
+		Order("id ASC").
+		Find(&rankings).Error
+	if err != nil {
+		return err
+	}
+	if len(rankings) != len(rankingIDs) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// currentLifeTotal returns the life total after the most recent life event targeting any of the rankings
+func (r *Repository) currentLifeTotal(tx *gorm.DB, gameID uint, rankingIDs []uint) (int, error) {
+	var previous GameEvent
+	err := tx.Where("game_id = ? AND target_ranking_id IN ? AND counter_type = ? AND voided_at IS NULL", gameID, rankingIDs, "").
+		Order("id DESC").
+		First(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.startingLife(tx, gameID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return previous.TargetLifeTotalAfter, nil
+}
+
+// startingLife returns the starting life total of a game's format
+func (r *Repository) startingLife(tx *gorm.DB, gameID uint) (int, error) {
+	var game Game
+	if err := tx.Select("id", "format").First(&game, gameID).Error; err != nil {
+		return 0, err
+	}
+	return rulesForGame(&game).StartingLife, nil
+}
+
+// SetGameEventVoided voids an event when voidedBy is set, or restores it when voidedBy is nil
+// The stored life totals of the target's later events are recalculated without the voided events
+func (r *Repository) SetGameEventVoided(gameID, eventID uint, voidedBy *string) (*GameEvent, error) {
+	var event GameEvent
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND game_id = ?", eventID, gameID).First(&event).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("game event not found")
+			}
+			return err
+		}
+		if event.EventType == EventTypeInit {
+			return errors.New("initial events cannot be voided")
+		}
+		if voidedBy != nil && event.VoidedAt != nil {
+			return errors.New("game event already voided")
+		}
+		if voidedBy == nil && event.VoidedAt == nil {
+			return errors.New("game event is not voided")
+		}
+
+		// Lock the target rankings so no new event for them is derived from stale totals
+		var rankingIDs []uint
+		if event.TargetRankingID != nil {
+			var err error
+			rankingIDs, err = r.lifeRankingIDs(tx, gameID, *event.TargetRankingID)
+			if err != nil {
+				return err
+			}
+			if err := r.lockRankings(tx, gameID, rankingIDs); err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{"voided_at": nil, "voided_by_id": nil}
+		if voidedBy != nil {
+			updates["voided_at"] = time.Now()
+			updates["voided_by_id"] = *voidedBy
+		}
+		if err := tx.Model(&event).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if event.TargetRankingID != nil {
+			return r.recalculateLifeTotals(tx, gameID, rankingIDs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.DB.First(&event, eventID).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// recalculateLifeTotals replays the non-voided events of rankings sharing a life total and fixes their stored life totals
+func (r *Repository) recalculateLifeTotals(tx *gorm.DB, gameID uint, rankingIDs []uint) error {
+	var gameEvents []GameEvent
+	err := tx.Where("game_id = ? AND target_ranking_id IN ? AND voided_at IS NULL", gameID, rankingIDs).
+		Order("id ASC").
+		Find(&gameEvents).Error
+	if err != nil {
+		return err
+	}
+
+	life, err := r.startingLife(tx, gameID)
+	if err != nil {
+		return err
+	}
+	for eventID, lifeAfter := range replayLifeTotals(life, gameEvents) {
+		if err := tx.Model(&GameEvent{}).Where("id = ?", eventID).Update("target_life_total_after", lifeAfter).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayLifeTotals applies the events in order to the starting life total
+// It returns the life total of each event whose stored total differs, keyed by event ID
+func replayLifeTotals(life int, gameEvents []GameEvent) map[uint]int {
+	changed := make(map[uint]int)
+	for _, event := range gameEvents {
+		switch {
+		case event.EventType == EventTypeInit:
+			life = event.TargetLifeTotalAfter
+		case changesLifeTotal(event.EventType, event.CounterType):
+			life += event.DamageDelta
+		}
+		if event.TargetLifeTotalAfter != life {
+			changed[event.ID] = life
+		}
+	}
+	return changed
+}
+
+// eventsInOrder preloads the events of a game in the order they were logged, which replaying them relies on
+// Updates to event rows change the order the database returns them in without one
+func eventsInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
+
+func (r *Repository) GetGameWithEvents(gameID uint) (*Game, error) {
+	var game Game
+	err := r.DB.
+		Preload("Rankings.Player").
+		Preload("Rankings.Deck", withDeletedDecks).Preload("Rankings.DeckVersion").
+		Preload("GameEvents", eventsInOrder).
+		Preload("GameEvents.SourceRanking.Player").
+		Preload("GameEvents.SourceRanking.Deck", withDeletedDecks).Preload("GameEvents.SourceRanking.DeckVersion").
+		Preload("GameEvents.TargetRanking.Player").
+		Preload("GameEvents.TargetRanking.Deck", withDeletedDecks).Preload("GameEvents.TargetRanking.DeckVersion").
+		Preload("Pauses").
+		Preload("ResultVotes").
+		First(&game, gameID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+// PauseGame starts a pause of an unfinished game
+// The game row is locked so a game can't be paused twice at the same time
+func (r *Repository) PauseGame(gameID uint, pausedBy string) (*GamePause, error) {
+	var pause GamePause
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var game Game
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Pauses").First(&game, gameID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("game not found")
+			}
+			return err
+		}
+		if game.Finished {
+			return errors.New("a finished game cannot be paused")
+		}
+		if activePause(game.Pauses) != nil {
+			return errors.New("game is already paused")
+		}
+
+		pause = GamePause{
+			GameID:     gameID,
+			PausedAt:   time.Now(),
+			PausedByID: &pausedBy,
+		}
+		return tx.Create(&pause).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+// ResumeGame ends the current pause of a game
+func (r *Repository) ResumeGame(gameID uint) (*GamePause, error) {
+	var pause GamePause
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var game Game
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Pauses").First(&game, gameID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("game not found")
+			}
+			return err
+		}
+		active := activePause(game.Pauses)
+		if active == nil {
+			return errors.New("game is not paused")
+		}
+
+		pause = *active
+		now := time.Now()
+		pause.ResumedAt = &now
+		return tx.Model(&pause).Update("resumed_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+func (r *Repository) UpdatePlayerProfileImage(firebaseID, imageURL string) error {
+	result := r.DB.Model(&Player{}).Where("firebase_id = ?", firebaseID).Update("image", imageURL)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("player not found")
+	}
+	return nil
+}
+
+func (r *Repository) UpdatePlayer(firebaseID string, updates map[string]interface{}) (*Player, error) {
+	result := r.DB.Model(&Player{}).Where("firebase_id = ?", firebaseID).Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("player not found")
+	}
+
+	// Fetch and return the updated player
+	var player Player
+	if err := r.DB.Where("firebase_id = ?", firebaseID).First(&player).Error; err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+func (r *Repository) UpdateRanking(rankingID uint, updates map[string]interface{}) (*Ranking, error) {
+	result := r.DB.Model(&Ranking{}).Where("id = ?", rankingID).Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("ranking not found")
+	}
+
+	// Fetch and return the updated ranking with relationships
+	var ranking Ranking
+	if err := r.DB.Preload("Player").Preload("Deck", withDeletedDecks).Preload("DeckVersion").Where("id = ?", rankingID).First(&ranking).Error; err != nil {
+		return nil, err
+	}
+	return &ranking, nil
+}
+
+// UpdateRankingElimination stores the elimination derived from the game events on a ranking
+func (r *Repository) UpdateRankingElimination(rankingID uint, state *rankingState) error {
+	updates := map[string]interface{}{
+		"eliminated":               state.Eliminated,
+		"eliminated_at":            state.EliminatedAt,
+		"eliminated_by_ranking_id": state.EliminatedByRankingID,
+		"elimination_reason":       state.EliminationReason,
+	}
+	return r.DB.Model(&Ranking{}).Where("id = ?", rankingID).Updates(updates).Error
+}
+
+// GetRankingWithGamePlayers fetches ranking data and other player IDs in the same game
+// Returns: ranking, gameID, otherPlayerIDs (excluding the ranking's player), error
+func (r *Repository) GetRankingWithGamePlayers(rankingID uint) (*Ranking, uint, []string, error) {
+	// Get the ranking
+	var ranking Ranking
+	if err := r.DB.First(&ranking, rankingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, nil, errors.New("ranking not found")
+		}
+		return nil, 0, nil, err
+	}
+
+	// Check if ranking is already deleted (player_id is nil)
+	if ranking.PlayerID == nil {
+		return nil, 0, nil, errors.New("ranking already deleted")
+	}
+
+	// Get all other rankings in the same game
+	var allRankings []Ranking
+	if err := r.DB.Where("game_id = ?", ranking.GameID).Find(&allRankings).Error; err != nil {
+		return nil, 0, nil, err
+	}
+
+	// Extract other player IDs (excluding the current ranking's player and guests)
+	otherPlayerIDs := make([]string, 0)
+	for _, r := range allRankings {
+		if r.ID != rankingID && r.PlayerID != nil {
+			otherPlayerIDs = append(otherPlayerIDs, *r.PlayerID)
+		}
+	}
+
+	return &ranking, ranking.GameID, otherPlayerIDs, nil
+}
+
+// DeleteRanking removes the player from a ranking, the caller authorizes the user
+func (r *Repository) DeleteRanking(rankingID uint) error {
+	// First, get the ranking to verify it exists and get the game info
+	var ranking Ranking
+	if err := r.DB.First(&ranking, rankingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("ranking not found")
+		}
+		return err
+	}
+
+	// Check if ranking is already deleted (player_id is nil)
+	if ranking.PlayerID == nil {
+		return errors.New("ranking already deleted")
+	}
+
+	var game Game
+	if err := r.DB.First(&game, ranking.GameID).Error; err != nil {
+		return err
+	}
+
+	// Decrement deck statistics if this ranking has a deck reference
+	if ranking.DeckID != nil && game.ResultStatus == ResultStatusConfirmed {
+		if err := r.decrementDeckStatistics(*ranking.DeckID, ranking.Position == 1); err != nil {
+			log.Printf("Failed to decrement deck statistics: %v", err)
+			// Don't fail the deletion if deck stats update fails
+		}
+	}
+
+	// Set player_id to nil instead of deleting the ranking
+	return r.DB.Model(&ranking).Update("player_id", nil).Error
+}
+
+// CreateRankingClaim proposes a guest ranking to a player
+func (r *Repository) CreateRankingClaim(rankingID uint, playerID, proposedByID string) (*RankingClaim, error) {
+	var ranking Ranking
+	if err := r.DB.First(&ranking, rankingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ranking not found")
+		}
+		return nil, err
+	}
+	if ranking.PlayerID != nil {
+		return nil, errors.New("ranking already belongs to a player")
+	}
+
+	var player Player
+	if err := r.DB.Where("firebase_id = ?", playerID).First(&player).Error; err != nil {
+		return nil, errors.New("player not found")
+	}
+
+	// A player can't take over a second ranking in the same game
+	var count int64
+	if err := r.DB.Model(&Ranking{}).Where("game_id = ? AND player_id = ?", ranking.GameID, playerID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("player already plays in this game")
+	}
+	if err := r.DB.Model(&RankingClaim{}).
+		Where("ranking_id = ? AND player_id = ? AND status = ?", rankingID, playerID, ClaimStatusPending).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("claim is already pending")
+	}
+
+	claim := RankingClaim{
+		RankingID:    ranking.ID,
+		GameID:       ranking.GameID,
+		PlayerID:     playerID,
+		ProposedByID: proposedByID,
+		Status:       ClaimStatusPending,
+	}
+	if err := r.DB.Create(&claim).Error; err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// GetPendingRankingClaims returns the claims waiting for a player's answer
+func (r *Repository) GetPendingRankingClaims(playerID string) ([]RankingClaim, error) {
+	var claims []RankingClaim
+	err := r.DB.Where("player_id = ? AND status = ?", playerID, ClaimStatusPending).
+		Preload("Ranking.Deck", withDeletedDecks).Preload("Ranking.DeckVersion").
+		Preload("ProposedBy").
+		Order("created_at DESC").
+		Find(&claims).Error
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// AcceptRankingClaim gives the guest ranking of a pending claim to its player
+// Without a deck ID the ranking is linked to the player's deck with the same commander, if there is one
+// Other pending claims on the ranking are rejected
+func (r *Repository) AcceptRankingClaim(claimID uint, playerID string, deckID *uint) (*RankingClaim, *Game, error) {
+	var claim RankingClaim
+	var game Game
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := r.lockPendingClaim(tx, &claim, claimID, playerID); err != nil {
+			return err
+		}
+
+		var ranking Ranking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ranking, claim.RankingID).Error; err != nil {
+			return err
+		}
+		if ranking.PlayerID != nil {
+			return errors.New("ranking already belongs to a player")
+		}
+		if err := tx.First(&game, ranking.GameID).Error; err != nil {
+			return err
+		}
+
+		txRepo := &Repository{DB: tx}
+		if deckID != nil {
+			if err := txRepo.checkDeckOwner(*deckID, playerID); err != nil {
+				return err
+			}
+		} else if ranking.DeckID == nil && ranking.DeckEmbedded.Commander != "" {
+			var deck Deck
+			err := tx.Where("player_id = ? AND LOWER(commander) = LOWER(?) AND LOWER(COALESCE(partner, '')) = LOWER(?)",
+				playerID, ranking.DeckEmbedded.Commander, ranking.DeckEmbedded.Partner).
+				Order("game_count DESC").
+				First(&deck).Error
+			if err == nil {
+				deckID = &deck.ID
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		// Updates sets the new deck on the ranking, so keep the deck it was played with
+		previousDeckID := ranking.DeckID
+		updates := map[string]interface{}{"player_id": playerID}
+		if deckID != nil {
+			updates["deck_id"] = *deckID
+		}
+		if err := tx.Model(&ranking).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := r.pinDeckVersions(tx, "id = ?", ranking.ID); err != nil {
+			return err
+		}
+
+		// Confirmed games already counted towards deck statistics, so the linked deck catches up
+		if game.ResultStatus == ResultStatusConfirmed && deckID != nil && (previousDeckID == nil || *previousDeckID != *deckID) {
+			if err := txRepo.incrementDeckStatistics(*deckID, ranking.Position == 1); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&claim).Update("status", ClaimStatusAccepted).Error; err != nil {
+			return err
+		}
+		return tx.Model(&RankingClaim{}).
+			Where("ranking_id = ? AND id <> ? AND status = ?", claim.RankingID, claim.ID, ClaimStatusPending).
+			Update("status", ClaimStatusRejected).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	claim.Status = ClaimStatusAccepted
+	return &claim, &game, nil
+}
+
+// RejectRankingClaim rejects a pending claim of a player
+func (r *Repository) RejectRankingClaim(claimID uint, playerID string) (*RankingClaim, error) {
+	var claim RankingClaim
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := r.lockPendingClaim(tx, &claim, claimID, playerID); err != nil {
+			return err
+		}
+		return tx.Model(&claim).Update("status", ClaimStatusRejected).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	claim.Status = ClaimStatusRejected
+	return &claim, nil
+}
+
+// lockPendingClaim loads and locks a claim, only the player it is proposed to can answer it and only once
+func (r *Repository) lockPendingClaim(tx *gorm.DB, claim *RankingClaim, claimID uint, playerID string) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(claim, claimID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("claim not found")
+		}
+		return err
+	}
+	if claim.PlayerID != playerID {
+		return errors.New("unauthorized to answer this claim")
+	}
+	if claim.Status != ClaimStatusPending {
+		return fmt.Errorf("claim was already %s", claim.Status)
+	}
+	return nil
+}
+
+// RecordResultVote stores a participant's confirmation or dispute of a finished game's result
+// A player can change their vote as long as the result isn't confirmed
+func (r *Repository) RecordResultVote(gameID uint, playerID string, confirmed bool, comment string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var game Game
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&game, gameID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("game not found")
+			}
+			return err
+		}
+		if !game.Finished {
+			return errors.New("game is not finished yet")
+		}
+		if game.ResultStatus == ResultStatusConfirmed {
+			return errors.New("result is already confirmed")
+		}
+
+		vote := GameResultVote{GameID: gameID, PlayerID: playerID, Confirmed: confirmed, Comment: comment}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "game_id"}, {Name: "player_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"confirmed", "comment", "updated_at"}),
+		}).Create(&vote).Error
+	})
+}
+
+// ConfirmGameResult confirms a pending result and counts the game for the decks played in it
+// It reports false if the result was not pending, so a result is only ever counted once
+func (r *Repository) ConfirmGameResult(gameID uint) (bool, error) {
+	result := r.DB.Model(&Game{}).
+		Where("id = ? AND result_status = ?", gameID, ResultStatusPending).
+		Updates(map[string]interface{}{"result_status": ResultStatusConfirmed, "result_confirmed_at": time.Now()})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	game, err := r.GetGameWithEvents(gameID)
+	if err != nil {
+		return true, err
+	}
+	if err := r.updateDeckStatisticsOnFinish(game); err != nil {
+		log.Printf("Failed to update deck statistics: %v", err)
+		// Don't fail the confirmation if deck stats update fails
+	}
+	return true, nil
+}
+
+// DisputeGameResult marks a pending result as disputed, it reports false if the result was not pending
+func (r *Repository) DisputeGameResult(gameID uint) (bool, error) {
+	result := r.DB.Model(&Game{}).
+		Where("id = ? AND result_status = ?", gameID, ResultStatusPending).
+		Update("result_status", ResultStatusDisputed)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetPendingResultGameIDs returns the games with a pending result that finished before a point in time
+func (r *Repository) GetPendingResultGameIDs(finishedBefore time.Time) ([]uint, error) {
+	var gameIDs []uint
+	err := r.DB.Model(&Game{}).
+		Where("result_status = ? AND end_date < ?", ResultStatusPending, finishedBefore).
+		Pluck("id", &gameIDs).Error
+	return gameIDs, err
+}
+
+// GetClockTimeoutGameIDs returns the unfinished games whose players are eliminated when their clock runs out
+func (r *Repository) GetClockTimeoutGameIDs() ([]uint, error) {
+	var gameIDs []uint
+	err := r.DB.Model(&Game{}).
+		Where("finished = ? AND clock_initial_seconds IS NOT NULL AND clock_eliminate_on_timeout = ?", false, true).
+		Pluck("id", &gameIDs).Error
+	return gameIDs, err
+}
+
+// GetDisputedGames returns the games of a creator with a disputed result
+func (r *Repository) GetDisputedGames(creatorID string) ([]Game, error) {
+	var games []Game
+	err := r.DB.Where("creator_id = ? AND result_status = ?", creatorID, ResultStatusDisputed).
+		Preload("Rankings", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("Player").Preload("Deck", withDeletedDecks).Preload("DeckVersion")
+		}).
+		Preload("ResultVotes").
+		Preload("Creator").
+		Order("end_date DESC").
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// GetConfirmedPlayerGames returns all games of a player with a confirmed result in the order they were played
+func (r *Repository) GetConfirmedPlayerGames(playerID string) ([]Game, error) {
+	var games []Game
+	subQuery := r.DB.Model(&Ranking{}).Select("game_id").Where("player_id = ?", playerID)
+	err := r.DB.Where("id IN (?) AND result_status = ?", subQuery, ResultStatusConfirmed).
+		Preload("Rankings.Player").
+		Preload("Rankings.Deck", withDeletedDecks).Preload("Rankings.DeckVersion").
+		Preload("GameEvents", eventsInOrder).
+		Preload("Pauses").
+		Order("COALESCE(end_date, date, created_at) ASC").
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// GetConfirmedDeckGames returns all games a deck was played in with a confirmed result in the order they were played
+func (r *Repository) GetConfirmedDeckGames(deckID uint) ([]Game, error) {
+	var games []Game
+	subQuery := r.DB.Model(&Ranking{}).Select("game_id").Where("deck_id = ?", deckID)
+	err := r.DB.Where("id IN (?) AND result_status = ?", subQuery, ResultStatusConfirmed).
+		Preload("Rankings.Player").
+		Preload("Rankings.Deck", withDeletedDecks).Preload("Rankings.DeckVersion").
+		Preload("GameEvents", eventsInOrder).
+		Preload("Pauses").
+		Order("COALESCE(end_date, date, created_at) ASC").
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// uncountDeckStatistics takes a game with a confirmed result off the counters of its decks
+func (r *Repository) uncountDeckStatistics(tx *gorm.DB, gameID uint) error {
+	var game Game
+	if err := tx.Preload("Rankings").First(&game, gameID).Error; err != nil {
+		return err
+	}
+	if game.ResultStatus != ResultStatusConfirmed {
+		return nil
+	}
+	txRepo := &Repository{DB: tx}
+	for _, ranking := range game.Rankings {
+		if ranking.DeckID != nil {
+			if err := txRepo.decrementDeckStatistics(*ranking.DeckID, ranking.Position == 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// FindDuplicateCandidates returns the games that look like the same game as the given one, see isDuplicateCandidate
+func (r *Repository) FindDuplicateCandidates(game *Game) ([]Game, error) {
+	playerIDs := registeredPlayers(game)
+	if len(playerIDs) == 0 {
+		return nil, nil
+	}
+
+	start, _ := gameWindow(game)
+	subQuery := r.DB.Model(&Ranking{}).Select("game_id").Where("player_id IN ?", playerIDs)
+	var games []Game
+	err := r.DB.Where("id IN (?) AND id <> ?", subQuery, game.ID).
+		Where("COALESCE(date, created_at) BETWEEN ? AND ?", start.Add(-backdatedThreshold), start.Add(backdatedThreshold)).
+		Preload("Rankings.Deck", withDeletedDecks).Preload("Rankings.DeckVersion").
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Game, 0, len(games))
+	for i := range games {
+		if isDuplicateCandidate(game, &games[i]) {
+			candidates = append(candidates, games[i])
+		}
+	}
+	return candidates, nil
+}
+
+// MergeGames merges a duplicate game into the game that survives and deletes the duplicate
+// Rankings of the duplicate are matched to the survivor's rankings, unmatched rankings move to the survivor
+// Both games logged what happened between matched rankings, so only events involving an unmatched ranking move to the
+// survivor, pointing to the matched rankings, and the life totals are recalculated afterwards
+// The other events are deleted with the duplicate
+// It returns the survivor's ranking for every ranking of the duplicate
+func (r *Repository) MergeGames(survivorID, duplicateID uint) (map[uint]uint, error) {
+	rankingMap := make(map[uint]uint)
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var survivor, duplicate Game
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Rankings.Deck", withDeletedDecks).First(&survivor, survivorID).Error; err != nil {
+			return errors.New("game not found")
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Rankings.Deck", withDeletedDecks).First(&duplicate, duplicateID).Error; err != nil {
+			return errors.New("duplicate game not found")
+		}
+
+		// The duplicate's result no longer counts for its decks
+		if err := r.uncountDeckStatistics(tx, duplicate.ID); err != nil {
+			return err
+		}
+
+		matches := matchRankings(duplicate.Rankings, survivor.Rankings)
+		unmatched := []uint{0} // Never empty in an IN clause
+		for _, ranking := range duplicate.Rankings {
+			target, matched := matches[ranking.ID]
+			if !matched {
+				if err := tx.Model(&Ranking{}).Where("id = ?", ranking.ID).Update("game_id", survivor.ID).Error; err != nil {
+					return err
+				}
+				target = ranking.ID
+				unmatched = append(unmatched, ranking.ID)
+			}
+			rankingMap[ranking.ID] = target
+		}
+
+		var movedEventIDs []uint
+		if err := tx.Model(&GameEvent{}).
+			Where("game_id = ? AND (source_ranking_id IN ? OR target_ranking_id IN ?)", duplicate.ID, unmatched, unmatched).
+			Pluck("id", &movedEventIDs).Error; err != nil {
+			return err
+		}
+		if len(movedEventIDs) > 0 {
+			for from, to := range matches {
+				if err := tx.Model(&GameEvent{}).Where("id IN ? AND source_ranking_id = ?", movedEventIDs, from).
+					Update("source_ranking_id", to).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&GameEvent{}).Where("id IN ? AND target_ranking_id = ?", movedEventIDs, from).
+					Update("target_ranking_id", to).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&GameEvent{}).Where("id IN ?", movedEventIDs).Update("game_id", survivor.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("game_id = ?", duplicate.ID).Delete(&GameEvent{}).Error; err != nil {
+			return err
+		}
+
+		// Claims on moved guest rankings stay valid, claims on matched rankings are answered by the survivor's ranking
+		if err := tx.Model(&RankingClaim{}).Where("game_id = ? AND ranking_id NOT IN ?", duplicate.ID, keysOf(matches)).
+			Update("game_id", survivor.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("game_id = ?", duplicate.ID).Delete(&RankingClaim{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("game_id = ?", duplicate.ID).Delete(&GamePause{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("game_id = ?", duplicate.ID).Delete(&GameResultVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("game_id = ?", duplicate.ID).Delete(&Ranking{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Game{}).Where("id = ?", duplicate.ID).Update("merged_into_id", survivor.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Game{}, duplicate.ID).Error; err != nil {
+			return err
+		}
+
+		// Replay the merged events of every life total of the survivor
+		var rankings []Ranking
+		if err := tx.Where("game_id = ?", survivor.ID).Find(&rankings).Error; err != nil {
+			return err
+		}
+		for _, ranking := range rankings {
+			rankingIDs, err := r.lifeRankingIDs(tx, survivor.ID, ranking.ID)
+			if err != nil {
+				return err
+			}
+			if err := r.recalculateLifeTotals(tx, survivor.ID, rankingIDs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rankingMap, nil
+}
+
+// keysOf returns the keys of a ranking map, with a zero ID so the list is never empty in an IN clause
+func keysOf(rankings map[uint]uint) []uint {
+	keys := []uint{0}
+	for key := range rankings {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (r *Repository) updateDeckStatisticsOnFinish(game *Game) error {
+	for _, ranking := range game.Rankings {
+		// Only update if the ranking has a deck reference
+		if ranking.DeckID != nil {
+			isWinner := ranking.Position == 1
+			if err := r.incrementDeckStatistics(*ranking.DeckID, isWinner); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Deck counters keep counting after a deck was deleted, so games deleted or restored later stay consistent
+func (r *Repository) incrementDeckStatistics(deckID uint, isWinner bool) error {
 	log.Println("Incrementing stats for deck", deckID, "winner:", isWinner)
 	updates := map[string]interface{}{
 		"game_count": gorm.Expr("game_count + ?", 1),
@@ -449,7 +1607,7 @@ func (r *Repository) incrementDeckStatistics(deckID uint, isWinner bool) error {
 	if isWinner {
 		updates["win_count"] = gorm.Expr("win_count + ?", 1)
 	}
-	return r.DB.Model(&Deck{}).Where("id = ?", deckID).Updates(updates).Error
+	return r.DB.Unscoped().Model(&Deck{}).Where("id = ?", deckID).Updates(updates).Error
 }
 
 func (r *Repository) decrementDeckStatistics(deckID uint, wasWinner bool) error {
@@ -459,23 +1617,34 @@ func (r *Repository) decrementDeckStatistics(deckID uint, wasWinner bool) error
 	if wasWinner {
 		updates["win_count"] = gorm.Expr("CASE WHEN win_count > 0 THEN win_count - 1 ELSE 0 END")
 	}
-	return r.DB.Model(&Deck{}).Where("id = ?", deckID).Updates(updates).Error
+	return r.DB.Unscoped().Model(&Deck{}).Where("id = ?", deckID).Updates(updates).Error
 }
 
-func (r *Repository) CreateDeck(playerID, commander, image, secondaryImage, crop string, moxFieldID *string, themes, colors []string, bracket *uint) (*Deck, error) {
+func (r *Repository) CreateDeck(playerID string, request CreateDeckRequest) (*Deck, error) {
 	deck := Deck{
 		PlayerID:       &playerID,
-		MoxfieldURL:    moxFieldID,
-		Themes:         themes,
-		Bracket:        bracket,
-		Commander:      commander,
-		Colors:         colors,
-		Image:          image,
-		SecondaryImage: secondaryImage,
-		Crop:           crop,
+		MoxfieldURL:    request.MoxfieldURL,
+		Themes:         request.Themes,
+		Bracket:        request.Bracket,
+		Commander:      request.Commander,
+		Colors:         request.Colors,
+		Image:          request.Image,
+		SecondaryImage: request.SecondaryImage,
+		Crop:           request.Crop,
+		Partner:        request.Partner,
+		PartnerImage:   request.PartnerImage,
+		PartnerCrop:    request.PartnerCrop,
+		Version:        1,
 	}
 
-	if err := r.DB.Create(&deck).Error; err != nil {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&deck).Error; err != nil {
+			return err
+		}
+		version := snapshotDeck(&deck)
+		return tx.Create(&version).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -487,11 +1656,91 @@ func (r *Repository) CreateDeck(playerID, commander, image, secondaryImage, crop
 	return &deck, nil
 }
 
-func (r *Repository) GetPlayerDecks(playerID string, limit, offset int) ([]Deck, int64, error) {
+// GetDeck returns a deck with its player
+func (r *Repository) GetDeck(deckID uint) (*Deck, error) {
+	var deck Deck
+	if err := r.DB.Preload("Player").First(&deck, deckID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("deck not found")
+		}
+		return nil, err
+	}
+	return &deck, nil
+}
+
+// UpdateDeck applies a change to a deck
+// A change to what the deck is stores a new version, games played before keep the version they were played with
+func (r *Repository) UpdateDeck(deckID uint, update func(deck *Deck)) (*Deck, error) {
+	var deck Deck
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deck, deckID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("deck not found")
+			}
+			return err
+		}
+
+		before := snapshotDeck(&deck)
+		update(&deck)
+		if after := snapshotDeck(&deck); !sameDeckVersion(before, after) {
+			deck.Version++
+			version := snapshotDeck(&deck)
+			if err := tx.Create(&version).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(&deck).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetDeck(deck.ID)
+}
+
+// SetDeckRetired retires a deck or brings it back to the deck pickers
+func (r *Repository) SetDeckRetired(deckID uint, retired bool) (*Deck, error) {
+	var retiredAt *time.Time
+	if retired {
+		now := time.Now()
+		retiredAt = &now
+	}
+	result := r.DB.Model(&Deck{}).Where("id = ?", deckID).Update("retired_at", retiredAt)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("deck not found")
+	}
+	return r.GetDeck(deckID)
+}
+
+// DeleteDeck soft-deletes a deck, games played with it keep showing it and its versions
+func (r *Repository) DeleteDeck(deckID uint) error {
+	result := r.DB.Delete(&Deck{}, deckID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("deck not found")
+	}
+	return nil
+}
+
+// GetDeckVersions returns the versions of a deck, newest first
+func (r *Repository) GetDeckVersions(deckID uint) ([]DeckVersion, error) {
+	var versions []DeckVersion
+	err := r.DB.Where("deck_id = ?", deckID).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+func (r *Repository) GetPlayerDecks(playerID string, includeRetired bool, limit, offset int) ([]Deck, int64, error) {
 	var decks []Deck
 	var total int64
 
 	query := r.DB.Model(&Deck{}).Where("player_id = ?", playerID)
+	if !includeRetired {
+		query = query.Where("retired_at IS NULL")
+	}
 
 	// Get total count
 	if err := query.Count(&total).Error; err != nil {
@@ -499,7 +1748,7 @@ func (r *Repository) GetPlayerDecks(playerID string, limit, offset int) ([]Deck,
 	}
 
 	// Get paginated results
-	err := r.DB.Where("player_id = ?", playerID).
+	err := query.
 		Order("game_count DESC, win_count DESC").
 		Limit(limit).Offset(offset).
 		Find(&decks).Error
@@ -512,3 +1761,29 @@ func (r *Repository) GetPlayerDecks(playerID string, limit, offset int) ([]Deck,
 func (r *Repository) SearchGames(playerIDs []string, limit, offset int) ([]Game, int64, error) {
 	return r.SearchGamesWithFilters(GameFilter{PlayerIDs: playerIDs}, limit, offset)
 }
+
+// InsertAuditEntries appends entries to the audit log
+func (r *Repository) InsertAuditEntries(entries []AuditEntry) error {
+	return r.DB.Create(&entries).Error
+}
+
+// GetGameHistory returns the audit log of a game, oldest first
+// The log is kept after the game is deleted
+func (r *Repository) GetGameHistory(gameID uint, limit, offset int) ([]AuditEntry, int64, error) {
+	var entries []AuditEntry
+	var total int64
+
+	query := r.DB.Model(&AuditEntry{}).Where("game_id = ?", gameID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Actor").
+		Order("created_at ASC, id ASC").
+		Limit(limit).Offset(offset).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
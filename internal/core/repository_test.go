@@ -0,0 +1,707 @@
+package core
+
+import (
+	"fmt"
+	"mtgtracker/internal/events"
+	"mtgtracker/internal/middleware"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testRepository connects to the database of TEST_POSTGRES_DSN, tests that need a database are skipped without it
+// Every test creates its own players and games, so the database can be shared
+func testRepository(t *testing.T) *Repository {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	return NewRepository(db)
+}
+
+// createTestPlayer stores a player with a unique ID and returns the ID
+func createTestPlayer(t *testing.T, repo *Repository, name string) string {
+	t.Helper()
+	id := fmt.Sprintf("%s-%d", strings.ToLower(name), time.Now().UnixNano())
+	player := Player{FirebaseID: id, Name: id, Email: id + "@example.com"}
+	if err := repo.DB.Create(&player).Error; err != nil {
+		t.Fatalf("failed to create player: %v", err)
+	}
+	return id
+}
+
+// createTestDeck stores a deck of a player with a commander
+func createTestDeck(t *testing.T, repo *Repository, playerID, commander string) *Deck {
+	t.Helper()
+	deck, err := repo.CreateDeck(playerID, CreateDeckRequest{Commander: commander})
+	if err != nil {
+		t.Fatalf("failed to create deck: %v", err)
+	}
+	return deck
+}
+
+// createTestGame stores a game with its rankings
+func createTestGame(t *testing.T, repo *Repository, game Game) *Game {
+	t.Helper()
+	if err := repo.DB.Create(&game).Error; err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	return &game
+}
+
+// reloadDeck returns the stored counters of a deck, deleted decks included
+func reloadDeck(t *testing.T, repo *Repository, deckID uint) Deck {
+	t.Helper()
+	var deck Deck
+	if err := repo.DB.Unscoped().First(&deck, deckID).Error; err != nil {
+		t.Fatalf("failed to load deck %d: %v", deckID, err)
+	}
+	return deck
+}
+
+func TestRankingClaims(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	guest := createTestPlayer(t, repo, "guest")
+	other := createTestPlayer(t, repo, "other")
+
+	game := createTestGame(t, repo, Game{
+		CreatorID: &creator,
+		Finished:  true,
+		Rankings: []Ranking{
+			{PlayerID: &creator, Position: 2},
+			{Position: 1, DeckEmbedded: SimpleDeck{Commander: "Atraxa, Praetors' Voice"}},
+		},
+	})
+	guestRanking := game.Rankings[1]
+
+	if _, err := repo.CreateRankingClaim(game.Rankings[0].ID, guest, creator); err == nil || !strings.Contains(err.Error(), "already belongs") {
+		t.Errorf("expected a ranking with a player to be rejected, got %v", err)
+	}
+	if _, err := repo.CreateRankingClaim(guestRanking.ID, creator, creator); err == nil || !strings.Contains(err.Error(), "already plays") {
+		t.Errorf("expected a participant to be rejected, got %v", err)
+	}
+
+	claim, err := repo.CreateRankingClaim(guestRanking.ID, guest, creator)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claim.Status != ClaimStatusPending || claim.GameID != game.ID || claim.ProposedByID != creator {
+		t.Errorf("expected a pending claim of the game, got %+v", claim)
+	}
+	if _, err := repo.CreateRankingClaim(guestRanking.ID, guest, creator); err == nil || !strings.Contains(err.Error(), "already pending") {
+		t.Errorf("expected a second claim to be rejected, got %v", err)
+	}
+	otherClaim, err := repo.CreateRankingClaim(guestRanking.ID, other, creator)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pending, err := repo.GetPendingRankingClaims(guest)
+	if err != nil || len(pending) != 1 || pending[0].ID != claim.ID {
+		t.Errorf("expected the claim to wait for the player, got %+v (%v)", pending, err)
+	}
+
+	if _, err := repo.RejectRankingClaim(claim.ID, other); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("expected another player to be unable to answer, got %v", err)
+	}
+	if _, _, err := repo.AcceptRankingClaim(claim.ID, other, nil); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("expected another player to be unable to accept, got %v", err)
+	}
+
+	accepted, _, err := repo.AcceptRankingClaim(claim.ID, guest, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if accepted.Status != ClaimStatusAccepted {
+		t.Errorf("expected the claim to be accepted, got %s", accepted.Status)
+	}
+
+	var ranking Ranking
+	repo.DB.First(&ranking, guestRanking.ID)
+	if ranking.PlayerID == nil || *ranking.PlayerID != guest || ranking.DeckID != nil {
+		t.Errorf("expected the ranking to belong to the player without a deck, got %+v", ranking)
+	}
+	var rejected RankingClaim
+	repo.DB.First(&rejected, otherClaim.ID)
+	if rejected.Status != ClaimStatusRejected {
+		t.Errorf("expected the other pending claim to be rejected, got %s", rejected.Status)
+	}
+	if _, err := repo.RejectRankingClaim(claim.ID, guest); err == nil || !strings.Contains(err.Error(), "already accepted") {
+		t.Errorf("expected an answered claim to be final, got %v", err)
+	}
+}
+
+func TestRejectRankingClaim(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	guest := createTestPlayer(t, repo, "guest")
+	game := createTestGame(t, repo, Game{
+		CreatorID: &creator,
+		Rankings:  []Ranking{{PlayerID: &creator}, {}},
+	})
+
+	claim, err := repo.CreateRankingClaim(game.Rankings[1].ID, guest, creator)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rejected, err := repo.RejectRankingClaim(claim.ID, guest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rejected.Status != ClaimStatusRejected {
+		t.Errorf("expected the claim to be rejected, got %s", rejected.Status)
+	}
+
+	var ranking Ranking
+	repo.DB.First(&ranking, game.Rankings[1].ID)
+	if ranking.PlayerID != nil {
+		t.Errorf("expected the ranking to stay a guest ranking, got %s", *ranking.PlayerID)
+	}
+	if _, _, err := repo.AcceptRankingClaim(claim.ID, guest, nil); err == nil || !strings.Contains(err.Error(), "already rejected") {
+		t.Errorf("expected a rejected claim to be final, got %v", err)
+	}
+
+	// A rejected claim can be proposed again
+	if _, err := repo.CreateRankingClaim(game.Rankings[1].ID, guest, creator); err != nil {
+		t.Errorf("expected a new claim after a rejection, got %v", err)
+	}
+}
+
+func TestAcceptRankingClaimLinksDeck(t *testing.T) {
+	tests := []struct {
+		name          string
+		resultStatus  string
+		chooseDeck    bool
+		expectedGames int
+		expectedWins  int
+	}{
+		{name: "confirmed game counts for the linked deck", resultStatus: ResultStatusConfirmed, expectedGames: 1, expectedWins: 1},
+		{name: "chosen deck of a confirmed game", resultStatus: ResultStatusConfirmed, chooseDeck: true, expectedGames: 1, expectedWins: 1},
+		{name: "pending game is counted once confirmed", resultStatus: ResultStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testRepository(t)
+			creator := createTestPlayer(t, repo, "creator")
+			guest := createTestPlayer(t, repo, "guest")
+			matching := createTestDeck(t, repo, guest, "Atraxa, Praetors' Voice")
+			chosen := createTestDeck(t, repo, guest, "Edgar Markov")
+			createTestDeck(t, repo, creator, "Atraxa, Praetors' Voice")
+
+			game := createTestGame(t, repo, Game{
+				CreatorID:    &creator,
+				Finished:     true,
+				ResultStatus: tt.resultStatus,
+				Rankings: []Ranking{
+					{PlayerID: &creator, Position: 2},
+					{Position: 1, DeckEmbedded: SimpleDeck{Commander: "atraxa, praetors' voice"}},
+				},
+			})
+			claim, err := repo.CreateRankingClaim(game.Rankings[1].ID, guest, creator)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expectedDeck := matching
+			var deckID *uint
+			if tt.chooseDeck {
+				expectedDeck = chosen
+				deckID = &chosen.ID
+			}
+			if _, _, err := repo.AcceptRankingClaim(claim.ID, guest, deckID); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var ranking Ranking
+			repo.DB.First(&ranking, game.Rankings[1].ID)
+			if ranking.DeckID == nil || *ranking.DeckID != expectedDeck.ID {
+				t.Fatalf("expected the ranking to be linked to deck %d, got %v", expectedDeck.ID, ranking.DeckID)
+			}
+			if ranking.DeckVersionID == nil {
+				t.Error("expected the ranking to be pinned to the deck's version")
+			}
+			deck := reloadDeck(t, repo, expectedDeck.ID)
+			if deck.GameCount != tt.expectedGames || deck.WinCount != tt.expectedWins {
+				t.Errorf("expected %d games and %d wins, got %d and %d", tt.expectedGames, tt.expectedWins, deck.GameCount, deck.WinCount)
+			}
+		})
+	}
+}
+
+func TestAcceptRankingClaimRejectsForeignDeck(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	guest := createTestPlayer(t, repo, "guest")
+	foreign := createTestDeck(t, repo, creator, "Edgar Markov")
+	game := createTestGame(t, repo, Game{
+		CreatorID:    &creator,
+		Finished:     true,
+		ResultStatus: ResultStatusConfirmed,
+		Rankings:     []Ranking{{PlayerID: &creator, Position: 2}, {Position: 1}},
+	})
+	claim, err := repo.CreateRankingClaim(game.Rankings[1].ID, guest, creator)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, _, err := repo.AcceptRankingClaim(claim.ID, guest, &foreign.ID); err == nil || !strings.Contains(err.Error(), "does not belong") {
+		t.Fatalf("expected a deck of another player to be rejected, got %v", err)
+	}
+
+	// Nothing of the accept is kept
+	var ranking Ranking
+	repo.DB.First(&ranking, game.Rankings[1].ID)
+	if ranking.PlayerID != nil {
+		t.Errorf("expected the ranking to stay a guest ranking, got %s", *ranking.PlayerID)
+	}
+	var stored RankingClaim
+	repo.DB.First(&stored, claim.ID)
+	if stored.Status != ClaimStatusPending {
+		t.Errorf("expected the claim to stay pending, got %s", stored.Status)
+	}
+	if deck := reloadDeck(t, repo, foreign.ID); deck.GameCount != 0 {
+		t.Errorf("expected the foreign deck to stay uncounted, got %d games", deck.GameCount)
+	}
+}
+
+func TestAutomaticFinishLosesToManualFinish(t *testing.T) {
+	repo := testRepository(t)
+	first := createTestPlayer(t, repo, "first")
+	second := createTestPlayer(t, repo, "second")
+	game := createTestGame(t, repo, Game{CreatorID: &first, Rankings: []Ranking{{PlayerID: &first}, {PlayerID: &second}}})
+	positions := func(firstPosition, secondPosition int) []Ranking {
+		return []Ranking{
+			{Model: gorm.Model{ID: game.Rankings[0].ID}, Position: firstPosition},
+			{Model: gorm.Model{ID: game.Rankings[1].ID}, Position: secondPosition},
+		}
+	}
+	finished := true
+
+	if _, _, err := repo.UpdateGame(game.ID, positions(1, 2), &finished, nil, false); err != nil {
+		t.Fatalf("unexpected error finishing: %v", err)
+	}
+	if err := repo.RecordResultVote(game.ID, first, true, ""); err != nil {
+		t.Fatalf("unexpected error voting: %v", err)
+	}
+
+	updated, awaiting, err := repo.UpdateGame(game.ID, positions(2, 1), &finished, nil, true)
+	if err != nil || updated != nil || awaiting {
+		t.Fatalf("expected the automatic finish to be skipped, got %v, %v, %v", updated, awaiting, err)
+	}
+	stored, err := repo.GetGameWithEvents(game.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, ranking := range stored.Rankings {
+		if expected := map[string]int{first: 1, second: 2}[*ranking.PlayerID]; ranking.Position != expected {
+			t.Errorf("expected %s to keep position %d, got %d", *ranking.PlayerID, expected, ranking.Position)
+		}
+	}
+	if len(stored.ResultVotes) != 1 || stored.ResultStatus != ResultStatusPending {
+		t.Errorf("expected the vote on the pending result to be kept, got %d votes with status %q", len(stored.ResultVotes), stored.ResultStatus)
+	}
+}
+
+func TestRefinishingConfirmedGameCountsOnce(t *testing.T) {
+	repo := testRepository(t)
+	winner := createTestPlayer(t, repo, "winner")
+	loser := createTestPlayer(t, repo, "loser")
+	winnerDeck := createTestDeck(t, repo, winner, "Edgar Markov")
+	loserDeck := createTestDeck(t, repo, loser, "Atraxa, Praetors' Voice")
+	game := createTestGame(t, repo, Game{
+		CreatorID: &winner,
+		Rankings: []Ranking{
+			{PlayerID: &winner, DeckID: &winnerDeck.ID},
+			{PlayerID: &loser, DeckID: &loserDeck.ID},
+		},
+	})
+	positions := []Ranking{
+		{Model: gorm.Model{ID: game.Rankings[0].ID}, Position: 1},
+		{Model: gorm.Model{ID: game.Rankings[1].ID}, Position: 2},
+	}
+	finished, reopened := true, false
+
+	steps := []struct {
+		name              string
+		finished          *bool
+		expectedAwaiting  bool
+		expectedStatus    string
+		expectedConfirmed bool
+	}{
+		{name: "finish", finished: &finished, expectedAwaiting: true, expectedStatus: ResultStatusPending, expectedConfirmed: true},
+		{name: "reopen", finished: &reopened, expectedStatus: ResultStatusConfirmed},
+		{name: "finish again", finished: &finished, expectedStatus: ResultStatusConfirmed},
+	}
+	for _, step := range steps {
+		updated, awaiting, err := repo.UpdateGame(game.ID, positions, step.finished, nil, false)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if awaiting != step.expectedAwaiting || updated.ResultStatus != step.expectedStatus {
+			t.Errorf("%s: expected awaiting %v with status %q, got %v with %q", step.name, step.expectedAwaiting, step.expectedStatus, awaiting, updated.ResultStatus)
+		}
+		confirmed, err := repo.ConfirmGameResult(game.ID)
+		if err != nil {
+			t.Fatalf("%s: unexpected error confirming: %v", step.name, err)
+		}
+		if confirmed != step.expectedConfirmed {
+			t.Errorf("%s: expected the result to be confirmed now: %v, got %v", step.name, step.expectedConfirmed, confirmed)
+		}
+	}
+
+	if deck := reloadDeck(t, repo, winnerDeck.ID); deck.GameCount != 1 || deck.WinCount != 1 {
+		t.Errorf("expected the winner's deck to count the game once, got %d games and %d wins", deck.GameCount, deck.WinCount)
+	}
+	if deck := reloadDeck(t, repo, loserDeck.ID); deck.GameCount != 1 || deck.WinCount != 0 {
+		t.Errorf("expected the loser's deck to count the game once, got %d games and %d wins", deck.GameCount, deck.WinCount)
+	}
+}
+
+func TestRestoreGame(t *testing.T) {
+	tests := []struct {
+		name          string
+		resultStatus  string
+		expectedGames int
+	}{
+		{name: "confirmed result counts for the decks again", resultStatus: ResultStatusConfirmed, expectedGames: 1},
+		{name: "pending result isn't counted", resultStatus: ResultStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testRepository(t)
+			creator := createTestPlayer(t, repo, "creator")
+			opponent := createTestPlayer(t, repo, "opponent")
+			deck := createTestDeck(t, repo, creator, "Edgar Markov")
+			game := createTestGame(t, repo, Game{
+				CreatorID:    &creator,
+				Finished:     true,
+				ResultStatus: tt.resultStatus,
+				Rankings: []Ranking{
+					{PlayerID: &creator, DeckID: &deck.ID, Position: 1},
+					{PlayerID: &opponent, Position: 2},
+				},
+			})
+			target := game.Rankings[1].ID
+			events := []GameEvent{
+				{GameID: game.ID, EventType: EventTypeInit, TargetRankingID: &target, TargetLifeTotalAfter: 40},
+				{GameID: game.ID, EventType: EventTypeDecrement, DamageDelta: -40, TargetRankingID: &target},
+			}
+			if err := repo.DB.Create(&events).Error; err != nil {
+				t.Fatalf("failed to create events: %v", err)
+			}
+			if tt.resultStatus == ResultStatusConfirmed {
+				repo.DB.Model(&Deck{}).Where("id = ?", deck.ID).Updates(map[string]interface{}{"game_count": 1, "win_count": 1})
+			}
+
+			if err := repo.DeleteGame(game.ID); err != nil {
+				t.Fatalf("unexpected error deleting: %v", err)
+			}
+			if counted := reloadDeck(t, repo, deck.ID); counted.GameCount != 0 {
+				t.Errorf("expected the deleted game to be uncounted, got %d games", counted.GameCount)
+			}
+			deleted, total, err := repo.GetDeletedGames(creator, 10, 0)
+			if err != nil || total != 1 || len(deleted) != 1 || deleted[0].ID != game.ID || len(deleted[0].Rankings) != 2 {
+				t.Fatalf("expected the deleted game with its rankings in the trash, got %+v (%v)", deleted, err)
+			}
+			if _, total, _ := repo.GetDeletedGames(opponent, 10, 0); total != 0 {
+				t.Errorf("expected only the creator to see the deleted game, the opponent sees %d", total)
+			}
+
+			if err := repo.RestoreGame(game.ID); err != nil {
+				t.Fatalf("unexpected error restoring: %v", err)
+			}
+			restored, err := repo.GetGameWithEvents(game.ID)
+			if err != nil {
+				t.Fatalf("expected the game to be restored, got %v", err)
+			}
+			if len(restored.Rankings) != 2 || len(restored.GameEvents) != 2 {
+				t.Errorf("expected the rankings and events to be restored, got %d rankings and %d events", len(restored.Rankings), len(restored.GameEvents))
+			}
+			if counted := reloadDeck(t, repo, deck.ID); counted.GameCount != tt.expectedGames || counted.WinCount != tt.expectedGames {
+				t.Errorf("expected %d games and wins, got %d and %d", tt.expectedGames, counted.GameCount, counted.WinCount)
+			}
+			if _, total, _ := repo.GetDeletedGames(creator, 10, 0); total != 0 {
+				t.Errorf("expected the trash to be empty, got %d games", total)
+			}
+			if err := repo.RestoreGame(game.ID); err == nil || !strings.Contains(err.Error(), "not deleted") {
+				t.Errorf("expected a second restore to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRestoreMergedGame(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	survivor := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: []Ranking{{PlayerID: &creator}}})
+	duplicate := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: []Ranking{{PlayerID: &creator}}})
+
+	if _, err := repo.MergeGames(survivor.ID, duplicate.ID); err != nil {
+		t.Fatalf("unexpected error merging: %v", err)
+	}
+	if _, total, _ := repo.GetDeletedGames(creator, 10, 0); total != 0 {
+		t.Errorf("expected a merged game to be left out of the trash, got %d games", total)
+	}
+	if err := repo.RestoreGame(duplicate.ID); err == nil || !strings.Contains(err.Error(), "merged") {
+		t.Errorf("expected restoring a merged game to be rejected, got %v", err)
+	}
+	if _, err := repo.GetGameWithEvents(duplicate.ID); err == nil {
+		t.Error("expected the merged game to stay deleted")
+	}
+}
+
+func TestMergeGamesEvents(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	opponent := createTestPlayer(t, repo, "opponent")
+	survivor := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: []Ranking{{PlayerID: &creator}, {PlayerID: &opponent}}})
+	duplicate := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: []Ranking{{PlayerID: &creator}, {PlayerID: &opponent}, {}}})
+	survivorCreator, survivorOpponent := survivor.Rankings[0].ID, survivor.Rankings[1].ID
+	duplicateCreator, duplicateOpponent, guest := duplicate.Rankings[0].ID, duplicate.Rankings[1].ID, duplicate.Rankings[2].ID
+
+	// Both phones logged the creator's attack, only the duplicate knew about the guest
+	gameEvents := []GameEvent{
+		{GameID: survivor.ID, EventType: EventTypeInit, TargetRankingID: &survivorOpponent, TargetLifeTotalAfter: 40},
+		{GameID: survivor.ID, EventType: EventTypeDecrement, DamageDelta: -10, SourceRankingID: &survivorCreator, TargetRankingID: &survivorOpponent, TargetLifeTotalAfter: 30},
+		{GameID: duplicate.ID, EventType: EventTypeInit, TargetRankingID: &duplicateOpponent, TargetLifeTotalAfter: 40},
+		{GameID: duplicate.ID, EventType: EventTypeInit, TargetRankingID: &guest, TargetLifeTotalAfter: 40},
+		{GameID: duplicate.ID, EventType: EventTypeDecrement, DamageDelta: -10, SourceRankingID: &duplicateCreator, TargetRankingID: &duplicateOpponent, TargetLifeTotalAfter: 30},
+		{GameID: duplicate.ID, EventType: EventTypeDecrement, DamageDelta: -5, SourceRankingID: &guest, TargetRankingID: &duplicateOpponent, TargetLifeTotalAfter: 25},
+		{GameID: duplicate.ID, EventType: EventTypeDecrement, DamageDelta: -3, SourceRankingID: &duplicateCreator, TargetRankingID: &guest, TargetLifeTotalAfter: 37},
+	}
+	if err := repo.DB.Create(&gameEvents).Error; err != nil {
+		t.Fatalf("failed to create events: %v", err)
+	}
+
+	if _, err := repo.MergeGames(survivor.ID, duplicate.ID); err != nil {
+		t.Fatalf("unexpected error merging: %v", err)
+	}
+	merged, err := repo.GetGameWithEvents(survivor.ID)
+	if err != nil {
+		t.Fatalf("unexpected error loading the survivor: %v", err)
+	}
+	if len(merged.Rankings) != 3 || len(merged.GameEvents) != 5 {
+		t.Fatalf("expected the guest and its events to be merged, got %d rankings and %d events", len(merged.Rankings), len(merged.GameEvents))
+	}
+	life := make(map[uint]int)
+	for _, event := range merged.GameEvents {
+		if event.SourceRankingID != nil && *event.SourceRankingID == duplicateCreator {
+			t.Errorf("expected event %d to point to the survivor's creator", event.ID)
+		}
+		life[*event.TargetRankingID] = event.TargetLifeTotalAfter
+	}
+	if life[survivorOpponent] != 25 {
+		t.Errorf("expected the opponent to take the shared attack once, got %d life", life[survivorOpponent])
+	}
+	if life[guest] != 37 {
+		t.Errorf("expected the guest to keep its life total, got %d", life[guest])
+	}
+}
+
+// recordingEventBus keeps the published events
+type recordingEventBus struct {
+	published []events.Event
+}
+
+func (b *recordingEventBus) Publish(event events.Event) {
+	b.published = append(b.published, event)
+}
+
+func TestRestoreGameHandlers(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	opponent := createTestPlayer(t, repo, "opponent")
+	game := createTestGame(t, repo, Game{
+		CreatorID:    &creator,
+		Finished:     true,
+		ResultStatus: ResultStatusConfirmed,
+		Rankings:     []Ranking{{PlayerID: &creator, Position: 1}, {PlayerID: &opponent, Position: 2}},
+	})
+	if err := repo.DeleteGame(game.ID); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	bus := &recordingEventBus{}
+	mux := http.NewServeMux()
+	NewService(repo, nil, bus).RegisterRoutes(mux)
+	handler := middleware.MockFirebaseAuthMw(mux)
+	request := func(method, path, userID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if userID != "" {
+			r.Header.Set("Authorization", "Bearer "+userID)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	restorePath := fmt.Sprintf("/game/v1/games/%d/restore", game.ID)
+
+	if w := request(http.MethodGet, "/game/v1/games/trash", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for the trash without a user, got %d", w.Code)
+	}
+	w := request(http.MethodGet, "/game/v1/games/trash", creator)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), fmt.Sprintf(`"id":%d`, game.ID)) {
+		t.Errorf("expected the deleted game in the creator's trash, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := request(http.MethodPost, restorePath, opponent); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a participant who isn't the creator, got %d", w.Code)
+	}
+	if len(bus.published) != 0 {
+		t.Fatalf("expected nothing to be published for a rejected restore, got %+v", bus.published)
+	}
+
+	if w := request(http.MethodPost, restorePath, creator); w.Code != http.StatusOK {
+		t.Fatalf("expected the creator to restore the game, got %d %s", w.Code, w.Body.String())
+	}
+	if len(bus.published) != 1 {
+		t.Fatalf("expected one published event, got %+v", bus.published)
+	}
+	restored, ok := bus.published[0].(events.GameRestoredEvent)
+	if !ok || restored.GameID != game.ID || !restored.Confirmed || len(restored.PlayerIDs) != 2 || restored.DeletedAt.IsZero() {
+		t.Errorf("expected a game.restored event of the confirmed game, got %+v", bus.published[0])
+	}
+
+	if w := request(http.MethodPost, restorePath, creator); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a game that isn't deleted, got %d", w.Code)
+	}
+}
+
+func TestMergeAndDiscardRights(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	opponent := createTestPlayer(t, repo, "opponent")
+	rankings := func() []Ranking { return []Ranking{{PlayerID: &creator}, {PlayerID: &opponent}} }
+	survivor := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: rankings()})
+	duplicate := createTestGame(t, repo, Game{CreatorID: &opponent, Rankings: rankings()})
+
+	mux := http.NewServeMux()
+	NewService(repo, nil, &recordingEventBus{}).RegisterRoutes(mux)
+	handler := middleware.MockFirebaseAuthMw(mux)
+	request := func(path, body, userID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+userID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Both play both games, only the opponent created the duplicate
+	discardPath := fmt.Sprintf("/game/v1/games/%d/discard", duplicate.ID)
+	if w := request(discardPath, fmt.Sprintf(`{"duplicate_of":%d}`, survivor.ID), creator); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for discarding a game the user didn't create, got %d", w.Code)
+	}
+	mergePath := fmt.Sprintf("/game/v1/games/%d/merge", survivor.ID)
+	if w := request(mergePath, fmt.Sprintf(`{"duplicate_game_id":%d}`, duplicate.ID), creator); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for merging away a game the user didn't create, got %d", w.Code)
+	}
+	if _, err := repo.GetGameWithEvents(duplicate.ID); err != nil {
+		t.Fatalf("expected the duplicate to be kept, got %v", err)
+	}
+
+	mergePath = fmt.Sprintf("/game/v1/games/%d/merge", duplicate.ID)
+	if w := request(mergePath, fmt.Sprintf(`{"duplicate_game_id":%d}`, survivor.ID), creator); w.Code != http.StatusOK {
+		t.Errorf("expected the creator of the merged away game to merge it, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSearchGamesByCommanderOracleID(t *testing.T) {
+	repo := testRepository(t)
+	creator := createTestPlayer(t, repo, "creator")
+	oracleID := fmt.Sprintf("o-atraxa-%d", time.Now().UnixNano())
+	canonical := "Atraxa, Praetors' Voice " + oracleID
+	if err := repo.SaveCommanders([]Commander{{OracleID: oracleID, Name: canonical}}); err != nil {
+		t.Fatalf("failed to save commander: %v", err)
+	}
+	ranking := func(commander string, oracleID *string) Ranking {
+		return Ranking{PlayerID: &creator, DeckEmbedded: SimpleDeck{Commander: commander, CommanderOracleID: oracleID}}
+	}
+	// Resolved games keep the spelling they were logged with, the backfill only rewrites unresolved ones
+	canonicalGame := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: []Ranking{ranking(canonical, &oracleID)}})
+	spelledGame := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: []Ranking{ranking("atraxa", &oracleID)}})
+	unresolvedGame := createTestGame(t, repo, Game{CreatorID: &creator, Rankings: []Ranking{ranking("Atraxa", nil)}})
+
+	tests := []struct {
+		name     string
+		filter   GameFilter
+		expected []uint
+	}{
+		{
+			name:     "any commander",
+			filter:   GameFilter{AllPlayers: []string{creator}, Commanders: []string{canonical}},
+			expected: []uint{canonicalGame.ID, spelledGame.ID},
+		},
+		{
+			name:     "all commanders",
+			filter:   GameFilter{AllPlayers: []string{creator}, AllCommanders: []string{canonical}},
+			expected: []uint{canonicalGame.ID, spelledGame.ID},
+		},
+		{
+			name:     "unknown commander by name",
+			filter:   GameFilter{AllPlayers: []string{creator}, Commanders: []string{"Atraxa"}},
+			expected: []uint{unresolvedGame.ID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			games, total, err := repo.SearchGamesWithFilters(tt.filter, 10, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids := make(map[uint]bool)
+			for _, game := range games {
+				ids[game.ID] = true
+			}
+			if int(total) != len(tt.expected) || len(ids) != len(tt.expected) {
+				t.Fatalf("expected games %v, got %d games %v", tt.expected, total, ids)
+			}
+			for _, id := range tt.expected {
+				if !ids[id] {
+					t.Errorf("expected game %d, got %v", id, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestParticipantNames(t *testing.T) {
+	repo := testRepository(t)
+	loaded := createTestPlayer(t, repo, "loaded")
+	assigned := createTestPlayer(t, repo, "assigned")
+	unknown := "unknown-player"
+	rankings := []Ranking{
+		{PlayerID: &loaded, Player: &Player{FirebaseID: loaded, Name: "Loaded"}},
+		{PlayerID: &assigned},
+		{PlayerID: &unknown},
+		{},
+	}
+
+	names, err := (&Service{Repository: repo}).participantNames(rankings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{loaded: "Loaded", assigned: assigned}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+	for id, name := range expected {
+		if names[id] != name {
+			t.Errorf("expected %s to be named %q, got %q", id, name, names[id])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"log"
+	"time"
+
+	"mtgtracker/internal/events"
+)
+
+// Status of the result of a finished game, empty while the game is unfinished
+const (
+	ResultStatusPending   = "pending"   // Waiting for the participants to confirm the final positions
+	ResultStatusConfirmed = "confirmed" // Counts towards statistics and deck counters
+	ResultStatusDisputed  = "disputed"  // A participant disagreed, the creator has to correct the positions
+)
+
+// resultConfirmationTimeout is how long a pending result waits for confirmations before it is confirmed anyway
+const resultConfirmationTimeout = 48 * time.Hour
+
+// resultCheckInterval is how often pending results are checked for the timeout
+const resultCheckInterval = 10 * time.Minute
+
+// confirmationQuorum is the number of confirmations that confirm a result: a majority of the registered players
+func confirmationQuorum(game *Game) int {
+	players := 0
+	for _, ranking := range game.Rankings {
+		if ranking.PlayerID != nil {
+			players++
+		}
+	}
+	return players/2 + 1
+}
+
+// decideResult derives the status of a finished game's result from its votes
+// A single dispute disputes the result, disputed results are never confirmed by the timeout
+func decideResult(game *Game, now time.Time) string {
+	if !game.Finished {
+		return ""
+	}
+	if game.ResultStatus == ResultStatusConfirmed {
+		return ResultStatusConfirmed
+	}
+
+	confirmations := 0
+	for _, vote := range game.ResultVotes {
+		if !vote.Confirmed {
+			return ResultStatusDisputed
+		}
+		confirmations++
+	}
+	if game.ResultStatus == ResultStatusDisputed {
+		return ResultStatusDisputed
+	}
+	if confirmations >= confirmationQuorum(game) {
+		return ResultStatusConfirmed
+	}
+	if game.EndDate != nil && now.Sub(*game.EndDate) >= resultConfirmationTimeout {
+		return ResultStatusConfirmed
+	}
+	return ResultStatusPending
+}
+
+// evaluateResult confirms or disputes the result of a finished game once its votes or the timeout decide it
+// Confirming a result counts it for the decks and publishes the game finished event, exactly once
+// The user whose vote or update decided the result is recorded as the actor, none for the timeout
+func (s *Service) evaluateResult(gameID uint, userID string) error {
+	game, err := s.Repository.GetGameWithEvents(gameID)
+	if err != nil {
+		return err
+	}
+
+	switch decideResult(game, time.Now()) {
+	case ResultStatusConfirmed:
+		confirmed, err := s.Repository.ConfirmGameResult(game.ID)
+		if err != nil || !confirmed {
+			return err
+		}
+		log.Printf("Result of game %d confirmed", game.ID)
+		s.recordAudit(
+			auditAction(userID, game.ID, AuditEntityGame, game.ID, AuditActionUpdated, map[string]AuditChange{
+				"result_status": {Before: game.ResultStatus, After: ResultStatusConfirmed},
+			}),
+			statsAuditEntry(userID, game.ID, registeredPlayers(game)),
+		)
+		s.eventBus.Publish(events.GameFinishedEvent{
+			GameID:     game.ID,
+			RankingIDs: rankingIDsOf(game),
+			Date:       time.Now(),
+		})
+	case ResultStatusDisputed:
+		disputed, err := s.Repository.DisputeGameResult(game.ID)
+		if err != nil || !disputed {
+			return err
+		}
+		log.Printf("Result of game %d disputed", game.ID)
+		s.recordAudit(auditAction(userID, game.ID, AuditEntityGame, game.ID, AuditActionUpdated, map[string]AuditChange{
+			"result_status": {Before: game.ResultStatus, After: ResultStatusDisputed},
+		}))
+		creatorID := ""
+		if game.CreatorID != nil {
+			creatorID = *game.CreatorID
+		}
+		s.eventBus.Publish(events.GameResultDisputedEvent{
+			GameID:    game.ID,
+			CreatorID: creatorID,
+			Date:      time.Now(),
+		})
+	}
+	return nil
+}
+
+// StartResultConfirmation periodically confirms the pending results that waited longer than the timeout
+func (s *Service) StartResultConfirmation() {
+	go func() {
+		ticker := time.NewTicker(resultCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.confirmExpiredResults()
+		}
+	}()
+}
+
+func (s *Service) confirmExpiredResults() {
+	gameIDs, err := s.Repository.GetPendingResultGameIDs(time.Now().Add(-resultConfirmationTimeout))
+	if err != nil {
+		log.Printf("Failed to fetch pending results: %v", err)
+		return
+	}
+	for _, gameID := range gameIDs {
+		if err := s.evaluateResult(gameID, ""); err != nil {
+			log.Printf("Failed to confirm the result of game %d: %v", gameID, err)
+		}
+	}
+}
+
+// rankingIDsOf returns the IDs of a game's rankings
+func rankingIDsOf(game *Game) []uint {
+	rankingIDs := make([]uint, len(game.Rankings))
+	for i, ranking := range game.Rankings {
+		rankingIDs[i] = ranking.ID
+	}
+	return rankingIDs
+}
@@ -8,7 +8,9 @@ import (
 	"mtgtracker/internal/events"
 	"mtgtracker/internal/middleware"
 	"mtgtracker/internal/pagination"
+	"mtgtracker/pkg/cards"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,9 +24,15 @@ type EventBus interface {
 	Publish(event events.Event)
 }
 
+// CardDatabase looks up cards by name, see pkg/cards
+type CardDatabase interface {
+	Get(name string) (*cards.Card, bool)
+}
+
 type Service struct {
 	Repository *Repository
 	Storage    Storage
+	Cards      CardDatabase // Optional: resolves commanders, fills in new decks and checks the cards of descriptions
 	eventBus   EventBus
 }
 
@@ -51,16 +59,42 @@ func (s *Service) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /player/v1/players/{playerId}/decks", s.GetPlayerDecks)
 	mux.HandleFunc("GET /player/v1/players/{playerId}/games", s.GetPlayerGames)
 	mux.HandleFunc("POST /deck/v1/decks", s.CreateDeck)
+	mux.HandleFunc("PUT /deck/v1/decks/{deckId}", s.UpdateDeck)
+	mux.HandleFunc("DELETE /deck/v1/decks/{deckId}", s.DeleteDeck)
+	mux.HandleFunc("POST /deck/v1/decks/{deckId}/retire", s.RetireDeck)
+	mux.HandleFunc("POST /deck/v1/decks/{deckId}/unretire", s.UnretireDeck)
+	mux.HandleFunc("GET /deck/v1/decks/{deckId}", s.GetDeck)
+	mux.HandleFunc("GET /deck/v1/decks/{deckId}/versions", s.GetDeckVersions)
+	mux.HandleFunc("GET /game/v1/formats", s.GetFormats)
 	mux.HandleFunc("POST /game/v1/games", s.CreateGame)
 	mux.HandleFunc("GET /game/v1/games", s.GetGames)
 	mux.HandleFunc("POST /game/v1/games/search", s.SearchGamesEndpoint)
 	mux.HandleFunc("GET /game/v1/games/active", s.GetActiveGame)
+	mux.HandleFunc("GET /game/v1/games/disputed", s.GetDisputedGames)
+	mux.HandleFunc("GET /game/v1/games/trash", s.GetDeletedGames)
 	mux.HandleFunc("PUT /game/v1/games/{gameId}", s.UpdateGame)
 	mux.HandleFunc("GET /game/v1/games/{gameId}", s.GetGame)
 	mux.HandleFunc("DELETE /game/v1/games/{gameId}", s.DeleteGame)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/restore", s.RestoreGame)
 	mux.HandleFunc("PUT /ranking/v1/rankings/{rankingId}", s.UpdateRankingEndpoint)
 	mux.HandleFunc("DELETE /ranking/v1/rankings/{rankingId}", s.DeleteRanking)
+	mux.HandleFunc("POST /ranking/v1/rankings/{rankingId}/claims", s.ProposeRankingClaim)
+	mux.HandleFunc("GET /ranking/v1/claims", s.GetMyRankingClaims)
+	mux.HandleFunc("POST /ranking/v1/claims/{claimId}/accept", s.AcceptRankingClaim)
+	mux.HandleFunc("POST /ranking/v1/claims/{claimId}/reject", s.RejectRankingClaim)
 	mux.HandleFunc("POST /game/v1/games/{gameId}/events", s.AddGameEvent)
+	mux.HandleFunc("DELETE /game/v1/games/{gameId}/events/{eventId}", s.VoidGameEvent)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/events/{eventId}/redo", s.RedoGameEvent)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/pause", s.PauseGame)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/resume", s.ResumeGame)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/result/confirm", s.ConfirmGameResult)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/result/dispute", s.DisputeGameResult)
+	mux.HandleFunc("GET /game/v1/games/{gameId}/duplicates", s.GetGameDuplicates)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/merge", s.MergeGame)
+	mux.HandleFunc("POST /game/v1/games/{gameId}/discard", s.DiscardGame)
+	mux.HandleFunc("GET /game/v1/games/{gameId}/history", s.GetGameHistory)
+	mux.HandleFunc("GET /game/v1/join/{code}", s.GetGameByJoinCode)
+	mux.HandleFunc("POST /game/v1/join/{code}", s.JoinGame)
 }
 
 func (s *Service) GetPlayerByFirebaseID(firebaseID string) (*Player, error) {
@@ -71,6 +105,10 @@ func (s *Service) GetGameByID(gameID uint) (*Game, error) {
 	return s.Repository.GetGameWithEvents(gameID)
 }
 
+func (s *Service) GetConfirmedPlayerGames(playerID string) ([]Game, error) {
+	return s.Repository.GetConfirmedPlayerGames(playerID)
+}
+
 func (s *Service) GetMyPlayer(w http.ResponseWriter, r *http.Request) {
 	// Get the user ID from the context
 	userID := middleware.GetUserID(r)
@@ -172,6 +210,23 @@ func (s *Service) GetPlayer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// GetFormats lists the supported formats and their rules
+func (s *Service) GetFormats(w http.ResponseWriter, r *http.Request) {
+	formats := make([]FormatRules, 0, len(Formats))
+	for _, rules := range Formats {
+		formats = append(formats, rules)
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		return formats[i].Format < formats[j].Format
+	})
+
+	err := json.NewEncoder(w).Encode(formats)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
 func (s *Service) CreateGame(w http.ResponseWriter, r *http.Request) {
 	// Get the user ID from the context
 	userID := middleware.GetUserID(r)
@@ -191,50 +246,55 @@ func (s *Service) CreateGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(request.Rankings) < 2 {
-		http.Error(w, "At least two rankings are required", http.StatusBadRequest)
+	rules, ok := GetFormatRules(request.Format)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown format: %s", request.Format), http.StatusBadRequest)
 		return
 	}
-
-	// Validate rankings have either deck_id or inline deck
-	for i, rank := range request.Rankings {
-		if rank.DeckID == nil && rank.Deck == nil {
-			http.Error(w, "Each ranking must have either deck_id or deck provided", http.StatusBadRequest)
-			return
-		}
-		if rank.DeckID != nil && rank.Deck != nil {
-			http.Error(w, "Each ranking must have either deck_id OR deck, not both", http.StatusBadRequest)
-			return
-		}
-		// If inline deck is provided, validate required fields
-		if rank.Deck != nil && rank.Deck.Commander == "" {
-			http.Error(w, fmt.Sprintf("Ranking %d: deck.commander is required", i), http.StatusBadRequest)
-			return
-		}
+	if err := validateRankingsForFormat(rules, request.Rankings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.Duration != nil && *request.Duration < 0 {
+		http.Error(w, "duration can't be negative", http.StatusBadRequest)
+		return
+	}
+	if request.Clock != nil && (request.Clock.InitialSeconds <= 0 || request.Clock.IncrementSeconds < 0) {
+		http.Error(w, "clock.initial_seconds must be positive and clock.increment_seconds can't be negative", http.StatusBadRequest)
+		return
 	}
 
 	// Call the repository to insert the game
 	var rankings []Ranking
+	var commanders []Commander
 	for _, rank := range request.Rankings {
 		toAdd := Ranking{
 			PlayerID: rank.PlayerID,
 			Position: 0,
 			DeckID:   rank.DeckID, // Optional deck reference
+			Team:     rank.Team,
 		}
 
 		// If inline deck is provided (and no deck_id), use embedded deck
 		if rank.Deck != nil && rank.DeckID == nil {
 			toAdd.DeckEmbedded = convertSimpleDeck(*rank.Deck)
+			commanders = append(commanders, s.completeSimpleDeck(&toAdd.DeckEmbedded)...)
 		}
 
 		rankings = append(rankings, toAdd)
 	}
-	game, err := s.Repository.InsertGame(user, request.Comments, request.Image, request.Date, request.Finished, rankings)
+	if err := s.Repository.SaveCommanders(commanders); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	game, err := s.Repository.InsertGame(user, rules.Format, request.Comments, request.Image, request.Date, request.Duration, request.Finished, rankings, request.Clock)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	s.recordAudit(gameAuditEntries(user.FirebaseID, nil, game)...)
+
 	// Publish game created event
 	rankingIDs := make([]uint, len(game.Rankings))
 	for i, ranking := range game.Rankings {
@@ -248,6 +308,7 @@ func (s *Service) CreateGame(w http.ResponseWriter, r *http.Request) {
 	})
 
 	result := s.ConvertGameToDto(game, false)
+	result.PossibleDuplicateIDs = s.findDuplicateIDs(game)
 	err = json.NewEncoder(w).Encode(result)
 	if err != nil {
 		log.Println("Error encoding response:", err)
@@ -267,11 +328,18 @@ func (s *Service) AddGameEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// fetch the game and check if source and target rankings are valid
-	_, err = s.Repository.GetGameWithEvents(uint(gameId))
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+	if err := validateGameEventRequest(req, game); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	var uploadImgUrl string
 	// If this is an image event, generate a presigned upload URL
@@ -289,6 +357,11 @@ func (s *Service) AddGameEvent(w http.ResponseWriter, r *http.Request) {
 		uploadImgUrl = uploadURL
 	}
 
+	if req.EventType == EventTypeTurnPass {
+		s.passTurn(w, req, game, middleware.GetUserID(r))
+		return
+	}
+
 	// Insert the event using the repository
 	event, err := s.Repository.InsertGameEvent(
 		uint(gameId), req.EventType,
@@ -296,11 +369,42 @@ func (s *Service) AddGameEvent(w http.ResponseWriter, r *http.Request) {
 		req.SourceRankingId, req.TargetRankingId,
 		strings.Split(uploadImgUrl, "?")[0],
 		req.Comment,
+		req.CommanderIndex,
+		req.CounterType,
 	)
+	var conflict *LifeTotalConflictError
+	if errors.As(err, &conflict) {
+		w.WriteHeader(http.StatusConflict)
+		err = json.NewEncoder(w).Encode(LifeTotalConflictResponse{
+			Error:                  conflict.Error(),
+			RankingID:              conflict.RankingID,
+			CurrentLifeTotal:       conflict.CurrentLifeTotal,
+			ExpectedLifeTotalAfter: conflict.ExpectedLifeTotalAfter,
+		})
+		if err != nil {
+			log.Println("Error encoding response:", err)
+		}
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if entry, ok := newAuditEntry(middleware.GetUserID(r), event.GameID, AuditEntityGameEvent, event.ID, nil, event); ok {
+		s.recordAudit(entry)
+	}
+
+	if err := s.syncRankingStates(event.GameID, middleware.GetUserID(r)); err != nil {
+		log.Printf("Failed to update eliminations for game %d: %v", event.GameID, err)
+		// Don't fail the event insert if the elimination update fails
+	}
+	s.eventBus.Publish(events.GameEventAddedEvent{
+		GameID:      event.GameID,
+		GameEventID: event.ID,
+		EventType:   event.EventType,
+		Date:        time.Now(),
+	})
+
 	eventDto := convertGameEvent(event, uploadImgUrl)
 
 	err = json.NewEncoder(w).Encode(eventDto)
@@ -309,313 +413,1549 @@ func (s *Service) AddGameEvent(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Service) GetGame(w http.ResponseWriter, r *http.Request) {
-	gameIdStr := r.PathValue("gameId")
-	gameId, err := strconv.Atoi(gameIdStr)
-	if err != nil {
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
-	}
-	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+// passTurn records a turn pass; the turn number and duration are derived by the server
+func (s *Service) passTurn(w http.ResponseWriter, req GameEventRequest, game *Game, userID string) {
+	event, err := s.Repository.InsertTurnPassEvent(game.ID, req.SourceRankingId, *req.TargetRankingId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	// convert the game to a DTO
-	result := s.ConvertGameToDto(game, true)
-	err = json.NewEncoder(w).Encode(result)
+	if entry, ok := newAuditEntry(userID, game.ID, AuditEntityGameEvent, event.ID, nil, event); ok {
+		s.recordAudit(entry)
+	}
+
+	// The chess clock of the ranking whose turn ended may have run out
+	if err := s.syncRankingStates(game.ID, userID); err != nil {
+		log.Printf("Failed to update rankings for game %d: %v", game.ID, err)
+	}
+
+	s.eventBus.Publish(events.GameEventAddedEvent{
+		GameID:      event.GameID,
+		GameEventID: event.ID,
+		EventType:   event.EventType,
+		Date:        time.Now(),
+	})
+
+	err = json.NewEncoder(w).Encode(convertGameEvent(event, ""))
 	if err != nil {
 		log.Println("Error encoding response:", err)
 	}
 }
 
-func (s *Service) DeleteGame(w http.ResponseWriter, r *http.Request) {
-	gameIdStr := r.PathValue("gameId")
-	gameId, err := strconv.Atoi(gameIdStr)
-	if err != nil {
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
-		return
+// validateGameEventRequest checks that the rankings referenced by an event belong to the game
+func validateGameEventRequest(req GameEventRequest, game *Game) error {
+	inGame := func(rankingID *uint) bool {
+		if rankingID == nil {
+			return true
+		}
+		for _, ranking := range game.Rankings {
+			if ranking.ID == *rankingID {
+				return true
+			}
+		}
+		return false
+	}
+	if !inGame(req.SourceRankingId) || !inGame(req.TargetRankingId) {
+		return errors.New("source and target rankings must belong to the game")
 	}
 
-	// Fetch game data before deletion to include in event
-	game, err := s.Repository.GetGameWithEvents(uint(gameId))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if err := validateCounterType(req.EventType, req.CounterType); err != nil {
+		return err
 	}
+	if req.CounterType != "" && req.TargetRankingId == nil {
+		return errors.New("counter events require a target ranking")
+	}
+
+	if req.EventType == EventTypeTurnPass {
+		if req.TargetRankingId == nil {
+			return errors.New("a turn pass requires the target ranking whose turn starts")
+		}
+		if game.Finished {
+			return errors.New("turns can't be passed in a finished game")
+		}
+	}
+
+	if req.EventType == EventTypeCommanderDamage {
+		rules := rulesForGame(game)
+		if rules.CommanderDamage == 0 {
+			return fmt.Errorf("%s has no commander damage", rules.Format)
+		}
+		if req.SourceRankingId == nil || req.TargetRankingId == nil {
+			return errors.New("commander damage requires a source and target ranking")
+		}
+		if *req.SourceRankingId == *req.TargetRankingId {
+			return errors.New("a commander cannot deal commander damage to its own ranking")
+		}
+		if req.CommanderIndex < 0 || req.CommanderIndex > 1 {
+			return errors.New("commander_index must be 0 (commander) or 1 (partner)")
+		}
+	}
+	return nil
+}
 
-	// Call the repository to delete the game
-	err = s.Repository.DeleteGame(uint(gameId))
+// syncRankingStates replays the events of a game and stores the derived elimination and chess clock on each ranking
+// Once at most one ranking is left standing, the final positions are applied and the game is finished
+// The changes are recorded for actorID, the user whose change of the game's events caused them
+func (s *Service) syncRankingStates(gameID uint, actorID string) error {
+	game, err := s.Repository.GetGameWithEvents(gameID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	// Publish game deleted event with player/ranking info
-	rankingIDs := make([]uint, len(game.Rankings))
-	playerIDs := make([]string, 0, len(game.Rankings))
+	rules := rulesForGame(game)
+	states := deriveRankingStates(game.GameEvents, rules, lifeSharingRankings(game.Rankings, rules))
+	turns := deriveTurnState(game, time.Now())
+	if game.ClockEliminateOnTimeout {
+		applyClockTimeouts(states, turns)
+	}
+
+	before := *game
+	before.Rankings = append([]Ranking(nil), game.Rankings...)
+	changed := false
 	for i, ranking := range game.Rankings {
-		rankingIDs[i] = ranking.ID
-		if ranking.PlayerID != nil {
-			playerIDs = append(playerIDs, *ranking.PlayerID)
+		if remaining, ok := turns.ClockRemaining[ranking.ID]; ok && (ranking.ClockRemainingSeconds == nil || *ranking.ClockRemainingSeconds != remaining) {
+			if _, err := s.Repository.UpdateRanking(ranking.ID, map[string]interface{}{"clock_remaining_seconds": remaining}); err != nil {
+				return err
+			}
+			changed = true
+		}
+
+		state, ok := states[ranking.ID]
+		if !ok {
+			state = newRankingState()
+		}
+		if !eliminationChanged(&ranking, state) {
+			continue
 		}
+		if err := s.Repository.UpdateRankingElimination(ranking.ID, state); err != nil {
+			return err
+		}
+		changed = true
+		game.Rankings[i].Eliminated = state.Eliminated
+		game.Rankings[i].EliminatedAt = state.EliminatedAt
+	}
+	if changed {
+		after, err := s.Repository.GetGameWithEvents(gameID)
+		if err != nil {
+			return err
+		}
+		s.recordAudit(gameAuditEntries(actorID, &before, after)...)
 	}
 
-	s.eventBus.Publish(events.GameDeletedEvent{
-		GameID:     uint(gameId),
-		RankingIDs: rankingIDs,
-		PlayerIDs:  playerIDs,
-		Date:       time.Now(),
-	})
+	if game.Finished {
+		return nil
+	}
+	ordered, decided := proposeFinalPositions(game.Rankings)
+	if !decided {
+		return nil
+	}
 
-	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Game %d decided by eliminations, finishing automatically", gameID)
+	newRankings := make([]Ranking, len(ordered))
+	for i, ranking := range ordered {
+		newRankings[i] = Ranking{Model: ranking.Model, Position: ranking.Position}
+	}
+	finished := true
+	// The game was read without a lock, a manual finish in the meantime wins
+	_, err = s.updateGame(gameID, newRankings, &finished, nil, actorID, true)
+	return err
 }
 
-func (s *Service) GetGames(w http.ResponseWriter, r *http.Request) {
-	p := pagination.ParsePagination(r)
+// clockCheckInterval is how often running chess clocks are checked for players who ran out of time without passing
+const clockCheckInterval = 15 * time.Second
 
-	// Call the repository to get the games
-	games, total, err := s.Repository.GetGames(p.PerPage, p.Offset())
+// StartClockTimeouts periodically eliminates the players of timed games whose clock ran out during their turn
+func (s *Service) StartClockTimeouts() {
+	go func() {
+		ticker := time.NewTicker(clockCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.applyExpiredClocks()
+		}
+	}()
+}
+
+func (s *Service) applyExpiredClocks() {
+	gameIDs, err := s.Repository.GetClockTimeoutGameIDs()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Printf("Failed to fetch timed games: %v", err)
 		return
 	}
-
-	items := make([]GameResponse, 0, len(games))
-	for _, game := range games {
-		items = append(items, s.ConvertGameToDto(&game, true))
+	for _, gameID := range gameIDs {
+		if err := s.syncRankingStates(gameID, ""); err != nil {
+			log.Printf("Failed to check the clocks of game %d: %v", gameID, err)
+		}
 	}
+}
 
-	result := pagination.PaginatedResult[GameResponse]{
-		Items:      items,
-		TotalCount: total,
-		Page:       p.Page,
-		PerPage:    p.PerPage,
+// eliminationChanged reports whether the stored elimination of a ranking differs from the derived one
+func eliminationChanged(ranking *Ranking, state *rankingState) bool {
+	if ranking.Eliminated != state.Eliminated || ranking.EliminationReason != state.EliminationReason {
+		return true
 	}
-
-	err = json.NewEncoder(w).Encode(result)
-	if err != nil {
-		log.Println("Error encoding response:", err)
+	if (ranking.EliminatedByRankingID == nil) != (state.EliminatedByRankingID == nil) {
+		return true
 	}
+	if ranking.EliminatedByRankingID != nil && *ranking.EliminatedByRankingID != *state.EliminatedByRankingID {
+		return true
+	}
+	if (ranking.EliminatedAt == nil) != (state.EliminatedAt == nil) {
+		return true
+	}
+	return ranking.EliminatedAt != nil && !ranking.EliminatedAt.Equal(*state.EliminatedAt)
 }
 
-func (s *Service) GetActiveGame(w http.ResponseWriter, r *http.Request) {
+// VoidGameEvent marks a game event as undone so it is ignored when deriving life totals
+func (s *Service) VoidGameEvent(w http.ResponseWriter, r *http.Request) {
+	s.setGameEventVoided(w, r, true)
+}
+
+// RedoGameEvent restores a previously voided game event
+func (s *Service) RedoGameEvent(w http.ResponseWriter, r *http.Request) {
+	s.setGameEventVoided(w, r, false)
+}
+
+func (s *Service) setGameEventVoided(w http.ResponseWriter, r *http.Request, void bool) {
 	userID := middleware.GetUserID(r)
 	if userID == "" {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	game, err := s.Repository.GetActiveGameForPlayer(userID)
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	if game == nil {
-		w.WriteHeader(http.StatusNoContent)
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
 		return
 	}
-
-	result := s.ConvertGameToDto(game, true)
-	err = json.NewEncoder(w).Encode(result)
-	if err != nil {
-		log.Println("Error encoding response:", err)
-	}
-}
-
-// UpdateGame updates an existing game by its ID.
-// You may need to adjust the request struct and repository method as needed.
-func (s *Service) UpdateGame(w http.ResponseWriter, r *http.Request) {
-	gameIdStr := r.PathValue("gameId")
-	gameId, err := strconv.Atoi(gameIdStr)
+	eventId, err := strconv.Atoi(r.PathValue("eventId"))
 	if err != nil {
-		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
 		return
 	}
 
-	var request UpdateGameRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	// find the rankings for that game
-	rankings := []Ranking{}
-	err = s.Repository.DB.Model(&Ranking{}).Where("game_id = ?", gameId).Find(&rankings).Error
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	// Validate and reorder rankings
-	newRankings, err := validateAndReorderRankings(request.Rankings, rankings)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !s.authorizeGame(w, r, game) {
 		return
 	}
 
-	// Call the repository to update the game (implement UpdateGame in your repository)
-	updatedGame, err := s.Repository.UpdateGame(uint(gameId), newRankings, request.Finished)
+	var voidedBy *string
+	if void {
+		voidedBy = &userID
+	}
+	event, err := s.Repository.SetGameEventVoided(game.ID, uint(eventId), voidedBy)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case strings.Contains(err.Error(), "cannot be voided"):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case strings.Contains(err.Error(), "voided"):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
-	if updatedGame == nil {
-		http.Error(w, "Game not found", http.StatusNotFound)
-		return
+	// The undo also changes the life totals stored on the target's later events
+	if updatedGame, err := s.Repository.GetGameWithEvents(game.ID); err == nil {
+		s.recordAudit(gameEventAuditEntries(userID, game.GameEvents, updatedGame.GameEvents)...)
+	} else {
+		log.Printf("Failed to record the events changed in game %d: %v", game.ID, err)
 	}
 
-	// Publish game finished event if game was just finished
-	if request.Finished != nil && *request.Finished {
-		rankingIDs := make([]uint, len(updatedGame.Rankings))
-		for i, ranking := range updatedGame.Rankings {
-			rankingIDs[i] = ranking.ID
-		}
-		s.eventBus.Publish(events.GameFinishedEvent{
-			GameID:     updatedGame.ID,
-			RankingIDs: rankingIDs,
-			Date:       time.Now(),
-		})
+	if err := s.syncRankingStates(game.ID, userID); err != nil {
+		log.Printf("Failed to update eliminations for game %d: %v", game.ID, err)
 	}
 
-	result := s.ConvertGameToDto(updatedGame, false)
-	err = json.NewEncoder(w).Encode(result)
+	s.eventBus.Publish(events.GameEventVoidedEvent{
+		GameID:      game.ID,
+		GameEventID: event.ID,
+		Voided:      void,
+		PlayerID:    userID,
+		Date:        time.Now(),
+	})
+
+	err = json.NewEncoder(w).Encode(convertGameEvent(event, ""))
 	if err != nil {
 		log.Println("Error encoding response:", err)
 	}
 }
 
-// validateAndReorderRankings validates that the request rankings match existing rankings
-// and returns them with sequential positions (1, 2, 3, etc.) based on request order
-func validateAndReorderRankings(requestRankings []UpdateRanking, existingRankings []Ranking) ([]Ranking, error) {
-	// Validate that request rankings match existing rankings count
-	if len(requestRankings) != len(existingRankings) {
-		return nil, errors.New("rankings count must match existing rankings")
-	}
-
-	// Create map of existing rankings by RankingID string value for quick lookup
-	existingMap := make(map[uint]Ranking)
-	for _, ranking := range existingRankings {
-		existingMap[ranking.ID] = ranking
-	}
-
-	newRankings := make([]Ranking, len(requestRankings))
-	for i, reqRanking := range requestRankings {
-		existing, exists := existingMap[reqRanking.RankingID]
-		if !exists {
-			return nil, errors.New("invalid ranking ID in rankings")
-		}
-		existing.Position = i + 1 // Set position to 1, 2, 3, etc.
-		if reqRanking.Description != nil {
-			existing.Description = reqRanking.Description
-		}
-		if reqRanking.StartingPlayer != nil {
-			existing.StartingPlayer = *reqRanking.StartingPlayer
-		}
-		// Only update player_id if it's provided and the ranking doesn't have a player yet
-		if reqRanking.PlayerID != nil && existing.PlayerID == nil {
-			existing.PlayerID = reqRanking.PlayerID
-		}
-		newRankings[i] = existing
-	}
+// PauseGame pauses an unfinished game; paused time doesn't count towards the game or turn durations
+func (s *Service) PauseGame(w http.ResponseWriter, r *http.Request) {
+	s.setGamePaused(w, r, true)
+}
 
-	return newRankings, nil
+// ResumeGame resumes a paused game
+func (s *Service) ResumeGame(w http.ResponseWriter, r *http.Request) {
+	s.setGamePaused(w, r, false)
 }
 
-func (s *Service) GetProfileImageUploadURL(w http.ResponseWriter, r *http.Request) {
+func (s *Service) setGamePaused(w http.ResponseWriter, r *http.Request, pause bool) {
 	userID := middleware.GetUserID(r)
 	if userID == "" {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	var request struct {
-		FileName string `json:"file_name"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
 		return
 	}
 
-	if request.FileName == "" {
-		http.Error(w, "file_name is required", http.StatusBadRequest)
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
 		return
 	}
 
-	uploadURL, err := s.Storage.GeneratePresignedUploadURL(profileImagePrefix+request.FileName, getImgContentType(request.FileName))
+	if pause {
+		_, err = s.Repository.PauseGame(game.ID, userID)
+	} else {
+		_, err = s.Repository.ResumeGame(game.ID)
+	}
 	if err != nil {
-		http.Error(w, "Error generating upload URL", http.StatusInternalServerError)
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case strings.Contains(err.Error(), "finished"):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case strings.Contains(err.Error(), "paused"):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
+	action := AuditActionResumed
+	if pause {
+		action = AuditActionPaused
+	}
+	s.recordAudit(auditAction(userID, game.ID, AuditEntityGame, game.ID, action, nil))
 
-	imgUrl := strings.Split(uploadURL, "?")[0]
-	err = s.Repository.UpdatePlayerProfileImage(userID, imgUrl)
+	updatedGame, err := s.Repository.GetGameWithEvents(game.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	response := struct {
-		UploadURL string `json:"upload_url"`
-		ImageURL  string `json:"image_url"`
+
+	rankingIDs := make([]uint, len(updatedGame.Rankings))
+	for i, ranking := range updatedGame.Rankings {
+		rankingIDs[i] = ranking.ID
+	}
+	s.eventBus.Publish(events.GameUpdatedEvent{
+		GameID:     updatedGame.ID,
+		RankingIDs: rankingIDs,
+		Date:       time.Now(),
+	})
+
+	result := s.ConvertGameToDto(updatedGame, false)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// ConfirmGameResult confirms the final positions of a finished game for the current user
+func (s *Service) ConfirmGameResult(w http.ResponseWriter, r *http.Request) {
+	s.voteOnResult(w, r, true)
+}
+
+// DisputeGameResult disputes the final positions of a finished game, the creator is asked to correct them
+func (s *Service) DisputeGameResult(w http.ResponseWriter, r *http.Request) {
+	s.voteOnResult(w, r, false)
+}
+
+func (s *Service) voteOnResult(w http.ResponseWriter, r *http.Request, confirmed bool) {
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	var request DisputeResultRequest
+	if !confirmed {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+
+	err = s.Repository.RecordResultVote(game.ID, middleware.GetUserID(r), confirmed, request.Comment)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case strings.Contains(err.Error(), "not finished"):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case strings.Contains(err.Error(), "already confirmed"):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := s.evaluateResult(game.ID, middleware.GetUserID(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updatedGame, err := s.Repository.GetGameWithEvents(game.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.eventBus.Publish(events.GameUpdatedEvent{
+		GameID:     updatedGame.ID,
+		RankingIDs: rankingIDsOf(updatedGame),
+		Date:       time.Now(),
+	})
+
+	result := s.ConvertGameToDto(updatedGame, false)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// GetGameHistory lists the audit log of a game: who changed what and when, oldest first
+func (s *Service) GetGameHistory(w http.ResponseWriter, r *http.Request) {
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	p := pagination.ParsePagination(r)
+	entries, total, err := s.Repository.GetGameHistory(uint(gameId), p.PerPage, p.Offset())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]AuditEntryResponse, 0, len(entries))
+	for i := range entries {
+		items = append(items, convertAuditEntry(&entries[i]))
+	}
+
+	result := pagination.PaginatedResult[AuditEntryResponse]{
+		Items:      items,
+		TotalCount: total,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+	}
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// GetDisputedGames lists the current user's games whose result was disputed, so they can correct the positions
+func (s *Service) GetDisputedGames(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	games, err := s.Repository.GetDisputedGames(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]GameResponse, len(games))
+	for i := range games {
+		result[i] = s.ConvertGameToDto(&games[i], false)
+	}
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// deleteGame deletes a game and publishes the game deleted event with its player and ranking info
+func (s *Service) deleteGame(game *Game, userID string) error {
+	if err := s.Repository.DeleteGame(game.ID); err != nil {
+		return err
+	}
+	s.recordAudit(gameAuditEntries(userID, game, nil)...)
+
+	playerIDs := make([]string, 0, len(game.Rankings))
+	for _, ranking := range game.Rankings {
+		if ranking.PlayerID != nil {
+			playerIDs = append(playerIDs, *ranking.PlayerID)
+		}
+	}
+	s.eventBus.Publish(events.GameDeletedEvent{
+		GameID:     game.ID,
+		RankingIDs: rankingIDsOf(game),
+		PlayerIDs:  playerIDs,
+		Confirmed:  game.ResultStatus == ResultStatusConfirmed,
+		Date:       time.Now(),
+	})
+	if game.ResultStatus == ResultStatusConfirmed {
+		s.recordAudit(statsAuditEntry(userID, game.ID, playerIDs))
+	}
+	return nil
+}
+
+// findDuplicateIDs returns the IDs of the games that look like the same game, failures only leave them out
+func (s *Service) findDuplicateIDs(game *Game) []uint {
+	candidates, err := s.Repository.FindDuplicateCandidates(game)
+	if err != nil {
+		log.Printf("Failed to find duplicates of game %d: %v", game.ID, err)
+		return nil
+	}
+	ids := make([]uint, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.ID
+	}
+	return ids
+}
+
+// GetGameDuplicates lists the games that look like the same game logged twice
+func (s *Service) GetGameDuplicates(w http.ResponseWriter, r *http.Request) {
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	candidates, err := s.Repository.FindDuplicateCandidates(game)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]GameResponse, len(candidates))
+	for i := range candidates {
+		result[i] = s.ConvertGameToDto(&candidates[i], false)
+	}
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// loadDuplicate loads the other game of a merge or discard and checks that both are the same game
+// The user has to be allowed to perform the action on the other game as well
+func (s *Service) loadDuplicate(w http.ResponseWriter, r *http.Request, game *Game, otherID uint, action gameAction) (*Game, bool) {
+	other, err := s.Repository.GetGameWithEvents(otherID)
+	if err != nil {
+		http.Error(w, "duplicate game not found", http.StatusNotFound)
+		return nil, false
+	}
+	userID := middleware.GetUserID(r)
+	if !s.canUserPerform(action, userID, roleInGame(other, userID, false)) {
+		http.Error(w, forbiddenMessages[action], http.StatusForbidden)
+		return nil, false
+	}
+	if !isDuplicateCandidate(game, other) {
+		http.Error(w, "games are not duplicates of each other", http.StatusBadRequest)
+		return nil, false
+	}
+	return other, true
+}
+
+// MergeGame merges a duplicate into the game of the path, the duplicate is deleted
+func (s *Service) MergeGame(w http.ResponseWriter, r *http.Request) {
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	var request MergeGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+	// The duplicate is deleted by the merge
+	duplicate, ok := s.loadDuplicate(w, r, game, request.DuplicateGameID, actionDeleteGame)
+	if !ok {
+		return
+	}
+
+	rankingMap, err := s.Repository.MergeGames(game.ID, duplicate.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	mergedGame, err := s.Repository.GetGameWithEvents(game.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The duplicate's events moved to the game and all life totals were replayed
+	userID := middleware.GetUserID(r)
+	entries := gameAuditEntries(userID, game, mergedGame)
+	entries = append(entries, gameEventAuditEntries(userID, append(game.GameEvents, duplicate.GameEvents...), mergedGame.GameEvents)...)
+	entries = append(entries,
+		auditAction(userID, game.ID, AuditEntityGame, game.ID, AuditActionMerged, map[string]AuditChange{
+			"merged_game_id": {After: duplicate.ID},
+		}),
+		auditAction(userID, duplicate.ID, AuditEntityGame, duplicate.ID, AuditActionMerged, map[string]AuditChange{
+			"merged_into_game_id": {After: game.ID},
+		}),
+	)
+	if duplicate.ResultStatus == ResultStatusConfirmed {
+		entries = append(entries, statsAuditEntry(userID, duplicate.ID, registeredPlayers(duplicate)))
+	}
+	s.recordAudit(entries...)
+
+	// Eliminations derived from the merged events are recorded by syncRankingStates
+	if err := s.syncRankingStates(game.ID, userID); err != nil {
+		log.Printf("Failed to update eliminations for game %d: %v", game.ID, err)
+	}
+	updatedGame, err := s.Repository.GetGameWithEvents(game.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.eventBus.Publish(events.GameMergedEvent{
+		GameID:          updatedGame.ID,
+		MergedGameID:    duplicate.ID,
+		RankingIDs:      rankingMap,
+		MergedPlayerIDs: registeredPlayers(duplicate),
+		Confirmed:       duplicate.ResultStatus == ResultStatusConfirmed,
+		Date:            time.Now(),
+	})
+	s.eventBus.Publish(events.GameUpdatedEvent{
+		GameID:     updatedGame.ID,
+		RankingIDs: rankingIDsOf(updatedGame),
+		Date:       time.Now(),
+	})
+
+	result := s.ConvertGameToDto(updatedGame, false)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// DiscardGame deletes the game of the path because it duplicates another game
+// Discarding needs the same rights as deleting the game
+func (s *Service) DiscardGame(w http.ResponseWriter, r *http.Request) {
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	var request DiscardGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+	if _, ok := s.loadDuplicate(w, r, game, request.DuplicateOf, actionEditGame); !ok {
+		return
+	}
+
+	if err := s.deleteGame(game, middleware.GetUserID(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGameByJoinCode previews the unfinished game behind a join code so players can pick a ranking to claim
+func (s *Service) GetGameByJoinCode(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	game, err := s.Repository.GetGameByJoinCode(normalizeJoinCode(r.PathValue("code")))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := s.ConvertGameToDto(game, false)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// JoinGame lets a player join an unfinished game with its join code
+// The player either claims a ranking without a player or adds a new ranking with their own deck
+func (s *Service) JoinGame(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, err := s.Repository.GetPlayerByFirebaseID(userID); err != nil {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+
+	var request JoinGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.DeckID != nil && request.Deck != nil {
+		http.Error(w, "Each ranking must have either deck_id OR deck, not both", http.StatusBadRequest)
+		return
+	}
+
+	game, err := s.Repository.GetGameByJoinCode(normalizeJoinCode(r.PathValue("code")))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if isParticipant(game, userID) {
+		http.Error(w, "You already play in this game", http.StatusConflict)
+		return
+	}
+
+	var rankingID uint
+	if request.RankingID != nil {
+		rankingID = *request.RankingID
+		if !gameHasRanking(game, rankingID) {
+			http.Error(w, "ranking does not belong to this game", http.StatusBadRequest)
+			return
+		}
+		err = s.Repository.ClaimRanking(rankingID, userID, request.DeckID)
+	} else {
+		// Checked again by AddRanking while the game is locked, this rejects invalid requests early
+		if err := validateJoiningRanking(game, request); err != nil {
+			status := http.StatusBadRequest
+			if strings.Contains(err.Error(), "full") {
+				status = http.StatusConflict
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		toAdd := Ranking{
+			PlayerID: &userID,
+			DeckID:   request.DeckID,
+			Team:     request.Team,
+		}
+		if request.Deck != nil {
+			toAdd.DeckEmbedded = convertSimpleDeck(*request.Deck)
+			if err := s.Repository.SaveCommanders(s.completeSimpleDeck(&toAdd.DeckEmbedded)); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		var ranking *Ranking
+		ranking, err = s.Repository.AddRanking(game.ID, toAdd, request)
+		if ranking != nil {
+			rankingID = ranking.ID
+		}
+	}
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "already"), strings.Contains(err.Error(), "full"), strings.Contains(err.Error(), "teams"):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case strings.Contains(err.Error(), "deck"):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	updatedGame, err := s.Repository.GetGameWithEvents(game.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rankingIDs := make([]uint, len(updatedGame.Rankings))
+	otherPlayerIDs := []string{}
+	for i, ranking := range updatedGame.Rankings {
+		rankingIDs[i] = ranking.ID
+		if ranking.PlayerID != nil && *ranking.PlayerID != userID {
+			otherPlayerIDs = append(otherPlayerIDs, *ranking.PlayerID)
+		}
+	}
+	s.recordAudit(gameAuditEntries(userID, game, updatedGame)...)
+
+	s.eventBus.Publish(events.RankingJoinedEvent{
+		RankingID:      rankingID,
+		GameID:         updatedGame.ID,
+		PlayerID:       userID,
+		OtherPlayerIDs: otherPlayerIDs,
+		Date:           time.Now(),
+	})
+	s.eventBus.Publish(events.GameUpdatedEvent{
+		GameID:     updatedGame.ID,
+		RankingIDs: rankingIDs,
+		Date:       time.Now(),
+	})
+
+	result := s.ConvertGameToDto(updatedGame, false)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// gameHasRanking reports whether a ranking belongs to the game
+func gameHasRanking(game *Game, rankingID uint) bool {
+	for _, ranking := range game.Rankings {
+		if ranking.ID == rankingID {
+			return true
+		}
+	}
+	return false
+}
+
+// isParticipant reports whether the user plays in one of the game's rankings
+func isParticipant(game *Game, userID string) bool {
+	for _, ranking := range game.Rankings {
+		if ranking.PlayerID != nil && *ranking.PlayerID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) GetGame(w http.ResponseWriter, r *http.Request) {
+	gameIdStr := r.PathValue("gameId")
+	gameId, err := strconv.Atoi(gameIdStr)
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// convert the game to a DTO
+	result := s.ConvertGameToDto(game, true)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+func (s *Service) DeleteGame(w http.ResponseWriter, r *http.Request) {
+	gameIdStr := r.PathValue("gameId")
+	gameId, err := strconv.Atoi(gameIdStr)
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	// Fetch game data before deletion to include in event
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+
+	if err := s.deleteGame(game, middleware.GetUserID(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeletedGames lists the current user's deleted games that can be restored
+func (s *Service) GetDeletedGames(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	p := pagination.ParsePagination(r)
+	games, total, err := s.Repository.GetDeletedGames(userID, p.PerPage, p.Offset())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]GameResponse, 0, len(games))
+	for i := range games {
+		items = append(items, s.ConvertGameToDto(&games[i], false))
+	}
+
+	result := pagination.PaginatedResult[GameResponse]{
+		Items:      items,
+		TotalCount: total,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+	}
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// RestoreGame undeletes a deleted game, its players, statistics, deck counters and notifications are rebuilt
+func (s *Service) RestoreGame(w http.ResponseWriter, r *http.Request) {
+	gameId, err := strconv.Atoi(r.PathValue("gameId"))
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	game, err := s.Repository.GetDeletedGame(uint(gameId))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+
+	if err := s.Repository.RestoreGame(game.ID); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case strings.Contains(err.Error(), "not deleted"), strings.Contains(err.Error(), "merged"):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	restoredGame, err := s.Repository.GetGameWithEvents(game.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	playerIDs := registeredPlayers(restoredGame)
+	confirmed := restoredGame.ResultStatus == ResultStatusConfirmed
+	entries := []AuditEntry{auditAction(userID, game.ID, AuditEntityGame, game.ID, AuditActionRestored, nil)}
+	if confirmed {
+		entries = append(entries, statsAuditEntry(userID, game.ID, playerIDs))
+	}
+	s.recordAudit(entries...)
+
+	s.eventBus.Publish(events.GameRestoredEvent{
+		GameID:     restoredGame.ID,
+		RankingIDs: rankingIDsOf(restoredGame),
+		PlayerIDs:  playerIDs,
+		Confirmed:  confirmed,
+		DeletedAt:  game.DeletedAt.Time,
+		Date:       time.Now(),
+	})
+
+	result := s.ConvertGameToDto(restoredGame, false)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+func (s *Service) GetGames(w http.ResponseWriter, r *http.Request) {
+	p := pagination.ParsePagination(r)
+
+	// Call the repository to get the games
+	games, total, err := s.Repository.GetGames(p.PerPage, p.Offset())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]GameResponse, 0, len(games))
+	for _, game := range games {
+		items = append(items, s.ConvertGameToDto(&game, true))
+	}
+
+	result := pagination.PaginatedResult[GameResponse]{
+		Items:      items,
+		TotalCount: total,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+	}
+
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+func (s *Service) GetActiveGame(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	game, err := s.Repository.GetActiveGameForPlayer(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if game == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	result := s.ConvertGameToDto(game, true)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// UpdateGame updates an existing game by its ID.
+// You may need to adjust the request struct and repository method as needed.
+func (s *Service) UpdateGame(w http.ResponseWriter, r *http.Request) {
+	gameIdStr := r.PathValue("gameId")
+	gameId, err := strconv.Atoi(gameIdStr)
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	var request UpdateGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	game, err := s.Repository.GetGameWithEvents(uint(gameId))
+	if err != nil {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+	// find the rankings for that game
+	rankings := []Ranking{}
+	err = s.Repository.DB.Model(&Ranking{}).Where("game_id = ?", gameId).Find(&rankings).Error
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Validate and reorder rankings
+	newRankings, err := validateAndReorderRankings(request.Rankings, rankings)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	names, err := s.participantNames(participantsOf(game, newRankings))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, reqRanking := range request.Rankings {
+		if err := s.checkDescription(reqRanking.Description, names); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if request.Duration != nil && *request.Duration < 0 {
+		http.Error(w, "duration can't be negative", http.StatusBadRequest)
+		return
+	}
+
+	// Call the repository to update the game (implement UpdateGame in your repository)
+	updatedGame, err := s.updateGame(uint(gameId), newRankings, request.Finished, request.Duration, middleware.GetUserID(r), false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if updatedGame == nil {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+
+	result := s.ConvertGameToDto(updatedGame, false)
+	if !game.Finished && updatedGame.Finished {
+		result.PossibleDuplicateIDs = s.findDuplicateIDs(updatedGame)
+	}
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// updateGame stores new rankings and the finished flag of a game and publishes the resulting events
+// Both the manual update endpoint and automatic finishes go through here
+// A finished game's result awaits confirmation, the user who finished or corrected it confirms it if they play in it
+// onlyUnfinished leaves a game that is finished by then as it is and returns nil, see Repository.UpdateGame
+func (s *Service) updateGame(gameID uint, rankings []Ranking, finished *bool, duration *int, userID string, onlyUnfinished bool) (*Game, error) {
+	before, err := s.Repository.GetGameWithEvents(gameID)
+	if err != nil {
+		return nil, err
+	}
+	updatedGame, awaitingConfirmation, err := s.Repository.UpdateGame(gameID, rankings, finished, duration, onlyUnfinished)
+	if err != nil || updatedGame == nil {
+		return updatedGame, err
+	}
+	s.recordAudit(gameAuditEntries(userID, before, updatedGame)...)
+
+	rankingIDs := rankingIDsOf(updatedGame)
+	s.eventBus.Publish(events.GameUpdatedEvent{
+		GameID:     updatedGame.ID,
+		RankingIDs: rankingIDs,
+		Date:       time.Now(),
+	})
+
+	if !awaitingConfirmation {
+		return updatedGame, nil
+	}
+	s.eventBus.Publish(events.GameResultPendingEvent{
+		GameID:     updatedGame.ID,
+		RankingIDs: rankingIDs,
+		Date:       time.Now(),
+	})
+	if userID != "" && isParticipant(updatedGame, userID) {
+		if err := s.Repository.RecordResultVote(updatedGame.ID, userID, true, ""); err != nil {
+			log.Printf("Failed to confirm the result of game %d for %s: %v", updatedGame.ID, userID, err)
+		}
+	}
+	if err := s.evaluateResult(updatedGame.ID, userID); err != nil {
+		log.Printf("Failed to evaluate the result of game %d: %v", updatedGame.ID, err)
+	}
+	return s.Repository.GetGameWithEvents(updatedGame.ID)
+}
+
+// validateAndReorderRankings validates that the request rankings match existing rankings
+// and returns them with sequential positions (1, 2, 3, etc.) based on request order
+func validateAndReorderRankings(requestRankings []UpdateRanking, existingRankings []Ranking) ([]Ranking, error) {
+	// Validate that request rankings match existing rankings count
+	if len(requestRankings) != len(existingRankings) {
+		return nil, errors.New("rankings count must match existing rankings")
+	}
+
+	// Create map of existing rankings by RankingID string value for quick lookup
+	existingMap := make(map[uint]Ranking)
+	for _, ranking := range existingRankings {
+		existingMap[ranking.ID] = ranking
+	}
+
+	newRankings := make([]Ranking, len(requestRankings))
+	position := 0
+	finishedTeams := make(map[int]bool)
+	for i, reqRanking := range requestRankings {
+		existing, exists := existingMap[reqRanking.RankingID]
+		if !exists {
+			return nil, errors.New("invalid ranking ID in rankings")
+		}
+		// Teammates are listed next to each other and share a position
+		if i > 0 && existing.Team != nil && newRankings[i-1].Team != nil && *newRankings[i-1].Team == *existing.Team {
+			existing.Position = position
+		} else {
+			if existing.Team != nil && finishedTeams[*existing.Team] {
+				return nil, errors.New("teammates must be listed next to each other")
+			}
+			position++
+			existing.Position = position // Set position to 1, 2, 3, etc.
+		}
+		if existing.Team != nil {
+			finishedTeams[*existing.Team] = true
+		}
+		if reqRanking.Description != nil {
+			existing.Description = reqRanking.Description
+		}
+		if reqRanking.StartingPlayer != nil {
+			existing.StartingPlayer = *reqRanking.StartingPlayer
+		}
+		// Only update player_id if it's provided and the ranking doesn't have a player yet
+		if reqRanking.PlayerID != nil && existing.PlayerID == nil {
+			existing.PlayerID = reqRanking.PlayerID
+		}
+		newRankings[i] = existing
+	}
+
+	return newRankings, nil
+}
+
+func (s *Service) GetProfileImageUploadURL(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var request struct {
+		FileName string `json:"file_name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if request.FileName == "" {
+		http.Error(w, "file_name is required", http.StatusBadRequest)
+		return
+	}
+
+	uploadURL, err := s.Storage.GeneratePresignedUploadURL(profileImagePrefix+request.FileName, getImgContentType(request.FileName))
+	if err != nil {
+		http.Error(w, "Error generating upload URL", http.StatusInternalServerError)
+		return
+	}
+
+	imgUrl := strings.Split(uploadURL, "?")[0]
+	err = s.Repository.UpdatePlayerProfileImage(userID, imgUrl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response := struct {
+		UploadURL string `json:"upload_url"`
+		ImageURL  string `json:"image_url"`
 	}{
 		UploadURL: uploadURL,
 		ImageURL:  strings.Split(uploadURL, "?")[0],
 	}
 
-	err = json.NewEncoder(w).Encode(response)
-	if err != nil {
-		log.Println("Error encoding response:", err)
-	}
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+func (s *Service) DeleteRanking(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rankingIDStr := r.PathValue("rankingId")
+	rankingID, err := strconv.Atoi(rankingIDStr)
+	if err != nil {
+		http.Error(w, "Invalid ranking ID", http.StatusBadRequest)
+		return
+	}
+
+	// Fetch ranking data before deletion to include in event
+	ranking, gameID, otherPlayerIDs, err := s.Repository.GetRankingWithGamePlayers(uint(rankingID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	game, err := s.Repository.GetGameWithEvents(gameID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !s.authorizeRanking(w, r, game, ranking) {
+		return
+	}
+
+	// Call the repository to delete the ranking
+	err = s.Repository.DeleteRanking(uint(rankingID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	removed := *ranking
+	removed.PlayerID = nil
+	if entry, ok := newAuditEntry(userID, gameID, AuditEntityRanking, ranking.ID, ranking, &removed); ok {
+		s.recordAudit(entry)
+	}
+
+	// Publish ranking deleted event if the ranking had a player
+	if ranking.PlayerID != nil {
+		s.eventBus.Publish(events.RankingDeletedEvent{
+			RankingID:      uint(rankingID),
+			GameID:         gameID,
+			PlayerID:       *ranking.PlayerID,
+			OtherPlayerIDs: otherPlayerIDs,
+			Date:           time.Now(),
+		})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
-func (s *Service) DeleteRanking(w http.ResponseWriter, r *http.Request) {
+
+// ProposeRankingClaim proposes that a guest ranking was played by a player
+// Only the creator and participants of the game can propose claims
+func (s *Service) ProposeRankingClaim(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 	if userID == "" {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	rankingIDStr := r.PathValue("rankingId")
-	rankingID, err := strconv.Atoi(rankingIDStr)
+	rankingID, err := strconv.Atoi(r.PathValue("rankingId"))
 	if err != nil {
 		http.Error(w, "Invalid ranking ID", http.StatusBadRequest)
 		return
 	}
 
-	// Fetch ranking data before deletion to include in event
-	ranking, gameID, otherPlayerIDs, err := s.Repository.GetRankingWithGamePlayers(uint(rankingID))
+	var request ProposeRankingClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.PlayerID == "" {
+		http.Error(w, "player_id is required", http.StatusBadRequest)
+		return
+	}
+
+	var ranking Ranking
+	if err := s.Repository.DB.First(&ranking, rankingID).Error; err != nil {
+		http.Error(w, "ranking not found", http.StatusNotFound)
+		return
+	}
+	game, err := s.Repository.GetGameWithEvents(ranking.GameID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+
+	claim, err := s.Repository.CreateRankingClaim(ranking.ID, request.PlayerID, userID)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
 			http.Error(w, err.Error(), http.StatusNotFound)
-			return
+		case strings.Contains(err.Error(), "already"):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
+		return
+	}
+
+	s.eventBus.Publish(events.RankingClaimProposedEvent{
+		ClaimID:      claim.ID,
+		RankingID:    claim.RankingID,
+		GameID:       claim.GameID,
+		PlayerID:     claim.PlayerID,
+		ProposedByID: claim.ProposedByID,
+		Date:         time.Now(),
+	})
+
+	claim.Ranking = &ranking
+	w.WriteHeader(http.StatusCreated)
+	result := s.convertRankingClaim(claim)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// GetMyRankingClaims lists the claims waiting for the current user's answer
+func (s *Service) GetMyRankingClaims(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := s.Repository.GetPendingRankingClaims(userID)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Call the repository to delete the ranking
-	err = s.Repository.DeleteRanking(uint(rankingID), userID)
+	result := make([]RankingClaimResponse, len(claims))
+	for i := range claims {
+		result[i] = s.convertRankingClaim(&claims[i])
+	}
+	err = json.NewEncoder(w).Encode(result)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "unauthorized") {
-			http.Error(w, err.Error(), http.StatusNotFound)
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// AcceptRankingClaim gives the claimed guest ranking to the current user
+// Opponent counts, deck statistics and the player's statistics history are updated for finished games
+func (s *Service) AcceptRankingClaim(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	claimID, err := strconv.Atoi(r.PathValue("claimId"))
+	if err != nil {
+		http.Error(w, "Invalid claim ID", http.StatusBadRequest)
+		return
+	}
+
+	var request AcceptRankingClaimRequest
+	// The body is optional
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+	}
+
+	claim, game, err := s.Repository.AcceptRankingClaim(uint(claimID), userID, request.DeckID)
+	if err != nil {
+		writeRankingClaimError(w, err)
+		return
+	}
+
+	updatedGame, err := s.Repository.GetGameWithEvents(game.ID)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Publish ranking deleted event if the ranking had a player
-	if ranking.PlayerID != nil {
-		s.eventBus.Publish(events.RankingDeletedEvent{
-			RankingID:      uint(rankingID),
-			GameID:         gameID,
-			PlayerID:       *ranking.PlayerID,
-			OtherPlayerIDs: otherPlayerIDs,
-			Date:           time.Now(),
-		})
+	rankingIDs := make([]uint, len(updatedGame.Rankings))
+	otherPlayerIDs := []string{}
+	for i, ranking := range updatedGame.Rankings {
+		rankingIDs[i] = ranking.ID
+		if ranking.PlayerID != nil && *ranking.PlayerID != userID {
+			otherPlayerIDs = append(otherPlayerIDs, *ranking.PlayerID)
+		}
+		if ranking.ID == claim.RankingID {
+			claim.Ranking = &updatedGame.Rankings[i]
+		}
+	}
+	changes := map[string]AuditChange{"player_id": {After: userID}}
+	if request.DeckID != nil {
+		changes["deck_id"] = AuditChange{After: *request.DeckID}
+	}
+	entries := []AuditEntry{auditAction(userID, updatedGame.ID, AuditEntityRanking, claim.RankingID, AuditActionUpdated, changes)}
+	if updatedGame.ResultStatus == ResultStatusConfirmed {
+		entries = append(entries, statsAuditEntry(userID, updatedGame.ID, []string{userID}))
+	}
+	s.recordAudit(entries...)
+
+	s.eventBus.Publish(events.RankingClaimedEvent{
+		ClaimID:        claim.ID,
+		RankingID:      claim.RankingID,
+		GameID:         updatedGame.ID,
+		PlayerID:       userID,
+		OtherPlayerIDs: otherPlayerIDs,
+		Confirmed:      updatedGame.ResultStatus == ResultStatusConfirmed,
+		Date:           time.Now(),
+	})
+	s.eventBus.Publish(events.GameUpdatedEvent{
+		GameID:     updatedGame.ID,
+		RankingIDs: rankingIDs,
+		Date:       time.Now(),
+	})
+
+	result := s.convertRankingClaim(claim)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// RejectRankingClaim rejects a claim proposed to the current user
+func (s *Service) RejectRankingClaim(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	claimID, err := strconv.Atoi(r.PathValue("claimId"))
+	if err != nil {
+		http.Error(w, "Invalid claim ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.Repository.RejectRankingClaim(uint(claimID), userID); err != nil {
+		writeRankingClaimError(w, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// writeRankingClaimError maps errors of answering a ranking claim to HTTP status codes
+func writeRankingClaimError(w http.ResponseWriter, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case strings.Contains(err.Error(), "unauthorized"):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case strings.Contains(err.Error(), "already"):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.Contains(err.Error(), "deck"):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (s *Service) CreateDeck(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 	if userID == "" {
@@ -629,22 +1969,20 @@ func (s *Service) CreateDeck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deck, err := s.Repository.CreateDeck(
-		userID,
-		request.Commander,
-		request.Image,
-		request.SecondaryImage,
-		request.Crop,
-		request.MoxfieldURL,
-		request.Themes,
-		request.Colors,
-		request.Bracket,
-	)
+	if err := s.Repository.SaveCommanders(s.completeDeckRequest(&request)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deck, err := s.Repository.CreateDeck(userID, request)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if entry, ok := newAuditEntry(userID, 0, AuditEntityDeck, deck.ID, nil, deck); ok {
+		s.recordAudit(entry)
+	}
+
 	// Convert to DTO (you may need to create a converter function)
 	result := convertDeckToDto(deck)
 	err = json.NewEncoder(w).Encode(result)
@@ -653,6 +1991,185 @@ func (s *Service) CreateDeck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// loadOwnDeck loads the deck of the request path and checks that it belongs to the current user
+// It writes the error response and returns false if the user may not change the deck
+func (s *Service) loadOwnDeck(w http.ResponseWriter, r *http.Request) (*Deck, bool) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	deckID, err := strconv.Atoi(r.PathValue("deckId"))
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	deck, err := s.Repository.GetDeck(uint(deckID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return nil, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if deck.PlayerID == nil || *deck.PlayerID != userID {
+		http.Error(w, "Only the owner of the deck can change it", http.StatusForbidden)
+		return nil, false
+	}
+	return deck, true
+}
+
+// UpdateDeck changes a deck, changes to what the deck is store a new version
+func (s *Service) UpdateDeck(w http.ResponseWriter, r *http.Request) {
+	var request UpdateDeckRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deck, ok := s.loadOwnDeck(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.Repository.SaveCommanders(s.completeDeckUpdate(deck, &request)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	updatedDeck, err := s.Repository.UpdateDeck(deck.ID, func(deck *Deck) {
+		applyDeckUpdate(deck, request)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entry, ok := newAuditEntry(middleware.GetUserID(r), 0, AuditEntityDeck, deck.ID, deck, updatedDeck); ok {
+		s.recordAudit(entry)
+	}
+
+	result := convertDeckToDto(updatedDeck)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// RetireDeck hides a deck from deck pickers, it keeps counting in statistics
+func (s *Service) RetireDeck(w http.ResponseWriter, r *http.Request) {
+	s.setDeckRetired(w, r, true)
+}
+
+// UnretireDeck brings a retired deck back to the deck pickers
+func (s *Service) UnretireDeck(w http.ResponseWriter, r *http.Request) {
+	s.setDeckRetired(w, r, false)
+}
+
+func (s *Service) setDeckRetired(w http.ResponseWriter, r *http.Request, retired bool) {
+	deck, ok := s.loadOwnDeck(w, r)
+	if !ok {
+		return
+	}
+
+	updatedDeck, err := s.Repository.SetDeckRetired(deck.ID, retired)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entry, ok := newAuditEntry(middleware.GetUserID(r), 0, AuditEntityDeck, deck.ID, deck, updatedDeck); ok {
+		s.recordAudit(entry)
+	}
+
+	result := convertDeckToDto(updatedDeck)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// DeleteDeck deletes a deck, games played with it keep showing the version that was played
+func (s *Service) DeleteDeck(w http.ResponseWriter, r *http.Request) {
+	deck, ok := s.loadOwnDeck(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.Repository.DeleteDeck(deck.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entry, ok := newAuditEntry(middleware.GetUserID(r), 0, AuditEntityDeck, deck.ID, deck, nil); ok {
+		s.recordAudit(entry)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeck returns a deck with its recent games and how it fares against other commanders and players
+func (s *Service) GetDeck(w http.ResponseWriter, r *http.Request) {
+	deckID, err := strconv.Atoi(r.PathValue("deckId"))
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	deck, err := s.Repository.GetDeck(uint(deckID))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	games, err := s.Repository.GetConfirmedDeckGames(deck.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := computeDeckStats(deck.ID, games)
+	result.Deck = convertDeckToDto(deck)
+	if deck.Player != nil {
+		player := ConvertPlayerToDtoSimple(deck.Player)
+		result.Player = &player
+	}
+	for i := len(games) - 1; i >= 0 && len(result.RecentGames) < deckRecentGames; i-- {
+		result.RecentGames = append(result.RecentGames, s.ConvertGameToDto(&games[i], false))
+	}
+
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
+// GetDeckVersions lists the versions of a deck, newest first
+func (s *Service) GetDeckVersions(w http.ResponseWriter, r *http.Request) {
+	deckID, err := strconv.Atoi(r.PathValue("deckId"))
+	if err != nil {
+		http.Error(w, "Invalid deck ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.Repository.GetDeck(uint(deckID)); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	versions, err := s.Repository.GetDeckVersions(uint(deckID))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]DeckResponse, len(versions))
+	for i := range versions {
+		result[i] = convertDeckVersion(&versions[i])
+	}
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
+
 func (s *Service) GetPlayerDecks(w http.ResponseWriter, r *http.Request) {
 	playerID := r.PathValue("playerId")
 	if playerID == "" {
@@ -662,7 +2179,9 @@ func (s *Service) GetPlayerDecks(w http.ResponseWriter, r *http.Request) {
 
 	p := pagination.ParsePagination(r)
 
-	decks, total, err := s.Repository.GetPlayerDecks(playerID, p.PerPage, p.Offset())
+	// Retired decks are hidden from deck pickers unless asked for
+	includeRetired := r.URL.Query().Get("include_retired") == "true"
+	decks, total, err := s.Repository.GetPlayerDecks(playerID, includeRetired, p.PerPage, p.Offset())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -773,6 +2292,7 @@ func (s *Service) SearchGamesEndpoint(w http.ResponseWriter, r *http.Request) {
 
 	// Convert request to filter
 	filter := request.ToFilter()
+	s.resolveFilterCommanders(&filter)
 
 	// Search games with filter
 	games, total, err := s.Repository.SearchGamesWithFilters(filter, p.PerPage, p.Offset())
@@ -814,6 +2334,30 @@ func (s *Service) UpdateRankingEndpoint(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	var existing Ranking
+	if err := s.Repository.DB.First(&existing, rankingID).Error; err != nil {
+		http.Error(w, "ranking not found", http.StatusNotFound)
+		return
+	}
+	game, err := s.Repository.GetGameWithEvents(existing.GameID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !s.authorizeGame(w, r, game) {
+		return
+	}
+
+	names, err := s.participantNames(game.Rankings)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.checkDescription(request.Description, names); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Build updates map
 	updates := make(map[string]interface{})
 	if request.Description != nil {
@@ -844,6 +2388,16 @@ func (s *Service) UpdateRankingEndpoint(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if entry, ok := newAuditEntry(middleware.GetUserID(r), ranking.GameID, AuditEntityRanking, ranking.ID, &existing, ranking); ok {
+		s.recordAudit(entry)
+	}
+
+	s.eventBus.Publish(events.GameUpdatedEvent{
+		GameID:     ranking.GameID,
+		RankingIDs: []uint{ranking.ID},
+		Date:       time.Now(),
+	})
+
 	result := convertRankingToDto(ranking)
 	err = json.NewEncoder(w).Encode(result)
 	if err != nil {
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"time"
+)
+
+// turnState is the current turn of a game derived by replaying its turn pass events
+type turnState struct {
+	TurnNumber        int        // Round of the current turn, 0 before the first turn is known
+	ActiveRankingID   *uint      // Ranking whose turn it is
+	StartedAt         *time.Time // When the current turn started
+	StartingRankingID *uint      // Ranking that took the first turn; a new round starts when it is active again
+
+	// Number of turns taken and their total duration in seconds per ranking, the current turn excluded
+	TurnCounts   map[uint]int
+	TurnSeconds  map[uint]int
+	LastDuration *int // Duration of the most recently finished turn in seconds
+
+	// Chess clock per ranking in seconds, nil for untimed games
+	// The time of the active ranking is counted down from the start of its turn
+	ClockRemaining map[uint]int
+	TimedOutAt     map[uint]time.Time // When a ranking's clock ran out
+	clockIncrement int
+
+	pauses []GamePause // Paused time doesn't count towards turn durations
+}
+
+// deriveTurnState replays the turn pass events of a game
+// If a ranking is flagged as the starting player, its first turn starts when the game is created
+// Otherwise the first turn pass decides who started: its source, or its target if no source was given
+// Voided events are skipped and turn numbers are recalculated, so undoing a turn pass is consistent
+// The clock of the active ranking runs until now, or until the game ended, so a player stalling their turn runs out of time
+func deriveTurnState(game *Game, now time.Time) turnState {
+	state := turnState{
+		TurnCounts:  make(map[uint]int),
+		TurnSeconds: make(map[uint]int),
+		pauses:      game.Pauses,
+	}
+	if game.ClockInitialSeconds != nil {
+		state.ClockRemaining = make(map[uint]int)
+		state.TimedOutAt = make(map[uint]time.Time)
+		state.clockIncrement = game.ClockIncrementSeconds
+		for _, ranking := range game.Rankings {
+			state.ClockRemaining[ranking.ID] = *game.ClockInitialSeconds
+		}
+	}
+	for _, ranking := range game.Rankings {
+		if ranking.StartingPlayer {
+			id := ranking.ID
+			createdAt := game.CreatedAt
+			state.StartingRankingID = &id
+			state.ActiveRankingID = &id
+			state.StartedAt = &createdAt
+			state.TurnNumber = 1
+			break
+		}
+	}
+
+	for _, event := range game.GameEvents {
+		if event.EventType != EventTypeTurnPass || event.VoidedAt != nil || event.TargetRankingID == nil {
+			continue
+		}
+		state.pass(event.SourceRankingID, *event.TargetRankingID, event.CreatedAt)
+	}
+
+	if state.ClockRemaining != nil && state.ActiveRankingID != nil {
+		if game.Finished && game.EndDate != nil {
+			now = *game.EndDate
+		}
+		played := int(now.Sub(*state.StartedAt).Seconds()) - pausedSeconds(state.pauses, *state.StartedAt, now)
+		state.expireClock(*state.ActiveRankingID, *state.StartedAt, played)
+	}
+	return state
+}
+
+// pass ends the current turn and starts the turn of the target ranking at the given time
+func (state *turnState) pass(source *uint, target uint, at time.Time) {
+	if state.ActiveRankingID == nil {
+		// Nobody was known to be playing: the source took the first turn, or the target starts it
+		switch {
+		case source != nil && *source != target:
+			starting := *source
+			state.StartingRankingID = &starting
+			state.TurnNumber = 1
+		default:
+			starting := target
+			state.StartingRankingID = &starting
+			state.TurnNumber = 0
+		}
+	} else {
+		duration := int(at.Sub(*state.StartedAt).Seconds()) - pausedSeconds(state.pauses, *state.StartedAt, at)
+		if duration < 0 {
+			duration = 0
+		}
+		state.TurnCounts[*state.ActiveRankingID]++
+		state.TurnSeconds[*state.ActiveRankingID] += duration
+		state.LastDuration = &duration
+		state.runClock(*state.ActiveRankingID, *state.StartedAt, duration)
+	}
+
+	if target == *state.StartingRankingID {
+		state.TurnNumber++
+	}
+	active := target
+	startedAt := at
+	state.ActiveRankingID = &active
+	state.StartedAt = &startedAt
+}
+
+// runClock takes the duration of a finished turn off a ranking's clock and adds the increment
+// A clock that ran out stays at zero
+func (state *turnState) runClock(rankingID uint, startedAt time.Time, duration int) {
+	if state.ClockRemaining == nil {
+		return
+	}
+	if state.expireClock(rankingID, startedAt, duration) {
+		return
+	}
+	state.ClockRemaining[rankingID] -= duration
+	state.ClockRemaining[rankingID] += state.clockIncrement
+}
+
+// expireClock reports whether a ranking's clock ran out during a turn started at startedAt and played for the given seconds
+// A clock running out is set to zero and times out when the turn had used up the time left, not when it was noticed
+func (state *turnState) expireClock(rankingID uint, startedAt time.Time, played int) bool {
+	if state.ClockRemaining == nil {
+		return false
+	}
+	if _, timedOut := state.TimedOutAt[rankingID]; timedOut {
+		return true
+	}
+	remaining := state.ClockRemaining[rankingID]
+	if played < remaining {
+		return false
+	}
+
+	// Pauses during the turn don't count, so the clock runs out that much later
+	expiry := startedAt.Add(time.Duration(remaining) * time.Second)
+	for {
+		next := startedAt.Add(time.Duration(remaining+pausedSeconds(state.pauses, startedAt, expiry)) * time.Second)
+		if !next.After(expiry) {
+			break
+		}
+		expiry = next
+	}
+	state.ClockRemaining[rankingID] = 0
+	state.TimedOutAt[rankingID] = expiry
+	return true
+}
+
+// applyClockTimeouts eliminates the rankings whose clock ran out, unless they were eliminated before that
+func applyClockTimeouts(states map[uint]*rankingState, turns turnState) {
+	for rankingID, timedOutAt := range turns.TimedOutAt {
+		state, ok := states[rankingID]
+		if !ok {
+			state = newRankingState()
+			states[rankingID] = state
+		}
+		if state.Eliminated && state.EliminatedAt != nil && !state.EliminatedAt.After(timedOutAt) {
+			continue
+		}
+		at := timedOutAt
+		state.Eliminated = true
+		state.EliminatedAt = &at
+		state.EliminatedByRankingID = nil
+		state.EliminationReason = EliminationReasonTimeout
+	}
+}
@@ -30,6 +30,7 @@ type GameDeletedEvent struct {
 	GameID     uint
 	RankingIDs []uint
 	PlayerIDs  []string // Player IDs from rankings (for follow count decrements)
+	Confirmed  bool     // Whether the game's result was counted, so statistics need a rebuild
 	Date       time.Time
 }
 
@@ -37,6 +38,20 @@ func (e GameDeletedEvent) EventName() string {
 	return "game.deleted"
 }
 
+// GameRestoredEvent is published when a deleted game is restored
+type GameRestoredEvent struct {
+	GameID     uint
+	RankingIDs []uint
+	PlayerIDs  []string  // Player IDs from rankings (for follow count increments)
+	Confirmed  bool      // Whether the game's result counts, so statistics need a rebuild
+	DeletedAt  time.Time // When the game was deleted, everything removed with it since then is restored
+	Date       time.Time
+}
+
+func (e GameRestoredEvent) EventName() string {
+	return "game.restored"
+}
+
 // RankingDeletedEvent is published when a player removes themselves from a game
 type RankingDeletedEvent struct {
 	RankingID uint
@@ -49,3 +64,117 @@ type RankingDeletedEvent struct {
 func (e RankingDeletedEvent) EventName() string {
 	return "ranking.deleted"
 }
+
+// GameEventAddedEvent is published when a new event is added to a game's timeline
+type GameEventAddedEvent struct {
+	GameID      uint
+	GameEventID uint
+	EventType   string
+	Date        time.Time
+}
+
+func (e GameEventAddedEvent) EventName() string {
+	return "game.event_added"
+}
+
+// GameUpdatedEvent is published when the rankings of a game change
+type GameUpdatedEvent struct {
+	GameID     uint
+	RankingIDs []uint
+	Date       time.Time
+}
+
+func (e GameUpdatedEvent) EventName() string {
+	return "game.updated"
+}
+
+// GameEventVoidedEvent is published when a game event is voided (undone) or restored (redone)
+type GameEventVoidedEvent struct {
+	GameID      uint
+	GameEventID uint
+	Voided      bool
+	PlayerID    string // The participant who voided or restored the event
+	Date        time.Time
+}
+
+func (e GameEventVoidedEvent) EventName() string {
+	return "game.event_voided"
+}
+
+// RankingJoinedEvent is published when a player joins a game with its join code
+type RankingJoinedEvent struct {
+	RankingID      uint
+	GameID         uint
+	PlayerID       string   // The player who joined
+	OtherPlayerIDs []string // Other players in the game (for follow count increments)
+	Date           time.Time
+}
+
+func (e RankingJoinedEvent) EventName() string {
+	return "ranking.joined"
+}
+
+// RankingClaimProposedEvent is published when a guest ranking is proposed to a player
+type RankingClaimProposedEvent struct {
+	ClaimID      uint
+	RankingID    uint
+	GameID       uint
+	PlayerID     string // The player the ranking is proposed to
+	ProposedByID string
+	Date         time.Time
+}
+
+func (e RankingClaimProposedEvent) EventName() string {
+	return "ranking.claim_proposed"
+}
+
+// RankingClaimedEvent is published when a player accepts a claim on a guest ranking
+type RankingClaimedEvent struct {
+	ClaimID        uint
+	RankingID      uint
+	GameID         uint
+	PlayerID       string   // The player who now owns the ranking
+	OtherPlayerIDs []string // Other players in the game (for follow count increments)
+	Confirmed      bool     // Whether the game's result was already confirmed, so it counts for the player
+	Date           time.Time
+}
+
+func (e RankingClaimedEvent) EventName() string {
+	return "ranking.claimed"
+}
+
+// GameResultPendingEvent is published when the final positions of a game await confirmation by its participants
+type GameResultPendingEvent struct {
+	GameID     uint
+	RankingIDs []uint
+	Date       time.Time
+}
+
+func (e GameResultPendingEvent) EventName() string {
+	return "game.result_pending"
+}
+
+// GameResultDisputedEvent is published when a participant disputes the final positions of a game
+type GameResultDisputedEvent struct {
+	GameID    uint
+	CreatorID string // The creator is asked to correct the positions
+	Date      time.Time
+}
+
+func (e GameResultDisputedEvent) EventName() string {
+	return "game.result_disputed"
+}
+
+// GameMergedEvent is published when a duplicate game is merged into another game and deleted
+type GameMergedEvent struct {
+	GameID          uint          // The game that survives
+	MergedGameID    uint          // The duplicate that was deleted
+	RankingIDs      map[uint]uint // The survivor's ranking for every ranking of the duplicate
+	MergedPlayerIDs []string      // Players of the duplicate (for follow count decrements)
+	Confirmed       bool          // Whether the duplicate's result was counted, so statistics need a rebuild
+	Date            time.Time
+}
+
+func (e GameMergedEvent) EventName() string {
+	return "game.merged"
+}
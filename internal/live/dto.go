@@ -0,0 +1,19 @@
+package live
+
+import "mtgtracker/internal/core"
+
+const (
+	MessageTypeSnapshot     = "snapshot"
+	MessageTypeGameEvent    = "game_event"
+	MessageTypeEventVoided  = "game_event_voided"
+	MessageTypeGameUpdated  = "game_updated"
+	MessageTypeGameFinished = "game_finished"
+)
+
+// StreamMessage is the payload pushed to stream subscribers
+type StreamMessage struct {
+	Type   string                  `json:"type"`
+	GameID uint                    `json:"game_id"`
+	Game   *core.GameResponse      `json:"game,omitempty"`
+	Event  *core.GameEventResponse `json:"event,omitempty"`
+}
@@ -0,0 +1,114 @@
+package live
+
+import (
+	"log"
+	"mtgtracker/internal/core"
+	"mtgtracker/internal/events"
+)
+
+type CoreService interface {
+	GetGameByID(gameID uint) (*core.Game, error)
+	ConvertGameToDto(game *core.Game, addEvents bool) core.GameResponse
+	ConvertGameEventToDto(event *core.GameEvent) core.GameEventResponse
+}
+
+// EventHandlers forwards game events from the event bus to stream subscribers
+type EventHandlers struct {
+	hub         *Hub
+	coreService CoreService
+}
+
+// NewEventHandlers creates a new event handler instance
+func NewEventHandlers(hub *Hub, coreService CoreService) *EventHandlers {
+	return &EventHandlers{
+		hub:         hub,
+		coreService: coreService,
+	}
+}
+
+// RegisterHandlers subscribes to all relevant events
+func (h *EventHandlers) RegisterHandlers(bus *events.EventBus) {
+	bus.Subscribe("game.event_added", h.HandleGameEventAdded)
+	bus.Subscribe("game.event_voided", h.HandleGameEventVoided)
+	bus.Subscribe("game.updated", h.HandleGameUpdated)
+	bus.Subscribe("game.finished", h.HandleGameFinished)
+	log.Println("Live event handlers registered")
+}
+
+// HandleGameEventAdded pushes a new game event and the resulting rankings to subscribers
+func (h *EventHandlers) HandleGameEventAdded(event events.Event) error {
+	e, ok := event.(events.GameEventAddedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.event_added: %T", event)
+		return nil
+	}
+
+	return h.broadcast(e.GameID, MessageTypeGameEvent, &e.GameEventID)
+}
+
+// HandleGameEventVoided pushes a voided or restored game event and the recalculated rankings to subscribers
+func (h *EventHandlers) HandleGameEventVoided(event events.Event) error {
+	e, ok := event.(events.GameEventVoidedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.event_voided: %T", event)
+		return nil
+	}
+
+	return h.broadcast(e.GameID, MessageTypeEventVoided, &e.GameEventID)
+}
+
+// HandleGameUpdated pushes the updated rankings to subscribers
+func (h *EventHandlers) HandleGameUpdated(event events.Event) error {
+	e, ok := event.(events.GameUpdatedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.updated: %T", event)
+		return nil
+	}
+
+	return h.broadcast(e.GameID, MessageTypeGameUpdated, nil)
+}
+
+// HandleGameFinished pushes the final game state to subscribers
+func (h *EventHandlers) HandleGameFinished(event events.Event) error {
+	e, ok := event.(events.GameFinishedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.finished: %T", event)
+		return nil
+	}
+
+	return h.broadcast(e.GameID, MessageTypeGameFinished, nil)
+}
+
+// broadcast fetches the game once and fans it out to every subscriber
+// The game is only fetched when someone is actually listening
+func (h *EventHandlers) broadcast(gameID uint, messageType string, gameEventID *uint) error {
+	if h.hub.SubscriberCount(gameID) == 0 {
+		return nil
+	}
+
+	game, err := h.coreService.GetGameByID(gameID)
+	if err != nil {
+		log.Printf("Failed to fetch game %d: %v", gameID, err)
+		return err
+	}
+
+	gameDto := h.coreService.ConvertGameToDto(game, false)
+	msg := StreamMessage{
+		Type:   messageType,
+		GameID: gameID,
+		Game:   &gameDto,
+	}
+
+	if gameEventID != nil {
+		for i := range game.GameEvents {
+			if game.GameEvents[i].ID == *gameEventID {
+				eventDto := h.coreService.ConvertGameEventToDto(&game.GameEvents[i])
+				msg.Event = &eventDto
+				break
+			}
+		}
+	}
+
+	h.hub.Broadcast(gameID, msg)
+	return nil
+}
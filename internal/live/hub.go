@@ -0,0 +1,77 @@
+package live
+
+import (
+	"log"
+	"sync"
+)
+
+// subscriberBufferSize is the number of messages a slow subscriber can lag behind before messages are dropped
+const subscriberBufferSize = 16
+
+// Hub keeps track of the stream subscribers of every game
+type Hub struct {
+	subscribers map[uint]map[chan StreamMessage]struct{}
+	mu          sync.RWMutex
+}
+
+// NewHub creates a new hub instance
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[uint]map[chan StreamMessage]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber for a game and returns its message channel
+func (h *Hub) Subscribe(gameID uint) chan StreamMessage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan StreamMessage, subscriberBufferSize)
+	if h.subscribers[gameID] == nil {
+		h.subscribers[gameID] = make(map[chan StreamMessage]struct{})
+	}
+	h.subscribers[gameID][ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe removes a subscriber from a game and closes its channel
+func (h *Hub) Unsubscribe(gameID uint, ch chan StreamMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subscribers[gameID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(h.subscribers, gameID)
+	}
+}
+
+// Broadcast sends a message to all subscribers of a game
+// Subscribers whose buffer is full miss the message instead of blocking the publisher
+func (h *Hub) Broadcast(gameID uint, msg StreamMessage) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[gameID] {
+		select {
+		case ch <- msg:
+		default:
+			log.Printf("Dropping %s message for slow subscriber of game %d", msg.Type, gameID)
+		}
+	}
+}
+
+// SubscriberCount returns the number of subscribers for a game
+func (h *Hub) SubscriberCount(gameID uint) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subscribers[gameID])
+}
@@ -0,0 +1,151 @@
+package live
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"mtgtracker/internal/core"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type fakeCoreService struct {
+	games map[uint]*core.Game
+}
+
+func (f *fakeCoreService) GetGameByID(gameID uint) (*core.Game, error) {
+	game, ok := f.games[gameID]
+	if !ok {
+		return nil, errors.New("game not found")
+	}
+	return game, nil
+}
+
+func (f *fakeCoreService) ConvertGameToDto(game *core.Game, addEvents bool) core.GameResponse {
+	return core.GameResponse{ID: game.ID}
+}
+
+func (f *fakeCoreService) ConvertGameEventToDto(event *core.GameEvent) core.GameEventResponse {
+	return core.GameEventResponse{ID: event.ID}
+}
+
+func TestHubSubscribeBroadcastUnsubscribe(t *testing.T) {
+	hub := NewHub()
+	first := hub.Subscribe(1)
+	second := hub.Subscribe(1)
+	other := hub.Subscribe(2)
+	if count := hub.SubscriberCount(1); count != 2 {
+		t.Fatalf("expected 2 subscribers, got %d", count)
+	}
+
+	hub.Broadcast(1, StreamMessage{Type: MessageTypeGameUpdated, GameID: 1})
+	for _, ch := range []chan StreamMessage{first, second} {
+		select {
+		case msg := <-ch:
+			if msg.Type != MessageTypeGameUpdated || msg.GameID != 1 {
+				t.Errorf("unexpected message %+v", msg)
+			}
+		default:
+			t.Error("expected a message for a subscriber of the game")
+		}
+	}
+	select {
+	case msg := <-other:
+		t.Errorf("expected no message for another game, got %+v", msg)
+	default:
+	}
+
+	hub.Unsubscribe(1, first)
+	if _, ok := <-first; ok {
+		t.Error("expected the channel to be closed")
+	}
+	hub.Unsubscribe(1, first) // A second unsubscribe is a no-op
+	hub.Unsubscribe(1, second)
+	if count := hub.SubscriberCount(1); count != 0 {
+		t.Errorf("expected no subscribers, got %d", count)
+	}
+
+	// A full buffer drops messages instead of blocking
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		hub.Broadcast(2, StreamMessage{Type: MessageTypeGameEvent, GameID: 2})
+	}
+	if len(other) != subscriberBufferSize {
+		t.Errorf("expected a full buffer of %d messages, got %d", subscriberBufferSize, len(other))
+	}
+}
+
+func TestStreamGame(t *testing.T) {
+	hub := NewHub()
+	coreService := &fakeCoreService{games: map[uint]*core.Game{7: {Model: gorm.Model{ID: 7}}}}
+	mux := http.NewServeMux()
+	NewService(hub, coreService).RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/game/v1/games/8/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown game, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/game/v1/games/7/stream", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", contentType)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readMessage := func() (string, string) {
+		var event, data string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read stream: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	if event, data := readMessage(); event != MessageTypeSnapshot || !strings.Contains(data, `"game_id":7`) {
+		t.Errorf("expected a snapshot first, got %s %s", event, data)
+	}
+	if count := hub.SubscriberCount(7); count != 1 {
+		t.Fatalf("expected the stream to subscribe, got %d subscribers", count)
+	}
+
+	hub.Broadcast(7, StreamMessage{Type: MessageTypeGameFinished, GameID: 7})
+	if event, _ := readMessage(); event != MessageTypeGameFinished {
+		t.Errorf("expected the broadcast message, got %s", event)
+	}
+
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.SubscriberCount(7) != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := hub.SubscriberCount(7); count != 0 {
+		t.Errorf("expected the stream to unsubscribe after the client left, got %d subscribers", count)
+	}
+}
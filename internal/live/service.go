@@ -0,0 +1,106 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// keepAliveInterval is how often a comment is sent to keep idle connections open through proxies
+const keepAliveInterval = 25 * time.Second
+
+type Service struct {
+	hub         *Hub
+	coreService CoreService
+}
+
+func NewService(hub *Hub, coreService CoreService) *Service {
+	return &Service{hub: hub, coreService: coreService}
+}
+
+func (s *Service) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /game/v1/games/{gameId}/stream", s.StreamGame)
+}
+
+// StreamGame streams game updates to the client using Server-Sent Events
+// The first message is a snapshot of the current game state
+func (s *Service) StreamGame(w http.ResponseWriter, r *http.Request) {
+	gameIdStr := r.PathValue("gameId")
+	gameId, err := strconv.Atoi(gameIdStr)
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	game, err := s.coreService.GetGameByID(uint(gameId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Subscribe before sending the snapshot so no update is missed in between
+	ch := s.hub.Subscribe(game.ID)
+	defer s.hub.Unsubscribe(game.ID, ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		log.Println("Streaming not supported:", err)
+		return
+	}
+
+	gameDto := s.coreService.ConvertGameToDto(game, true)
+	snapshot := StreamMessage{
+		Type:   MessageTypeSnapshot,
+		GameID: game.ID,
+		Game:   &gameDto,
+	}
+	if err := writeMessage(w, rc, snapshot); err != nil {
+		log.Println("Error writing stream message:", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := writeMessage(w, rc, msg); err != nil {
+				log.Println("Error writing stream message:", err)
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeMessage writes a single SSE message and flushes it to the client
+func writeMessage(w http.ResponseWriter, rc *http.ResponseController, msg StreamMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Type, data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
@@ -31,6 +31,13 @@ func (h *EventHandlers) RegisterHandlers(bus *events.EventBus) {
 	bus.Subscribe("game.created", h.HandleGameCreated)
 	bus.Subscribe("game.finished", h.HandleGameFinished)
 	bus.Subscribe("game.deleted", h.HandleGameDeleted)
+	bus.Subscribe("game.merged", h.HandleGameMerged)
+	bus.Subscribe("game.restored", h.HandleGameRestored)
+	bus.Subscribe("game.result_pending", h.HandleGameResultPending)
+	bus.Subscribe("game.result_disputed", h.HandleGameResultDisputed)
+	bus.Subscribe("ranking.joined", h.HandleRankingJoined)
+	bus.Subscribe("ranking.claim_proposed", h.HandleRankingClaimProposed)
+	bus.Subscribe("ranking.claimed", h.HandleRankingClaimed)
 	log.Println("Notification event handlers registered")
 }
 
@@ -157,6 +164,215 @@ func (h *EventHandlers) HandleGameFinished(event events.Event) error {
 	return nil
 }
 
+// HandleRankingJoined processes ranking joined events
+func (h *EventHandlers) HandleRankingJoined(event events.Event) error {
+	e, ok := event.(events.RankingJoinedEvent)
+	if !ok {
+		log.Printf("Invalid event type for ranking.joined: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing ranking.joined event for game %d", e.GameID)
+
+	game, err := h.coreService.GetGameByID(e.GameID)
+	if err != nil {
+		log.Printf("Failed to fetch game %d: %v", e.GameID, err)
+		return err
+	}
+
+	player, err := h.coreService.GetPlayerByFirebaseID(e.PlayerID)
+	if err != nil {
+		log.Printf("Failed to fetch player %s: %v", e.PlayerID, err)
+		return err
+	}
+
+	// Create in-app notifications
+	err = h.repo.CreateRankingJoinedNotifications(game, player, e.OtherPlayerIDs)
+	if err != nil {
+		return err
+	}
+
+	// Send push notifications to the players already in the game
+	for _, otherPlayerID := range e.OtherPlayerIDs {
+		err := h.pushService.SendNotification(
+			otherPlayerID,
+			"Player Joined",
+			fmt.Sprintf("%s joined your game", player.Name),
+			player.Image,
+			map[string]string{
+				"type":    "ranking_joined",
+				"game_id": fmt.Sprint(e.GameID),
+			},
+		)
+		if err != nil {
+			log.Printf("Failed to send push notification to %s: %v", otherPlayerID, err)
+			// Continue processing other players
+		}
+	}
+
+	return nil
+}
+
+// HandleRankingClaimProposed processes ranking claim proposed events
+func (h *EventHandlers) HandleRankingClaimProposed(event events.Event) error {
+	e, ok := event.(events.RankingClaimProposedEvent)
+	if !ok {
+		log.Printf("Invalid event type for ranking.claim_proposed: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing ranking.claim_proposed event for game %d", e.GameID)
+
+	game, err := h.coreService.GetGameByID(e.GameID)
+	if err != nil {
+		log.Printf("Failed to fetch game %d: %v", e.GameID, err)
+		return err
+	}
+
+	proposedBy, err := h.coreService.GetPlayerByFirebaseID(e.ProposedByID)
+	if err != nil {
+		log.Printf("Failed to fetch player %s: %v", e.ProposedByID, err)
+		return err
+	}
+
+	for _, ranking := range game.Rankings {
+		if ranking.ID != e.RankingID {
+			continue
+		}
+
+		// Create in-app notification
+		if err := h.repo.CreateRankingClaimNotification(&ranking, e.PlayerID, proposedBy); err != nil {
+			return err
+		}
+
+		var imageURL string
+		if ranking.Deck != nil {
+			imageURL = ranking.Deck.Image
+		} else if ranking.DeckEmbedded.Image != "" {
+			imageURL = ranking.DeckEmbedded.Image
+		}
+
+		err := h.pushService.SendNotification(
+			e.PlayerID,
+			"Did you play this game?",
+			fmt.Sprintf("%s says you played in their game", proposedBy.Name),
+			imageURL,
+			map[string]string{
+				"type":     "ranking_claim_proposed",
+				"game_id":  fmt.Sprint(e.GameID),
+				"claim_id": fmt.Sprint(e.ClaimID),
+			},
+		)
+		if err != nil {
+			log.Printf("Failed to send push notification to %s: %v", e.PlayerID, err)
+		}
+	}
+
+	return nil
+}
+
+// HandleRankingClaimed processes ranking claimed events
+func (h *EventHandlers) HandleRankingClaimed(event events.Event) error {
+	e, ok := event.(events.RankingClaimedEvent)
+	if !ok {
+		log.Printf("Invalid event type for ranking.claimed: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing ranking.claimed event for notifications (game %d)", e.GameID)
+
+	// The claim was answered, so the notification asking for it is no longer needed
+	return h.repo.DeleteRankingClaimNotifications(e.RankingID, e.PlayerID)
+}
+
+// HandleGameResultPending asks the players of a finished game to confirm its result
+func (h *EventHandlers) HandleGameResultPending(event events.Event) error {
+	e, ok := event.(events.GameResultPendingEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.result_pending: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing game.result_pending event for game %d", e.GameID)
+
+	game, err := h.coreService.GetGameByID(e.GameID)
+	if err != nil {
+		log.Printf("Failed to fetch game %d: %v", e.GameID, err)
+		return err
+	}
+
+	// Create in-app notifications
+	err = h.repo.CreateResultPendingNotifications(game)
+	if err != nil {
+		return err
+	}
+
+	// Send push notifications to all players
+	for _, ranking := range game.Rankings {
+		if ranking.PlayerID != nil {
+			err := h.pushService.SendNotification(
+				*ranking.PlayerID,
+				"Confirm the result",
+				fmt.Sprintf("Did you finish in position %d?", ranking.Position),
+				"",
+				map[string]string{
+					"type":     "game_result_pending",
+					"game_id":  fmt.Sprint(e.GameID),
+					"position": fmt.Sprint(ranking.Position),
+				},
+			)
+			if err != nil {
+				log.Printf("Failed to send push notification to %s: %v", *ranking.PlayerID, err)
+				// Continue processing other players
+			}
+		}
+	}
+
+	return nil
+}
+
+// HandleGameResultDisputed asks the creator of a game to correct its disputed result
+func (h *EventHandlers) HandleGameResultDisputed(event events.Event) error {
+	e, ok := event.(events.GameResultDisputedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.result_disputed: %T", event)
+		return nil
+	}
+	if e.CreatorID == "" {
+		return nil
+	}
+
+	log.Printf("Processing game.result_disputed event for game %d", e.GameID)
+
+	game, err := h.coreService.GetGameByID(e.GameID)
+	if err != nil {
+		log.Printf("Failed to fetch game %d: %v", e.GameID, err)
+		return err
+	}
+
+	// Create in-app notification
+	err = h.repo.CreateResultDisputedNotification(game, e.CreatorID)
+	if err != nil {
+		return err
+	}
+
+	err = h.pushService.SendNotification(
+		e.CreatorID,
+		"Result disputed",
+		"A player disagrees with the final positions, please correct them",
+		"",
+		map[string]string{
+			"type":    "game_result_disputed",
+			"game_id": fmt.Sprint(e.GameID),
+		},
+	)
+	if err != nil {
+		log.Printf("Failed to send push notification to %s: %v", e.CreatorID, err)
+	}
+
+	return nil
+}
+
 // HandleGameDeleted processes game deleted events
 func (h *EventHandlers) HandleGameDeleted(event events.Event) error {
 	e, ok := event.(events.GameDeletedEvent)
@@ -170,3 +386,31 @@ func (h *EventHandlers) HandleGameDeleted(event events.Event) error {
 	// Delete all notifications related to this game
 	return h.repo.DeleteNotificationsByGameID(e.GameID)
 }
+
+// HandleGameMerged processes game merged events
+func (h *EventHandlers) HandleGameMerged(event events.Event) error {
+	e, ok := event.(events.GameMergedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.merged: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing game.merged event for notifications (game %d into %d)", e.MergedGameID, e.GameID)
+
+	// Point the duplicate's notifications to the surviving game
+	return h.repo.MoveMergedGameNotifications(e.GameID, e.MergedGameID, e.RankingIDs)
+}
+
+// HandleGameRestored processes game restored events
+func (h *EventHandlers) HandleGameRestored(event events.Event) error {
+	e, ok := event.(events.GameRestoredEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.restored: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing game.restored event for notifications (game %d)", e.GameID)
+
+	// Bring back the notifications that were deleted with the game
+	return h.repo.RestoreNotificationsByGameID(e.GameID, e.DeletedAt)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"mtgtracker/internal/core"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,6 +17,10 @@ const (
 	ActionViewGame           NotificationAction = "view_game"
 	ActionAddGameDescription NotificationAction = "add_game_description"
 	ActionAddImageGameEvent  NotificationAction = "add_image_game_event"
+	ActionReviewRankingClaim NotificationAction = "review_ranking_claim"
+	ActionConfirmResult      NotificationAction = "confirm_result"
+	ActionDisputeResult      NotificationAction = "dispute_result"
+	ActionCorrectResult      NotificationAction = "correct_result"
 )
 
 type Notification struct {
@@ -74,8 +79,9 @@ func (r *Repository) GetNotifications(userID string, readFilter *bool, limit, of
 		}).
 		Preload("Player").
 		Preload("Game.Rankings.Player").
-		Preload("Game.Rankings.Deck").
-		Preload("Game.GameEvents").
+		Preload("Game.Rankings.Deck", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }). // Deleted decks stay on old games
+		Preload("Game.Rankings.DeckVersion").
+		Preload("Game.GameEvents", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }). // Events in the order they were logged
 		Preload("Game.Creator").
 		Preload("ReferredPlayer").
 		Preload("PlayerRanking").
@@ -101,15 +107,25 @@ func (r *Repository) MarkNotificationAsRead(notificationID uint, userID string)
 	return nil
 }
 
+// rankingCommanderName returns the commander of a ranking from either its referenced deck or its embedded deck
+// A pairing is named with both commanders
+func rankingCommanderName(ranking *core.Ranking) string {
+	deck := ranking.DeckEmbedded
+	if ranking.Deck != nil {
+		deck = core.SimpleDeck{Commander: ranking.Deck.Commander, Partner: ranking.Deck.Partner}
+	}
+	if deck.Partner != "" {
+		return fmt.Sprintf("%s and %s", deck.Commander, deck.Partner)
+	}
+	return deck.Commander
+}
+
 func (r *Repository) CreateGameNotifications(game *core.Game, creator *core.Player) error {
 	// Create notifications for all players in the game
 	for _, ranking := range game.Rankings {
 		if ranking.PlayerID != nil {
 			// Get commander name from either referenced deck or embedded deck
-			commanderName := ranking.DeckEmbedded.Commander
-			if ranking.Deck != nil {
-				commanderName = ranking.Deck.Commander
-			}
+			commanderName := rankingCommanderName(&ranking)
 
 			notification := Notification{
 				UserID:           *ranking.PlayerID,
@@ -133,8 +149,8 @@ func (r *Repository) CreateGameNotifications(game *core.Game, creator *core.Play
 }
 
 func (r *Repository) CreateGameFinishedNotifications(game *core.Game) error {
-	// Delete all game_created notifications for this game
-	if err := r.DB.Where("game_id = ? AND type = ?", game.ID, "game_created").Delete(&Notification{}).Error; err != nil {
+	// Delete all game_created and result notifications for this game
+	if err := r.DB.Where("game_id = ? AND type IN ?", game.ID, []string{"game_created", "game_result_pending", "game_result_disputed"}).Delete(&Notification{}).Error; err != nil {
 		log.Printf("Failed to delete game_created notifications for game %d: %v", game.ID, err)
 		// Don't fail the entire operation if deletion fails
 	}
@@ -202,6 +218,133 @@ func (r *Repository) CreateGameFinishedNotifications(game *core.Game) error {
 	return nil
 }
 
+func (r *Repository) CreateRankingJoinedNotifications(game *core.Game, player *core.Player, otherPlayerIDs []string) error {
+	// Notify the players already in the game that someone joined with the join code
+	for _, otherPlayerID := range otherPlayerIDs {
+		notification := Notification{
+			UserID:           otherPlayerID,
+			ReferredPlayerID: &player.FirebaseID,
+			Title:            fmt.Sprintf("%s joined your game", player.Name),
+			Body:             fmt.Sprintf("%s joined with the game's join code", player.Name),
+			Type:             "ranking_joined",
+			Actions:          []NotificationAction{ActionViewGame},
+			Read:             false,
+			GameID:           &game.ID,
+		}
+
+		if err := r.DB.Create(&notification).Error; err != nil {
+			log.Printf("Failed to create ranking joined notification for player %s: %v", otherPlayerID, err)
+			// Continue creating notifications for other players even if one fails
+		}
+	}
+	return nil
+}
+
+func (r *Repository) CreateResultPendingNotifications(game *core.Game) error {
+	// Replace the notifications of an earlier result that was corrected
+	if err := r.DB.Where("game_id = ? AND type IN ?", game.ID, []string{"game_result_pending", "game_result_disputed"}).Delete(&Notification{}).Error; err != nil {
+		log.Printf("Failed to delete result notifications for game %d: %v", game.ID, err)
+	}
+
+	for _, ranking := range game.Rankings {
+		if ranking.PlayerID != nil {
+			notification := Notification{
+				UserID:           *ranking.PlayerID,
+				ReferredPlayerID: game.CreatorID,
+				Title:            "Confirm the result",
+				Body:             fmt.Sprintf("Did you finish in position %d?", ranking.Position),
+				Type:             "game_result_pending",
+				Actions:          []NotificationAction{ActionViewGame, ActionConfirmResult, ActionDisputeResult},
+				Read:             false,
+				GameID:           &game.ID,
+				PlayerRankingID:  &ranking.ID,
+			}
+
+			if err := r.DB.Create(&notification).Error; err != nil {
+				log.Printf("Failed to create result notification for player %s: %v", *ranking.PlayerID, err)
+				// Continue creating notifications for other players even if one fails
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Repository) CreateResultDisputedNotification(game *core.Game, creatorID string) error {
+	notification := Notification{
+		UserID:  creatorID,
+		Title:   "Result disputed",
+		Body:    "A player disagrees with the final positions, please correct them",
+		Type:    "game_result_disputed",
+		Actions: []NotificationAction{ActionViewGame, ActionCorrectResult},
+		Read:    false,
+		GameID:  &game.ID,
+	}
+	// Refer to the first player who disputed the result
+	for _, vote := range game.ResultVotes {
+		if !vote.Confirmed {
+			playerID := vote.PlayerID
+			notification.ReferredPlayerID = &playerID
+			break
+		}
+	}
+	return r.DB.Create(&notification).Error
+}
+
+func (r *Repository) CreateRankingClaimNotification(ranking *core.Ranking, playerID string, proposedBy *core.Player) error {
+	// Get commander name from either referenced deck or embedded deck
+	commanderName := rankingCommanderName(ranking)
+
+	notification := Notification{
+		UserID:           playerID,
+		ReferredPlayerID: &proposedBy.FirebaseID,
+		Title:            fmt.Sprintf("%s says you played in their game", proposedBy.Name),
+		Body:             fmt.Sprintf("Did you play %s?", commanderName),
+		Type:             "ranking_claim_proposed",
+		Actions:          []NotificationAction{ActionViewGame, ActionReviewRankingClaim},
+		Read:             false,
+		GameID:           &ranking.GameID,
+		PlayerRankingID:  &ranking.ID,
+	}
+	return r.DB.Create(&notification).Error
+}
+
+// DeleteRankingClaimNotifications removes the claim notifications of a ranking once a claim was answered
+func (r *Repository) DeleteRankingClaimNotifications(rankingID uint, playerID string) error {
+	return r.DB.Where("player_ranking_id = ? AND user_id = ? AND type = ?", rankingID, playerID, "ranking_claim_proposed").Delete(&Notification{}).Error
+}
+
+// MoveMergedGameNotifications moves the notifications of a merged duplicate to the game it was merged into
+// Players who got the same notification for both games keep only the older one
+func (r *Repository) MoveMergedGameNotifications(gameID, mergedGameID uint, rankingIDs map[uint]uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for from, to := range rankingIDs {
+			if from == to {
+				continue
+			}
+			if err := tx.Model(&Notification{}).Where("game_id = ? AND player_ranking_id = ?", mergedGameID, from).
+				Update("player_ranking_id", to).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&Notification{}).Where("game_id = ?", mergedGameID).Update("game_id", gameID).Error; err != nil {
+			return err
+		}
+		return tx.Where(`game_id = ? AND EXISTS (
+			SELECT 1 FROM notifications older
+			WHERE older.game_id = notifications.game_id AND older.user_id = notifications.user_id
+			AND older.type = notifications.type AND older.deleted_at IS NULL AND older.id < notifications.id)`, gameID).
+			Delete(&Notification{}).Error
+	})
+}
+
+// RestoreNotificationsByGameID undeletes the notifications of a game that were deleted since a point in time
+// Notifications that were replaced before the game was deleted stay deleted
+func (r *Repository) RestoreNotificationsByGameID(gameID uint, deletedSince time.Time) error {
+	return r.DB.Unscoped().Model(&Notification{}).
+		Where("game_id = ? AND deleted_at >= ?", gameID, deletedSince).
+		Update("deleted_at", nil).Error
+}
+
 func (r *Repository) DeleteNotificationsByGameID(gameID uint) error {
 	return r.DB.Where("game_id = ?", gameID).Delete(&Notification{}).Error
 }
@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"fmt"
+	"mtgtracker/internal/core"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testRepository connects to the database of TEST_POSTGRES_DSN and skips the test without one
+func testRepository(t *testing.T) *Repository {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	core.NewRepository(db)
+	return NewRepository(db)
+}
+
+func TestRestoreNotificationsByGameID(t *testing.T) {
+	repo := testRepository(t)
+	id := fmt.Sprintf("player-%d", time.Now().UnixNano())
+	if err := repo.DB.Create(&core.Player{FirebaseID: id, Name: id, Email: id + "@example.com"}).Error; err != nil {
+		t.Fatalf("failed to create player: %v", err)
+	}
+	game := core.Game{CreatorID: &id}
+	if err := repo.DB.Create(&game).Error; err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	otherGame := core.Game{CreatorID: &id}
+	if err := repo.DB.Create(&otherGame).Error; err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+
+	notifications := []Notification{
+		{UserID: id, Title: "Result pending", Body: "replaced", Type: "result_pending", GameID: &game.ID},
+		{UserID: id, Title: "Game finished", Body: "deleted with the game", Type: "game_finished", GameID: &game.ID},
+		{UserID: id, Title: "Game finished", Body: "other game", Type: "game_finished", GameID: &otherGame.ID},
+	}
+	if err := repo.DB.Create(&notifications).Error; err != nil {
+		t.Fatalf("failed to create notifications: %v", err)
+	}
+	// The first notification was replaced before the game was deleted
+	if err := repo.DB.Delete(&notifications[0]).Error; err != nil {
+		t.Fatalf("failed to delete notification: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	deletedAt := time.Now()
+	if err := repo.DeleteNotificationsByGameID(game.ID); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+	if err := repo.DeleteNotificationsByGameID(otherGame.ID); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+
+	if err := repo.RestoreNotificationsByGameID(game.ID, deletedAt); err != nil {
+		t.Fatalf("unexpected error restoring: %v", err)
+	}
+
+	var restored []Notification
+	if err := repo.DB.Where("user_id = ?", id).Order("id").Find(&restored).Error; err != nil {
+		t.Fatalf("failed to load notifications: %v", err)
+	}
+	if len(restored) != 1 || restored[0].ID != notifications[1].ID {
+		t.Errorf("expected only the notification deleted with the game to be restored, got %+v", restored)
+	}
+}
@@ -28,7 +28,11 @@ func NewEventHandlers(repo *Repository, coreService CoreService) *EventHandlers
 func (h *EventHandlers) RegisterHandlers(bus *events.EventBus) {
 	bus.Subscribe("game.created", h.HandleGameCreated)
 	bus.Subscribe("game.deleted", h.HandleGameDeleted)
+	bus.Subscribe("game.merged", h.HandleGameMerged)
+	bus.Subscribe("game.restored", h.HandleGameRestored)
 	bus.Subscribe("ranking.deleted", h.HandleRankingDeleted)
+	bus.Subscribe("ranking.joined", h.HandleRankingJoined)
+	bus.Subscribe("ranking.claimed", h.HandleRankingClaimed)
 	log.Println("Opponent event handlers registered")
 }
 
@@ -78,6 +82,34 @@ func (h *EventHandlers) HandleGameDeleted(event events.Event) error {
 	return h.updateOpponentsForPlayerPairs(e.PlayerIDs, false)
 }
 
+// HandleGameRestored processes game restored events
+// Increments follow counts for all player pairs in the game again
+func (h *EventHandlers) HandleGameRestored(event events.Event) error {
+	e, ok := event.(events.GameRestoredEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.restored: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing game.restored event for opponents (game %d)", e.GameID)
+
+	return h.updateOpponentsForPlayerPairs(e.PlayerIDs, true)
+}
+
+// HandleGameMerged processes game merged events
+// The duplicate counted the same game a second time, so its player pairs are decremented
+func (h *EventHandlers) HandleGameMerged(event events.Event) error {
+	e, ok := event.(events.GameMergedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.merged: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing game.merged event for opponents (game %d into %d)", e.MergedGameID, e.GameID)
+
+	return h.updateOpponentsForPlayerPairs(e.MergedPlayerIDs, false)
+}
+
 // HandleRankingDeleted processes ranking deleted events
 // Decrements follow counts between the deleted player and all other players in the game
 func (h *EventHandlers) HandleRankingDeleted(event events.Event) error {
@@ -101,6 +133,51 @@ func (h *EventHandlers) HandleRankingDeleted(event events.Event) error {
 	return nil
 }
 
+// HandleRankingJoined processes ranking joined events
+// Increments follow counts between the joined player and all other players in the game
+func (h *EventHandlers) HandleRankingJoined(event events.Event) error {
+	e, ok := event.(events.RankingJoinedEvent)
+	if !ok {
+		log.Printf("Invalid event type for ranking.joined: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing ranking.joined event for opponents (ranking %d, game %d)", e.RankingID, e.GameID)
+
+	// Increment opponents between the joined player and all other players
+	for _, otherPlayerID := range e.OtherPlayerIDs {
+		err := h.repo.IncrementGameCount(e.PlayerID, otherPlayerID)
+		if err != nil {
+			log.Printf("Failed to increment follow count for %s <-> %s: %v", e.PlayerID, otherPlayerID, err)
+			// Continue processing other pairs
+		}
+	}
+
+	return nil
+}
+
+// HandleRankingClaimed processes ranking claimed events
+// Counts the claimed guest ranking's game between the player and all other players in the game
+func (h *EventHandlers) HandleRankingClaimed(event events.Event) error {
+	e, ok := event.(events.RankingClaimedEvent)
+	if !ok {
+		log.Printf("Invalid event type for ranking.claimed: %T", event)
+		return nil
+	}
+
+	log.Printf("Processing ranking.claimed event for opponents (ranking %d, game %d)", e.RankingID, e.GameID)
+
+	for _, otherPlayerID := range e.OtherPlayerIDs {
+		err := h.repo.IncrementGameCount(e.PlayerID, otherPlayerID)
+		if err != nil {
+			log.Printf("Failed to increment follow count for %s <-> %s: %v", e.PlayerID, otherPlayerID, err)
+			// Continue processing other pairs
+		}
+	}
+
+	return nil
+}
+
 // updateOpponentsForPlayerPairs creates or updates follow relationships for all unique pairs
 // If increment is true, increments counts; otherwise decrements
 func (h *EventHandlers) updateOpponentsForPlayerPairs(playerIDs []string, increment bool) error {
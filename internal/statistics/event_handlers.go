@@ -5,10 +5,15 @@ import (
 	"math"
 	"mtgtracker/internal/core"
 	"mtgtracker/internal/events"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type CoreService interface {
 	GetGameByID(gameID uint) (*core.Game, error)
+	ConvertGameToDto(game *core.Game, addEvents bool) core.GameResponse
+	GetConfirmedPlayerGames(playerID string) ([]core.Game, error)
 }
 
 // EventHandlers manages event subscriptions for the statistics package
@@ -28,6 +33,10 @@ func NewEventHandlers(repo *Repository, coreService CoreService) *EventHandlers
 // RegisterHandlers subscribes to all relevant events
 func (h *EventHandlers) RegisterHandlers(bus *events.EventBus) {
 	bus.Subscribe("game.finished", h.HandleGameFinished)
+	bus.Subscribe("ranking.claimed", h.HandleRankingClaimed)
+	bus.Subscribe("game.deleted", h.HandleGameDeleted)
+	bus.Subscribe("game.merged", h.HandleGameMerged)
+	bus.Subscribe("game.restored", h.HandleGameRestored)
 	log.Println("Statistics event handlers registered")
 }
 
@@ -53,56 +62,230 @@ func (h *EventHandlers) HandleGameFinished(event events.Event) error {
 		if ranking.PlayerID == nil {
 			continue // Skip rankings without players
 		}
+		err := h.withPlayerLock(*ranking.PlayerID, func(txHandlers *EventHandlers) error {
+			return txHandlers.recordGame(game, &ranking, txHandlers.repo.GetLatestPlayerStats, time.Time{})
+		})
+		if err != nil {
+			log.Printf("Failed to create stats for player %s: %v", *ranking.PlayerID, err)
+		}
+	}
+
+	return nil
+}
+
+// withPlayerLock runs fn in a transaction holding the statistics lock of a player
+// The handlers passed to fn write through the transaction, so a rebuild and a new game of the same player don't interleave
+func (h *EventHandlers) withPlayerLock(playerID string, fn func(txHandlers *EventHandlers) error) error {
+	return h.repo.DB.Transaction(func(tx *gorm.DB) error {
+		txRepo := &Repository{DB: tx}
+		if err := txRepo.LockPlayerStats(playerID); err != nil {
+			return err
+		}
+		return fn(&EventHandlers{repo: txRepo, coreService: h.coreService})
+	})
+}
+
+// HandleRankingClaimed processes ranking claimed events
+// A claimed guest ranking of a confirmed game adds a game to the middle of the player's history, so it is rebuilt
+func (h *EventHandlers) HandleRankingClaimed(event events.Event) error {
+	e, ok := event.(events.RankingClaimedEvent)
+	if !ok {
+		log.Printf("Invalid event type for ranking.claimed: %T", event)
+		return nil
+	}
+	if !e.Confirmed {
+		return nil // Other games are counted when their result is confirmed
+	}
+
+	log.Printf("Processing ranking.claimed event for statistics (game %d)", e.GameID)
+	return h.RebuildPlayerStats(e.PlayerID)
+}
+
+// HandleGameDeleted processes game deleted events
+// A deleted game with a confirmed result leaves the histories of its players, so they are rebuilt
+func (h *EventHandlers) HandleGameDeleted(event events.Event) error {
+	e, ok := event.(events.GameDeletedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.deleted: %T", event)
+		return nil
+	}
+	if !e.Confirmed {
+		return nil // The game was never counted
+	}
 
-		playerID := *ranking.PlayerID
+	log.Printf("Processing game.deleted event for statistics (game %d)", e.GameID)
+	h.rebuildPlayers(e.PlayerIDs)
+	return nil
+}
+
+// HandleGameMerged processes game merged events
+// A merged duplicate with a confirmed result counted the game twice, so its players are rebuilt
+func (h *EventHandlers) HandleGameMerged(event events.Event) error {
+	e, ok := event.(events.GameMergedEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.merged: %T", event)
+		return nil
+	}
+	if !e.Confirmed {
+		return nil // The duplicate was never counted
+	}
+
+	log.Printf("Processing game.merged event for statistics (game %d into %d)", e.MergedGameID, e.GameID)
+	h.rebuildPlayers(e.MergedPlayerIDs)
+	return nil
+}
+
+// HandleGameRestored processes game restored events
+// A restored game with a confirmed result returns to the histories of its players, so they are rebuilt
+func (h *EventHandlers) HandleGameRestored(event events.Event) error {
+	e, ok := event.(events.GameRestoredEvent)
+	if !ok {
+		log.Printf("Invalid event type for game.restored: %T", event)
+		return nil
+	}
+	if !e.Confirmed {
+		return nil // The game is counted once its result is confirmed
+	}
+
+	log.Printf("Processing game.restored event for statistics (game %d)", e.GameID)
+	h.rebuildPlayers(e.PlayerIDs)
+	return nil
+}
+
+// rebuildPlayers rebuilds the statistics of several players, a failure doesn't stop the others
+func (h *EventHandlers) rebuildPlayers(playerIDs []string) {
+	for _, playerID := range playerIDs {
+		if err := h.RebuildPlayerStats(playerID); err != nil {
+			log.Printf("Failed to rebuild stats of player %s: %v", playerID, err)
+		}
+	}
+}
 
-		// Get current latest stats for the player
-		currentStats, err := h.repo.GetLatestPlayerStats(playerID)
+// RebuildPlayerStats replaces the statistics history of a player by replaying their confirmed games in order
+// Opponents are rated with the statistics they had before each game, their own history is left as it is
+// The history is deleted and replayed in one transaction while the player's statistics are locked
+func (h *EventHandlers) RebuildPlayerStats(playerID string) error {
+	var gameCount int
+	err := h.withPlayerLock(playerID, func(txHandlers *EventHandlers) error {
+		games, err := h.coreService.GetConfirmedPlayerGames(playerID)
 		if err != nil {
-			// If no stats exist, create initial stats
-			currentStats = &PlayerStats{
-				PlayerID:       playerID,
-				TotalWins:      0,
-				Winrate:        0,
-				RollingWinrate: 0,
-				GameCount:      0,
-				GameDuration:   0,
-				Streak:         0,
-				Elo:            1000, // Starting ELO
-			}
+			log.Printf("Failed to fetch confirmed games of player %s: %v", playerID, err)
+			return err
+		}
+		gameCount = len(games)
+
+		if err := txHandlers.repo.DeletePlayerStats(playerID); err != nil {
+			log.Printf("Failed to delete stats of player %s: %v", playerID, err)
+			return err
 		}
 
-		// Get all player stats for ELO calculation
-		allPlayerStats := make(map[string]*PlayerStats)
-		allPlayerStats[playerID] = currentStats
-		for _, r := range game.Rankings {
-			if r.PlayerID != nil && *r.PlayerID != playerID {
-				otherStats, err := h.repo.GetLatestPlayerStats(*r.PlayerID)
-				if err != nil {
-					// Initialize with default ELO if player has no stats
-					otherStats = &PlayerStats{
-						PlayerID: *r.PlayerID,
-						Elo:      1000,
+		for i := range games {
+			game := &games[i]
+			playedAt := game.CreatedAt
+			if game.EndDate != nil {
+				playedAt = *game.EndDate
+			} else if game.Date != nil {
+				playedAt = *game.Date
+			}
+
+			statsBefore := func(id, format string) (*PlayerStats, error) {
+				if id == playerID {
+					return txHandlers.repo.GetLatestPlayerStats(id, format)
+				}
+				return txHandlers.repo.GetPlayerStatsBefore(id, format, playedAt)
+			}
+			for _, ranking := range game.Rankings {
+				if ranking.PlayerID != nil && *ranking.PlayerID == playerID {
+					if err := txHandlers.recordGame(game, &ranking, statsBefore, playedAt); err != nil {
+						return err
 					}
+					break
 				}
-				allPlayerStats[*r.PlayerID] = otherStats
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Rebuilt stats of player %s from %d games", playerID, gameCount)
+	return nil
+}
 
-		// Calculate new stats
-		newStats := h.calculateNewStats(currentStats, &ranking, game, allPlayerStats)
+// statsLookup returns the statistics a player had in a format before a game
+type statsLookup func(playerID, format string) (*PlayerStats, error)
 
-		// Create new stats entry
-		err = h.repo.CreatePlayerStats(newStats)
-		if err != nil {
-			log.Printf("Failed to create stats for player %s: %v", playerID, err)
-			// Continue processing other players
-		} else {
-			log.Printf("Updated stats for player %s: ELO %d, Winrate %.2f%%",
-				playerID, newStats.Elo, newStats.Winrate*100)
+// recordGame stores the statistics of a ranking's player after a finished game
+// The entry is timestamped with playedAt, or the current time if it is zero
+func (h *EventHandlers) recordGame(game *core.Game, ranking *core.Ranking, lookup statsLookup, playedAt time.Time) error {
+	playerID := *ranking.PlayerID
+
+	// Statistics are kept separately per format
+	format := game.Format
+	if format == "" {
+		format = core.FormatCommander
+	}
+
+	// The game response carries the derived turn summary per ranking
+	gameDto := h.coreService.ConvertGameToDto(game, false)
+	var turns core.RankingResponse
+	for _, r := range gameDto.Rankings {
+		if r.ID == ranking.ID {
+			turns = r
 		}
 	}
 
+	// Get current latest stats for the player
+	currentStats, err := lookup(playerID, format)
+	if err != nil {
+		// If no stats exist, create initial stats
+		currentStats = &PlayerStats{
+			PlayerID:       playerID,
+			Format:         format,
+			TotalWins:      0,
+			Winrate:        0,
+			RollingWinrate: 0,
+			GameCount:      0,
+			GameDuration:   0,
+			Streak:         0,
+			Elo:            1000, // Starting ELO
+		}
+	}
+
+	// Get all player stats for ELO calculation
+	allPlayerStats := make(map[string]*PlayerStats)
+	allPlayerStats[playerID] = currentStats
+	for _, r := range game.Rankings {
+		if r.PlayerID != nil && *r.PlayerID != playerID {
+			otherStats, err := lookup(*r.PlayerID, format)
+			if err != nil {
+				// Initialize with default ELO if player has no stats
+				otherStats = &PlayerStats{
+					PlayerID: *r.PlayerID,
+					Elo:      1000,
+				}
+			}
+			allPlayerStats[*r.PlayerID] = otherStats
+		}
+	}
+
+	// Calculate new stats
+	newStats := h.calculateNewStats(currentStats, ranking, game, allPlayerStats)
+	newStats.TurnCount += turns.TurnCount
+	newStats.TurnDuration += turns.TurnSeconds
+	if ranking.Position == 1 && gameDto.CurrentTurn > 0 {
+		newStats.TrackedWins++
+		newStats.WinTurnTotal += gameDto.CurrentTurn
+	}
+	newStats.Timestamp = playedAt
+
+	// Create new stats entry
+	if err := h.repo.CreatePlayerStats(newStats); err != nil {
+		return err
+	}
+	log.Printf("Updated stats for player %s: ELO %d, Winrate %.2f%%",
+		playerID, newStats.Elo, newStats.Winrate*100)
 	return nil
 }
 
@@ -121,15 +304,17 @@ func (h *EventHandlers) calculateNewStats(current *PlayerStats, ranking *core.Ra
 	newWinrate := float64(newTotalWins) / float64(newGameCount)
 
 	// Calculate rolling winrate (moving average over last 10 games)
-	newRollingWinrate := h.calculateRollingWinrate(current.PlayerID, won)
+	newRollingWinrate := h.calculateRollingWinrate(current.PlayerID, current.Format, won)
 
 	// Update streak
 	newStreak := h.calculateStreak(current.Streak, won)
 
-	// Update total game duration
+	// Update total game duration, historical games without a duration don't count
 	newGameDuration := current.GameDuration
+	newTimedGames := current.TimedGames
 	if game.Duration != nil {
 		newGameDuration = current.GameDuration + *game.Duration
+		newTimedGames++
 	}
 
 	// Calculate new ELO based on performance against all other players
@@ -142,33 +327,40 @@ func (h *EventHandlers) calculateNewStats(current *PlayerStats, ranking *core.Ra
 
 	return &PlayerStats{
 		PlayerID:       current.PlayerID,
+		Format:         current.Format,
 		TotalWins:      newTotalWins,
 		Winrate:        newWinrate,
 		RollingWinrate: newRollingWinrate,
 		GameCount:      newGameCount,
 		GameDuration:   newGameDuration,
+		TimedGames:     newTimedGames,
 		Streak:         newStreak,
 		Elo:            newElo,
+		TurnCount:      current.TurnCount,
+		TurnDuration:   current.TurnDuration,
+		TrackedWins:    current.TrackedWins,
+		WinTurnTotal:   current.WinTurnTotal,
 	}
 }
 
 // calculateMultiplayerElo computes ELO rating by comparing against each opponent
 // Uses the formula: R'i = Ri + K/(N-1) * Σ(Sij - Eij) for all j ≠ i
+// Teammates play on the same side, so they are not compared and N-1 counts the opponents only
+// Guests count as opponents without a rating to compare against, as they do in free-for-all games
 func (h *EventHandlers) calculateMultiplayerElo(playerID string, ranking *core.Ranking, game *core.Game, allPlayerStats map[string]*PlayerStats) int {
 	currentElo := allPlayerStats[playerID].Elo
 	kFactor := 32.0
-	numPlayers := len(game.Rankings)
-
-	if numPlayers <= 1 {
-		return currentElo // No change if playing alone
+	opponents := 0
+	for i := range game.Rankings {
+		other := &game.Rankings[i]
+		isSelf := other.PlayerID != nil && *other.PlayerID == playerID
+		if !isSelf && !isTeammate(ranking, other) {
+			opponents++
+		}
 	}
 
-	// Create a map of playerID to their ranking position
-	playerPositions := make(map[string]int)
-	for _, r := range game.Rankings {
-		if r.PlayerID != nil {
-			playerPositions[*r.PlayerID] = r.Position
-		}
+	if opponents == 0 {
+		return currentElo // No change if playing alone
 	}
 
 	currentPosition := ranking.Position
@@ -179,6 +371,9 @@ func (h *EventHandlers) calculateMultiplayerElo(playerID string, ranking *core.R
 		if otherRanking.PlayerID == nil || *otherRanking.PlayerID == playerID {
 			continue // Skip self
 		}
+		if isTeammate(ranking, &otherRanking) {
+			continue
+		}
 
 		otherPlayerID := *otherRanking.PlayerID
 		otherElo := allPlayerStats[otherPlayerID].Elo
@@ -201,7 +396,7 @@ func (h *EventHandlers) calculateMultiplayerElo(playerID string, ranking *core.R
 	}
 
 	// Apply the formula: R'i = Ri + K/(N-1) * Σ(Sij - Eij)
-	eloChange := (kFactor / float64(numPlayers-1)) * totalChange
+	eloChange := (kFactor / float64(opponents)) * totalChange
 	newElo := currentElo + int(eloChange)
 
 	// Ensure ELO doesn't go below 0
@@ -212,13 +407,18 @@ func (h *EventHandlers) calculateMultiplayerElo(playerID string, ranking *core.R
 	return newElo
 }
 
+// isTeammate reports whether two rankings play on the same team
+func isTeammate(a, b *core.Ranking) bool {
+	return a.Team != nil && b.Team != nil && *a.Team == *b.Team
+}
+
 // calculateRollingWinrate computes a true moving average winrate over the last N games
-func (h *EventHandlers) calculateRollingWinrate(playerID string, won bool) float64 {
+func (h *EventHandlers) calculateRollingWinrate(playerID, format string, won bool) float64 {
 	// Use a window of 10 games for the moving average
 	windowSize := 10
 
 	// Fetch the last N stats entries for this player
-	recentStats, _, err := h.repo.GetPlayerStatsTimeSeries(playerID, windowSize, 0)
+	recentStats, _, err := h.repo.GetPlayerStatsTimeSeries(playerID, format, windowSize, 0)
 	if err != nil || len(recentStats) == 0 {
 		// If we can't fetch history, return simple result
 		if won {
@@ -9,46 +9,73 @@ import (
 type PlayerStats struct {
 	gorm.Model
 	PlayerID       string    `gorm:"index;not null"`
+	Format         string    `gorm:"index;not null;default:'commander'"` // Stats are kept separately per format
 	Timestamp      time.Time `gorm:"index;not null"`
 	TotalWins      int
 	Winrate        float64
 	RollingWinrate float64 // Winrate over last N games (moving average)
 	GameCount      int
-	GameDuration   int // Total game duration in minutes
+	GameDuration   int // Total game duration in seconds, paused time excluded
+	TimedGames     int // Games with a known duration
 	Streak         int // Current win/loss streak (positive = wins, negative = losses)
 	Elo            int
+	TurnCount      int // Total number of turns taken in games with turn tracking
+	TurnDuration   int // Total duration of those turns in seconds
+	TrackedWins    int // Wins in games with turn tracking
+	WinTurnTotal   int // Sum of the rounds those wins happened in
 }
 
 // PlayerStatsResponse is the DTO for API responses with snake_case JSON tags
 type PlayerStatsResponse struct {
-	ID             uint      `json:"id"`
-	PlayerID       string    `json:"player_id"`
-	Timestamp      time.Time `json:"timestamp"`
-	TotalWins      int       `json:"total_wins"`
-	Winrate        float64   `json:"winrate"`
-	RollingWinrate float64   `json:"rolling_winrate"`
-	GameCount      int       `json:"game_count"`
-	GameDuration   int       `json:"game_duration"`
-	Streak         int       `json:"streak"`
-	Elo            int       `json:"elo"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID                 uint      `json:"id"`
+	PlayerID           string    `json:"player_id"`
+	Format             string    `json:"format"`
+	Timestamp          time.Time `json:"timestamp"`
+	TotalWins          int       `json:"total_wins"`
+	Winrate            float64   `json:"winrate"`
+	RollingWinrate     float64   `json:"rolling_winrate"`
+	GameCount          int       `json:"game_count"`
+	GameDuration       int       `json:"game_duration"`
+	AverageGameSeconds float64   `json:"average_game_seconds"` // Over the games with a known duration
+	Streak             int       `json:"streak"`
+	Elo                int       `json:"elo"`
+	TurnCount          int       `json:"turn_count"`
+	AverageTurnSeconds float64   `json:"average_turn_seconds"`
+	AverageWinTurn     float64   `json:"average_win_turn"` // Average round of the wins with turn tracking
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 // ToResponse converts PlayerStats to PlayerStatsResponse
 func (ps *PlayerStats) ToResponse() PlayerStatsResponse {
+	var averageGameSeconds, averageTurnSeconds, averageWinTurn float64
+	if ps.TimedGames > 0 {
+		averageGameSeconds = float64(ps.GameDuration) / float64(ps.TimedGames)
+	}
+	if ps.TurnCount > 0 {
+		averageTurnSeconds = float64(ps.TurnDuration) / float64(ps.TurnCount)
+	}
+	if ps.TrackedWins > 0 {
+		averageWinTurn = float64(ps.WinTurnTotal) / float64(ps.TrackedWins)
+	}
+
 	return PlayerStatsResponse{
-		ID:             ps.ID,
-		PlayerID:       ps.PlayerID,
-		Timestamp:      ps.Timestamp,
-		TotalWins:      ps.TotalWins,
-		Winrate:        ps.Winrate,
-		RollingWinrate: ps.RollingWinrate,
-		GameCount:      ps.GameCount,
-		GameDuration:   ps.GameDuration,
-		Streak:         ps.Streak,
-		Elo:            ps.Elo,
-		CreatedAt:      ps.CreatedAt,
-		UpdatedAt:      ps.UpdatedAt,
+		ID:                 ps.ID,
+		PlayerID:           ps.PlayerID,
+		Format:             ps.Format,
+		Timestamp:          ps.Timestamp,
+		TotalWins:          ps.TotalWins,
+		Winrate:            ps.Winrate,
+		RollingWinrate:     ps.RollingWinrate,
+		GameCount:          ps.GameCount,
+		GameDuration:       ps.GameDuration,
+		AverageGameSeconds: averageGameSeconds,
+		Streak:             ps.Streak,
+		Elo:                ps.Elo,
+		TurnCount:          ps.TurnCount,
+		AverageTurnSeconds: averageTurnSeconds,
+		AverageWinTurn:     averageWinTurn,
+		CreatedAt:          ps.CreatedAt,
+		UpdatedAt:          ps.UpdatedAt,
 	}
 }
@@ -19,18 +19,29 @@ func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{DB: db}
 }
 
-// GetPlayerStatsTimeSeries retrieves all statistics for a specific player ordered by timestamp
-func (r *Repository) GetPlayerStatsTimeSeries(playerID string, limit, offset int) ([]PlayerStats, int64, error) {
+// GetPlayersWithoutTimedGames returns the players whose statistics sum game durations without counting timed games
+// Their statistics were recorded before TimedGames was counted, rebuilding them counts it
+func (r *Repository) GetPlayersWithoutTimedGames() ([]string, error) {
+	var playerIDs []string
+	err := r.DB.Model(&PlayerStats{}).
+		Where("timed_games = 0 AND game_duration <> 0").
+		Distinct().
+		Pluck("player_id", &playerIDs).Error
+	return playerIDs, err
+}
+
+// GetPlayerStatsTimeSeries retrieves all statistics of a format for a specific player ordered by timestamp
+func (r *Repository) GetPlayerStatsTimeSeries(playerID, format string, limit, offset int) ([]PlayerStats, int64, error) {
 	var stats []PlayerStats
 	var total int64
 
 	// Get total count for this player
-	if err := r.DB.Model(&PlayerStats{}).Where("player_id = ?", playerID).Count(&total).Error; err != nil {
+	if err := r.DB.Model(&PlayerStats{}).Where("player_id = ? AND format = ?", playerID, format).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results ordered by timestamp descending (most recent first)
-	err := r.DB.Where("player_id = ?", playerID).
+	err := r.DB.Where("player_id = ? AND format = ?", playerID, format).
 		Order("timestamp DESC").
 		Limit(limit).
 		Offset(offset).
@@ -42,10 +53,22 @@ func (r *Repository) GetPlayerStatsTimeSeries(playerID string, limit, offset int
 	return stats, total, nil
 }
 
-// GetLatestPlayerStats retrieves the most recent statistics for a specific player
-func (r *Repository) GetLatestPlayerStats(playerID string) (*PlayerStats, error) {
+// GetLatestPlayerStats retrieves the most recent statistics of a format for a specific player
+func (r *Repository) GetLatestPlayerStats(playerID, format string) (*PlayerStats, error) {
 	var stats PlayerStats
-	err := r.DB.Where("player_id = ?", playerID).
+	err := r.DB.Where("player_id = ? AND format = ?", playerID, format).
+		Order("timestamp DESC").
+		First(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetPlayerStatsBefore retrieves the statistics of a format a player had at a point in time
+func (r *Repository) GetPlayerStatsBefore(playerID, format string, at time.Time) (*PlayerStats, error) {
+	var stats PlayerStats
+	err := r.DB.Where("player_id = ? AND format = ? AND timestamp < ?", playerID, format, at).
 		Order("timestamp DESC").
 		First(&stats).Error
 	if err != nil {
@@ -62,13 +85,14 @@ func (r *Repository) CreatePlayerStats(stats *PlayerStats) error {
 	return r.DB.Create(stats).Error
 }
 
-// GetAllLatestPlayerStats retrieves the most recent statistics for all players with pagination
-func (r *Repository) GetAllLatestPlayerStats(limit, offset int) ([]PlayerStats, int64, error) {
+// GetAllLatestPlayerStats retrieves the most recent statistics of a format for all players with pagination
+func (r *Repository) GetAllLatestPlayerStats(format string, limit, offset int) ([]PlayerStats, int64, error) {
 	var stats []PlayerStats
 
 	// Subquery to get the latest timestamp for each player
 	subQuery := r.DB.Model(&PlayerStats{}).
 		Select("player_id, MAX(timestamp) as max_timestamp").
+		Where("format = ?", format).
 		Group("player_id")
 
 	// Get total count of unique players
@@ -81,6 +105,7 @@ func (r *Repository) GetAllLatestPlayerStats(limit, offset int) ([]PlayerStats,
 	err := r.DB.Table("player_stats").
 		Select("player_stats.*").
 		Joins("INNER JOIN (?) as latest ON player_stats.player_id = latest.player_id AND player_stats.timestamp = latest.max_timestamp", subQuery).
+		Where("player_stats.format = ?", format).
 		Order("player_stats.elo DESC").
 		Limit(limit).
 		Offset(offset).
@@ -92,6 +117,11 @@ func (r *Repository) GetAllLatestPlayerStats(limit, offset int) ([]PlayerStats,
 	return stats, total, nil
 }
 
+// LockPlayerStats holds a lock on the statistics of a player until the surrounding transaction ends
+func (r *Repository) LockPlayerStats(playerID string) error {
+	return r.DB.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "player_stats:"+playerID).Error
+}
+
 // DeletePlayerStats removes all statistics entries for a specific player
 func (r *Repository) DeletePlayerStats(playerID string) error {
 	return r.DB.Where("player_id = ?", playerID).Delete(&PlayerStats{}).Error
@@ -2,7 +2,9 @@ package statistics
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"mtgtracker/internal/core"
 	"mtgtracker/internal/middleware"
 	"mtgtracker/internal/pagination"
 	"net/http"
@@ -25,6 +27,18 @@ func (s *Service) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /statistics/v1/me/timeseries", s.GetMyStatsTimeSeries)
 }
 
+// parseFormat reads the format query parameter, defaulting to commander
+func parseFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return core.FormatCommander, nil
+	}
+	if _, ok := core.GetFormatRules(format); !ok {
+		return "", fmt.Errorf("unknown format: %s", format)
+	}
+	return format, nil
+}
+
 // GetLatestPlayerStats retrieves the most recent statistics for a specific player
 func (s *Service) GetLatestPlayerStats(w http.ResponseWriter, r *http.Request) {
 	playerID := r.PathValue("playerId")
@@ -33,7 +47,13 @@ func (s *Service) GetLatestPlayerStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := s.repo.GetLatestPlayerStats(playerID)
+	format, err := parseFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.repo.GetLatestPlayerStats(playerID, format)
 	if err != nil {
 		http.Error(w, "Statistics not found", http.StatusNotFound)
 		return
@@ -54,9 +74,14 @@ func (s *Service) GetPlayerStatsTimeSeries(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	format, err := parseFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	p := pagination.ParsePagination(r)
 
-	stats, total, err := s.repo.GetPlayerStatsTimeSeries(playerID, p.PerPage, p.Offset())
+	stats, total, err := s.repo.GetPlayerStatsTimeSeries(playerID, format, p.PerPage, p.Offset())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -88,7 +113,13 @@ func (s *Service) GetMyLatestStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := s.repo.GetLatestPlayerStats(userID)
+	format, err := parseFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.repo.GetLatestPlayerStats(userID, format)
 	if err != nil {
 		http.Error(w, "Statistics not found", http.StatusNotFound)
 		return
@@ -109,9 +140,14 @@ func (s *Service) GetMyStatsTimeSeries(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format, err := parseFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	p := pagination.ParsePagination(r)
 
-	stats, total, err := s.repo.GetPlayerStatsTimeSeries(userID, p.PerPage, p.Offset())
+	stats, total, err := s.repo.GetPlayerStatsTimeSeries(userID, format, p.PerPage, p.Offset())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -137,9 +173,14 @@ func (s *Service) GetMyStatsTimeSeries(w http.ResponseWriter, r *http.Request) {
 
 // GetAllLatestPlayerStats retrieves the most recent statistics for all players with pagination
 func (s *Service) GetAllLatestPlayerStats(w http.ResponseWriter, r *http.Request) {
+	format, err := parseFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	p := pagination.ParsePagination(r)
 
-	stats, total, err := s.repo.GetAllLatestPlayerStats(p.PerPage, p.Offset())
+	stats, total, err := s.repo.GetAllLatestPlayerStats(format, p.PerPage, p.Offset())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
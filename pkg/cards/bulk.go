@@ -0,0 +1,119 @@
+package cards
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// scryfallCard is a card object of a Scryfall bulk data file, see https://scryfall.com/docs/api/bulk-data
+type scryfallCard struct {
+	ID            string            `json:"id"`
+	OracleID      string            `json:"oracle_id"`
+	Name          string            `json:"name"`
+	Layout        string            `json:"layout"`
+	TypeLine      string            `json:"type_line"`
+	OracleText    string            `json:"oracle_text"`
+	ColorIdentity []string          `json:"color_identity"`
+	Legalities    map[string]string `json:"legalities"`
+	ImageURIs     map[string]string `json:"image_uris"`
+	CardFaces     []struct {
+		Name       string `json:"name"`
+		TypeLine   string `json:"type_line"`
+		OracleText string `json:"oracle_text"`
+	} `json:"card_faces"`
+}
+
+// Load reads a Scryfall bulk data file, oracle cards or default cards, into memory
+// Only the file is read, no network access is needed
+func Load(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bulk data file: %w", err)
+	}
+	defer file.Close()
+	return LoadReader(file)
+}
+
+// LoadReader reads Scryfall bulk data, a JSON array of cards
+// The array is decoded card by card, so the file is never in memory as a whole
+func LoadReader(r io.Reader) (*Database, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("bulk data is not a JSON array of cards")
+	}
+
+	db := newDatabase()
+	for decoder.More() {
+		var card scryfallCard
+		if err := decoder.Decode(&card); err != nil {
+			return nil, fmt.Errorf("failed to decode card %d: %w", db.Len()+1, err)
+		}
+		if card.OracleID == "" || card.Name == "" {
+			continue // Reversible cards keep their oracle ID on the faces, the front print covers them
+		}
+		db.add(card.toCard())
+	}
+	return db, nil
+}
+
+// frontFace returns the type line and text of a card's front face
+func (c *scryfallCard) frontFace() (string, string) {
+	if len(c.CardFaces) > 0 && c.CardFaces[0].TypeLine != "" {
+		return c.CardFaces[0].TypeLine, c.CardFaces[0].OracleText
+	}
+	return c.TypeLine, c.OracleText
+}
+
+// canBeCommander reports whether a card is legal in commander and can lead a deck
+// Legendary creatures can, as can backgrounds in a pairing and cards that say so
+func (c *scryfallCard) canBeCommander() bool {
+	if c.Legalities["commander"] != "legal" {
+		return false
+	}
+	typeLine, text := c.frontFace()
+	if strings.Contains(strings.ToLower(text), "can be your commander") {
+		return true
+	}
+	if !strings.Contains(typeLine, "Legendary") {
+		return false
+	}
+	return strings.Contains(typeLine, "Creature") || strings.Contains(typeLine, "Background")
+}
+
+func (c *scryfallCard) toCard() Card {
+	card := Card{
+		OracleID:      c.OracleID,
+		ScryfallID:    c.ID,
+		Name:          c.Name,
+		TypeLine:      c.TypeLine,
+		OracleText:    c.oracleText(),
+		ColorIdentity: c.ColorIdentity,
+		Commander:     c.canBeCommander(),
+		Image:         ImageURL(c.ID, "normal", "front"),
+		Crop:          ImageURL(c.ID, "art_crop", "front"),
+	}
+	if card.ColorIdentity == nil {
+		card.ColorIdentity = []string{}
+	}
+	// Double-faced cards have an image per face, split and adventure cards share one
+	if len(c.CardFaces) > 1 && c.ImageURIs == nil {
+		card.SecondaryImage = ImageURL(c.ID, "normal", "back")
+		card.SecondaryCrop = ImageURL(c.ID, "art_crop", "back")
+	}
+	return card
+}
+
+// oracleText returns the rules text of a card, cards with multiple faces keep it on the faces
+func (c *scryfallCard) oracleText() string {
+	if len(c.CardFaces) == 0 {
+		return c.OracleText
+	}
+	texts := make([]string, len(c.CardFaces))
+	for i, face := range c.CardFaces {
+		texts[i] = face.OracleText
+	}
+	return strings.Join(texts, "\n//\n")
+}
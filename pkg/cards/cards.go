@@ -0,0 +1,78 @@
+package cards
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Card is a card of the Scryfall bulk data, one per oracle card
+type Card struct {
+	OracleID       string   `json:"oracle_id"`
+	ScryfallID     string   `json:"scryfall_id"` // The printing the images are taken from
+	Name           string   `json:"name"`
+	TypeLine       string   `json:"type_line"`
+	OracleText     string   `json:"oracle_text"`    // The texts of multiple faces are separated by a line with //
+	ColorIdentity  []string `json:"color_identity"` // Scryfall color codes: W, U, B, R, G
+	Commander      bool     `json:"commander"`      // Legal as a commander, alone or in a pairing
+	Image          string   `json:"image"`
+	SecondaryImage string   `json:"secondary_image,omitempty"` // The back face of a double-faced card
+	Crop           string   `json:"crop"`
+	SecondaryCrop  string   `json:"secondary_crop,omitempty"`
+}
+
+// ImageURL constructs a Scryfall image URL from a scryfall_id
+// face can be "front" or "back"
+func ImageURL(scryfallID, imageType, face string) string {
+	if len(scryfallID) < 2 {
+		return ""
+	}
+	// Extract first two characters for path segments
+	dir1 := string(scryfallID[0])
+	dir2 := string(scryfallID[1])
+
+	return fmt.Sprintf("https://cards.scryfall.io/%s/%s/%s/%s/%s.jpg", imageType, face, dir1, dir2, scryfallID)
+}
+
+// CombinedColorIdentity returns the color identity of a pairing, in WUBRG order
+// A pairing without colors is colorless: C
+func CombinedColorIdentity(cards ...*Card) []string {
+	seen := make(map[string]bool)
+	for _, card := range cards {
+		if card == nil {
+			continue
+		}
+		for _, color := range card.ColorIdentity {
+			seen[color] = true
+		}
+	}
+	if len(seen) == 0 {
+		return []string{"C"}
+	}
+
+	colors := make([]string, 0, len(seen))
+	for _, color := range []string{"W", "U", "B", "R", "G"} {
+		if seen[color] {
+			colors = append(colors, color)
+		}
+	}
+	return colors
+}
+
+// normalizeName lowercases a name and drops punctuation, so "Krenko, Mob Boss" matches "krenko mob boss"
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r > 127:
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case r == ' ', r == '-', r == '/':
+			space = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,238 @@
+package cards
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const bulkExample = `[
+	{"id": "a3c4e2e0-1c0e-475d-a0f4-1be4216c2bad", "oracle_id": "o-krenko", "name": "Krenko, Mob Boss", "layout": "normal",
+	 "type_line": "Legendary Creature — Goblin Warrior", "color_identity": ["R"], "legalities": {"commander": "legal"},
+	 "image_uris": {"normal": "https://cards.scryfall.io/normal/front/a/3/a3c4e2e0.jpg"}},
+	{"id": "b1c2d3e4-0000-0000-0000-000000000000", "oracle_id": "o-krenko", "name": "Krenko, Mob Boss", "layout": "normal",
+	 "type_line": "Legendary Creature — Goblin Warrior", "color_identity": ["R"], "legalities": {"commander": "legal"},
+	 "image_uris": {"normal": "https://cards.scryfall.io/normal/front/b/1/b1c2d3e4.jpg"}},
+	{"id": "c5d6e7f8-0000-0000-0000-000000000000", "oracle_id": "o-norman", "name": "Norman Osborn // Green Goblin", "layout": "transform",
+	 "type_line": "Legendary Creature — Human Scientist // Legendary Creature — Goblin Villain", "color_identity": ["B", "R", "U"],
+	 "legalities": {"commander": "legal"},
+	 "card_faces": [{"name": "Norman Osborn", "type_line": "Legendary Creature — Human Scientist", "oracle_text": "Flying"},
+	                {"name": "Green Goblin", "type_line": "Legendary Creature — Goblin Villain", "oracle_text": "Menace"}]},
+	{"id": "d9e0f1a2-0000-0000-0000-000000000000", "oracle_id": "o-candlekeep", "name": "Candlekeep Sage", "layout": "normal",
+	 "type_line": "Legendary Enchantment — Background", "color_identity": ["U"], "legalities": {"commander": "legal"}},
+	{"id": "e3f4a5b6-0000-0000-0000-000000000000", "oracle_id": "o-teferi", "name": "Teferi, Temporal Archmage", "layout": "normal",
+	 "type_line": "Legendary Planeswalker — Teferi", "oracle_text": "Teferi, Temporal Archmage can be your commander.",
+	 "color_identity": ["U"], "legalities": {"commander": "legal"}},
+	{"id": "f7a8b9c0-0000-0000-0000-000000000000", "oracle_id": "o-goblin", "name": "Goblin Guide", "layout": "normal",
+	 "type_line": "Creature — Goblin Scout", "color_identity": ["R"], "legalities": {"commander": "legal"}},
+	{"id": "a1b2c3d4-0000-0000-0000-000000000000", "oracle_id": "o-braids", "name": "Braids, Cabal Minion", "layout": "normal",
+	 "type_line": "Legendary Creature — Human Minion", "color_identity": ["B"], "legalities": {"commander": "banned"}},
+	{"id": "0a0b0c0d-0000-0000-0000-000000000000", "oracle_id": "o-sol", "name": "Sol Ring", "layout": "normal",
+	 "type_line": "Artifact", "color_identity": [], "legalities": {"commander": "legal"}}
+]`
+
+func loadExample(t *testing.T) *Database {
+	t.Helper()
+	db, err := LoadReader(strings.NewReader(bulkExample))
+	if err != nil {
+		t.Fatalf("LoadReader failed: %v", err)
+	}
+	return db
+}
+
+func TestLoadReader(t *testing.T) {
+	db := loadExample(t)
+	if db.Len() != 7 {
+		t.Fatalf("expected 7 cards with the reprint skipped, got %d", db.Len())
+	}
+
+	krenko, ok := db.Get("Krenko, Mob Boss")
+	if !ok {
+		t.Fatal("expected Krenko, Mob Boss")
+	}
+	if krenko.ScryfallID != "a3c4e2e0-1c0e-475d-a0f4-1be4216c2bad" {
+		t.Errorf("expected the first printing, got %s", krenko.ScryfallID)
+	}
+	if krenko.Image != ImageURL(krenko.ScryfallID, "normal", "front") || krenko.Crop != ImageURL(krenko.ScryfallID, "art_crop", "front") {
+		t.Errorf("unexpected images %q and %q", krenko.Image, krenko.Crop)
+	}
+	if krenko.SecondaryImage != "" {
+		t.Errorf("expected no back face, got %q", krenko.SecondaryImage)
+	}
+
+	norman, ok := db.Get("Norman Osborn")
+	if !ok || norman.Name != "Norman Osborn // Green Goblin" {
+		t.Fatalf("expected the double-faced card by its front face, got %+v", norman)
+	}
+	if norman.SecondaryImage != ImageURL(norman.ScryfallID, "normal", "back") || norman.SecondaryCrop != ImageURL(norman.ScryfallID, "art_crop", "back") {
+		t.Errorf("expected the back face images, got %q and %q", norman.SecondaryImage, norman.SecondaryCrop)
+	}
+	if norman.OracleText != "Flying\n//\nMenace" {
+		t.Errorf("expected the texts of both faces, got %q", norman.OracleText)
+	}
+
+	if _, err := LoadReader(strings.NewReader(`{"object": "card"}`)); err == nil {
+		t.Error("expected an error for a file that isn't a list of cards")
+	}
+}
+
+func TestCommanderLegality(t *testing.T) {
+	db := loadExample(t)
+	tests := []struct {
+		name      string
+		commander bool
+	}{
+		{"Krenko, Mob Boss", true},
+		{"Norman Osborn // Green Goblin", true},
+		{"Candlekeep Sage", true},           // Backgrounds are commanders in a pairing
+		{"Teferi, Temporal Archmage", true}, // Says it can be your commander
+		{"Goblin Guide", false},             // Not legendary
+		{"Braids, Cabal Minion", false},     // Banned
+		{"Sol Ring", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, ok := db.Get(tt.name)
+			if !ok {
+				t.Fatalf("expected %s", tt.name)
+			}
+			if card.Commander != tt.commander {
+				t.Errorf("expected commander %v, got %v", tt.commander, card.Commander)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	db := loadExample(t)
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"krenko mob boss", []string{"Krenko, Mob Boss"}},
+		{"gob", []string{"Goblin Guide", "Norman Osborn // Green Goblin"}}, // Prefix before word prefix
+		{"green goblin", []string{"Norman Osborn // Green Goblin"}},        // The back face
+		{"krenkp", []string{"Krenko, Mob Boss"}},                           // A typo
+		{"xyzzy", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var names []string
+			for _, card := range db.Search(tt.query, 10, nil) {
+				names = append(names, card.Name)
+			}
+			if strings.Join(names, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+
+	commanders := db.Search("goblin", 10, func(card *Card) bool { return card.Commander })
+	if len(commanders) != 1 || commanders[0].Name != "Norman Osborn // Green Goblin" {
+		t.Errorf("expected only the legendary goblin, got %v", commanders)
+	}
+	if card, ok := db.Find("Krenk, Mob Boss"); !ok || card.Name != "Krenko, Mob Boss" {
+		t.Errorf("expected Find to correct the name, got %v", card)
+	}
+}
+
+func TestCombinedColorIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		cards    []*Card
+		expected string
+	}{
+		{"single", []*Card{{ColorIdentity: []string{"R"}}}, "R"},
+		{"pairing in WUBRG order", []*Card{{ColorIdentity: []string{"G", "B"}}, {ColorIdentity: []string{"W"}}}, "WBG"},
+		{"colorless", []*Card{{ColorIdentity: []string{}}}, "C"},
+		{"unknown partner", []*Card{{ColorIdentity: []string{"U"}}, nil}, "U"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if colors := strings.Join(CombinedColorIdentity(tt.cards...), ""); colors != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, colors)
+			}
+		})
+	}
+}
+
+func TestSearchCommanders(t *testing.T) {
+	db := loadExample(t)
+	tests := []struct {
+		name     string
+		db       *Database
+		url      string
+		status   int
+		expected []string
+	}{
+		{"prefix", db, "/cards/v1/commanders?q=kren", http.StatusOK, []string{"Krenko, Mob Boss"}},
+		{"only commanders", db, "/cards/v1/commanders?q=gob", http.StatusOK, []string{"Norman Osborn // Green Goblin"}},
+		{"typo", db, "/cards/v1/commanders?q=tefero", http.StatusOK, []string{"Teferi, Temporal Archmage"}},
+		{"limit", db, "/cards/v1/commanders?q=e&limit=2", http.StatusOK, []string{"Candlekeep Sage", "Krenko, Mob Boss"}},
+		{"no match", db, "/cards/v1/commanders?q=sol+ring", http.StatusOK, []string{}},
+		{"missing query", db, "/cards/v1/commanders", http.StatusBadRequest, nil},
+		{"invalid limit", db, "/cards/v1/commanders?q=kren&limit=0", http.StatusBadRequest, nil},
+		{"not loaded", nil, "/cards/v1/commanders?q=kren", http.StatusServiceUnavailable, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			NewService(tt.db).RegisterRoutes(mux)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var result []Card
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			names := []string{}
+			for _, card := range result {
+				names = append(names, card.Name)
+			}
+			if strings.Join(names, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestResolveCommander(t *testing.T) {
+	db := loadExample(t)
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Krenko, Mob Boss", "Krenko, Mob Boss"},
+		{"krenko mob boss", "Krenko, Mob Boss"},
+		{"Krenko", "Krenko, Mob Boss"}, // Short name
+		{"Krenko, Mob Bos", "Krenko, Mob Boss"},
+		{"Norman Osborn", "Norman Osborn // Green Goblin"},
+		{"Teferi", "Teferi, Temporal Archmage"},
+		{"Braids", ""}, // Banned as a commander, only the exact name resolves
+		{"Braids, Cabal Minion", "Braids, Cabal Minion"},
+		{"Homebrew Commander", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, ok := db.ResolveCommander(tt.name)
+			if tt.expected == "" {
+				if ok {
+					t.Errorf("expected no match, got %s", card.Name)
+				}
+				return
+			}
+			if !ok || card.Name != tt.expected {
+				t.Errorf("expected %s, got %v", tt.expected, card)
+			}
+		})
+	}
+}
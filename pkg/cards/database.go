@@ -0,0 +1,231 @@
+package cards
+
+import (
+	"sort"
+	"strings"
+)
+
+// Database is an in-memory card database, safe for concurrent reads once loaded
+type Database struct {
+	cards       []Card
+	names       []string         // Normalized names of the cards, so searches don't normalize every card again
+	byName      map[string]int   // Normalized full name and front face name to the index in cards
+	byShortName map[string][]int // Normalized name before the comma of commanders, "Atraxa" for "Atraxa, Praetors' Voice"
+	seen        map[string]bool
+}
+
+func newDatabase() *Database {
+	return &Database{
+		byName:      make(map[string]int),
+		byShortName: make(map[string][]int),
+		seen:        make(map[string]bool),
+	}
+}
+
+// add adds a card, bulk files with every printing only keep the first printing of a card
+func (db *Database) add(card Card) {
+	if db.seen[card.OracleID] {
+		return
+	}
+	db.seen[card.OracleID] = true
+	name := normalizeName(card.Name)
+	db.cards = append(db.cards, card)
+	db.names = append(db.names, name)
+
+	index := len(db.cards) - 1
+	db.byName[name] = index
+	front, _, ok := strings.Cut(card.Name, " // ")
+	if ok {
+		if _, exists := db.byName[normalizeName(front)]; !exists {
+			db.byName[normalizeName(front)] = index
+		}
+	}
+	if short, _, ok := strings.Cut(front, ","); ok && card.Commander {
+		key := normalizeName(short)
+		db.byShortName[key] = append(db.byShortName[key], index)
+	}
+}
+
+// Len returns the number of cards
+func (db *Database) Len() int {
+	return len(db.cards)
+}
+
+// Get returns the card with a name, ignoring case and punctuation
+// Double-faced cards are found by their full name and by their front face
+func (db *Database) Get(name string) (*Card, bool) {
+	index, ok := db.byName[normalizeName(name)]
+	if !ok {
+		return nil, false
+	}
+	card := db.cards[index]
+	return &card, true
+}
+
+// Find returns the card with a name, or the closest match for a misspelled or partial name
+func (db *Database) Find(name string) (*Card, bool) {
+	if card, ok := db.Get(name); ok {
+		return card, true
+	}
+	matches := db.Search(name, 1, nil)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return &matches[0], true
+}
+
+// ResolveCommander returns the card a commander name written by hand refers to
+// Besides the exact name it accepts the short name of a commander and small typos, as long as only one commander matches
+func (db *Database) ResolveCommander(name string) (*Card, bool) {
+	query := normalizeName(name)
+	if index, ok := db.byName[query]; ok {
+		card := db.cards[index]
+		return &card, true
+	}
+	if matches := db.byShortName[query]; len(matches) == 1 {
+		card := db.cards[matches[0]]
+		return &card, true
+	}
+
+	maxDistance := typoDistance(query)
+	if maxDistance == 0 {
+		return nil, false
+	}
+	match := -1
+	for i := range db.cards {
+		if !db.cards[i].Commander || editDistance(db.names[i], query, maxDistance) > maxDistance {
+			continue
+		}
+		if match >= 0 {
+			return nil, false // Ambiguous
+		}
+		match = i
+	}
+	if match < 0 {
+		return nil, false
+	}
+	card := db.cards[match]
+	return &card, true
+}
+
+// Match kinds of a search, better matches first
+const (
+	matchExact = iota
+	matchPrefix
+	matchWordPrefix
+	matchSubstring
+	matchTypo
+	noMatch
+)
+
+// Search returns up to limit cards whose name matches a query, best matches first
+// Names starting with the query come before names containing it, names within a few typos come last
+// keep filters the cards, nil keeps every card
+func (db *Database) Search(query string, limit int, keep func(*Card) bool) []Card {
+	query = normalizeName(query)
+	if query == "" || limit <= 0 {
+		return []Card{}
+	}
+
+	type match struct {
+		index int
+		kind  int
+	}
+	var matches []match
+	for i := range db.cards {
+		if keep != nil && !keep(&db.cards[i]) {
+			continue
+		}
+		if kind := matchName(db.names[i], query); kind != noMatch {
+			matches = append(matches, match{index: i, kind: kind})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := &db.cards[matches[i].index], &db.cards[matches[j].index]
+		if matches[i].kind != matches[j].kind {
+			return matches[i].kind < matches[j].kind
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return a.Name < b.Name
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]Card, len(matches))
+	for i, m := range matches {
+		result[i] = db.cards[m.index]
+	}
+	return result
+}
+
+// matchName returns how a normalized name matches a normalized query
+func matchName(name, query string) int {
+	switch {
+	case name == query:
+		return matchExact
+	case strings.HasPrefix(name, query):
+		return matchPrefix
+	case strings.Contains(name, " "+query):
+		return matchWordPrefix
+	case strings.Contains(name, query):
+		return matchSubstring
+	}
+
+	maxDistance := typoDistance(query)
+	if maxDistance == 0 {
+		return noMatch
+	}
+	if len(name) > len(query) {
+		name = name[:len(query)]
+	}
+	if editDistance(name, query, maxDistance) <= maxDistance {
+		return matchTypo
+	}
+	return noMatch
+}
+
+// typoDistance returns the number of typos forgiven in a normalized query
+// Typos are only forgiven in queries long enough to tell names apart
+func typoDistance(query string) int {
+	switch {
+	case len(query) < 5:
+		return 0
+	case len(query) < 10:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the Levenshtein distance between two strings, or max+1 once it exceeds max
+func editDistance(a, b string, max int) int {
+	if diff := len(a) - len(b); diff > max || -diff > max {
+		return max + 1
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
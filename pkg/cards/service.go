@@ -0,0 +1,56 @@
+package cards
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+type Service struct {
+	db *Database
+}
+
+// NewService serves the card database, db is nil when no bulk data file was loaded
+func NewService(db *Database) *Service {
+	return &Service{db: db}
+}
+
+func (s *Service) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /cards/v1/commanders", s.SearchCommanders)
+}
+
+// SearchCommanders autocompletes commander names, matching prefixes, words and small typos
+// Only cards that are legal as a commander are returned, with their canonical name, colors and images
+func (s *Service) SearchCommanders(w http.ResponseWriter, r *http.Request) {
+	if s.db == nil {
+		http.Error(w, "Card database is not loaded", http.StatusServiceUnavailable)
+		return
+	}
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxSearchLimit)
+	}
+
+	result := s.db.Search(query, limit, func(card *Card) bool { return card.Commander })
+	err := json.NewEncoder(w).Encode(result)
+	if err != nil {
+		log.Println("Error encoding response:", err)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mtgtracker/pkg/cards"
 	"net/http"
 	"net/url"
+	"sort"
 )
 
 const (
@@ -42,6 +44,10 @@ type Deck struct {
 	Image          string   `json:"image"`
 	SecondaryImage *string  `json:"secondary_image"`
 	Crop           string   `json:"crop"`
+	// The second commander of a pairing, empty for a single commander
+	Partner      string `json:"partner,omitempty"`
+	PartnerImage string `json:"partner_image,omitempty"`
+	PartnerCrop  string `json:"partner_crop,omitempty"`
 }
 
 type searchResponse struct {
@@ -74,29 +80,35 @@ type deckResponse struct {
 	Boards struct {
 		Commanders struct {
 			Cards map[string]struct {
-				Card struct {
-					Name       string `json:"name"`
-					ScryfallID string `json:"scryfall_id"`
-					CardFaces  []struct {
-						Name string `json:"name"`
-					} `json:"card_faces"`
-				} `json:"card"`
+				Card commanderCard `json:"card"`
 			} `json:"cards"`
 		} `json:"commanders"`
 	} `json:"boards"`
 }
 
-// buildScryfallImageURL constructs a Scryfall image URL from a scryfall_id
-// face can be "front" or "back"
-func buildScryfallImageURL(scryfallID, imageType, face string) string {
-	if len(scryfallID) < 2 {
-		return ""
-	}
-	// Extract first two characters for path segments
-	dir1 := string(scryfallID[0])
-	dir2 := string(scryfallID[1])
+type commanderCard struct {
+	Name       string `json:"name"`
+	ScryfallID string `json:"scryfall_id"`
+	CardFaces  []struct {
+		Name string `json:"name"`
+	} `json:"card_faces"`
+}
 
-	return fmt.Sprintf("https://cards.scryfall.io/%s/%s/%s/%s/%s.jpg", imageType, face, dir1, dir2, scryfallID)
+// commanders returns the commanders of a deck, the deck's main card first and a partner after it
+// Moxfield keys the commanders board by an ID, so the other cards are sorted by name to keep the order stable
+func (d *deckResponse) commanders() []commanderCard {
+	cards := make([]commanderCard, 0, len(d.Boards.Commanders.Cards))
+	for _, entry := range d.Boards.Commanders.Cards {
+		cards = append(cards, entry.Card)
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		iMain, jMain := cards[i].ScryfallID == d.Main.ScryfallID, cards[j].ScryfallID == d.Main.ScryfallID
+		if iMain != jMain {
+			return iMain
+		}
+		return cards[i].Name < cards[j].Name
+	})
+	return cards
 }
 
 func GetDecksForUser(username string) ([]Deck, error) {
@@ -208,28 +220,33 @@ func GetDeckByID(deckID string) (*Deck, error) {
 		themes = append(themes, hub.Name)
 	}
 
+	return deckFromResponse(deckID, &deckResp, themes), nil
+}
+
+// deckFromResponse builds a deck from Moxfield's deck details, keeping both commanders of a pairing
+func deckFromResponse(deckID string, deckResp *deckResponse, themes []string) *Deck {
 	// Extract commander name and scryfall_id from commanders board
 	var commanderName, scryfallID string
 	var hasCardFaces bool
-	for _, commanderCard := range deckResp.Boards.Commanders.Cards {
-		commanderName = commanderCard.Card.Name
-		scryfallID = commanderCard.Card.ScryfallID
-		hasCardFaces = len(commanderCard.Card.CardFaces) > 0
-		break // Take the first commander
+	commanders := deckResp.commanders()
+	if len(commanders) > 0 {
+		commanderName = commanders[0].Name
+		scryfallID = commanders[0].ScryfallID
+		hasCardFaces = len(commanders[0].CardFaces) > 0
 	}
 
 	// Build image URLs from scryfall_id
-	imageURL := buildScryfallImageURL(scryfallID, "normal", "front")
-	cropURL := buildScryfallImageURL(scryfallID, "art_crop", "front")
+	imageURL := cards.ImageURL(scryfallID, "normal", "front")
+	cropURL := cards.ImageURL(scryfallID, "art_crop", "front")
 
 	// If card has multiple faces, secondary image is the back face
 	var secondaryImageURL *string
 	if hasCardFaces {
-		backImageURL := buildScryfallImageURL(scryfallID, "normal", "back")
+		backImageURL := cards.ImageURL(scryfallID, "normal", "back")
 		secondaryImageURL = &backImageURL
 	}
 
-	return &Deck{
+	deck := &Deck{
 		ID:             deckResp.ID,
 		Name:           deckResp.Name,
 		MoxfieldID:     deckID,
@@ -240,5 +257,13 @@ func GetDeckByID(deckID string) (*Deck, error) {
 		Image:          imageURL,
 		SecondaryImage: secondaryImageURL,
 		Crop:           cropURL,
-	}, nil
+	}
+
+	// Partners, backgrounds and companions are the second card of the commanders board
+	if len(commanders) > 1 {
+		deck.Partner = commanders[1].Name
+		deck.PartnerImage = cards.ImageURL(commanders[1].ScryfallID, "normal", "front")
+		deck.PartnerCrop = cards.ImageURL(commanders[1].ScryfallID, "art_crop", "front")
+	}
+	return deck
 }
@@ -60,3 +60,65 @@ func TestGetDecksForUser(t *testing.T) {
 		}
 	}
 }
+
+func TestDeckFromResponseCommanders(t *testing.T) {
+	tests := []struct {
+		name      string
+		main      string
+		cards     map[string][2]string // Board key to name and scryfall_id
+		commander string
+		partner   string
+	}{
+		{
+			name:      "single commander",
+			main:      "a3c4e2e0",
+			cards:     map[string][2]string{"x": {"The Jolly Balloon Man", "a3c4e2e0"}},
+			commander: "The Jolly Balloon Man",
+		},
+		{
+			name: "main card first",
+			main: "f1e2d3c4",
+			cards: map[string][2]string{
+				"a": {"Wilson, Refined Grizzly", "b1c2d3e4"},
+				"b": {"Tymna the Weaver", "f1e2d3c4"},
+			},
+			commander: "Tymna the Weaver",
+			partner:   "Wilson, Refined Grizzly",
+		},
+		{
+			name: "sorted by name without a main card",
+			cards: map[string][2]string{
+				"a": {"Thrasios, Triton Hero", "b1c2d3e4"},
+				"b": {"Kraum, Ludevic's Opus", "f1e2d3c4"},
+			},
+			commander: "Kraum, Ludevic's Opus",
+			partner:   "Thrasios, Triton Hero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp deckResponse
+			resp.Main.ScryfallID = tt.main
+			resp.Boards.Commanders.Cards = make(map[string]struct {
+				Card commanderCard `json:"card"`
+			})
+			for key, card := range tt.cards {
+				entry := resp.Boards.Commanders.Cards[key]
+				entry.Card = commanderCard{Name: card[0], ScryfallID: card[1]}
+				resp.Boards.Commanders.Cards[key] = entry
+			}
+
+			deck := deckFromResponse("deck", &resp, nil)
+			if deck.Commander != tt.commander || deck.Partner != tt.partner {
+				t.Errorf("expected %q and %q, got %q and %q", tt.commander, tt.partner, deck.Commander, deck.Partner)
+			}
+			if tt.partner != "" && (deck.PartnerImage == "" || deck.PartnerCrop == "") {
+				t.Errorf("expected images of the partner, got %+v", deck)
+			}
+			if tt.partner == "" && deck.PartnerImage != "" {
+				t.Errorf("expected no partner image, got %q", deck.PartnerImage)
+			}
+		})
+	}
+}
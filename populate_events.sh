@@ -67,7 +67,7 @@ while true; do
   new_life=$(( tgt_life - delta ))
 
   # Post event
-  http POST $url damage_delta:=$delta life_total_after:=$new_life source_ranking_id:=$src_id target_ranking_id:=$tgt_id
+  http POST $url event_type=decrement damage_delta:=-$delta life_total_after:=$new_life source_ranking_id:=$src_id target_ranking_id:=$tgt_id
 
   # Update local life totals and alive status
   if [ $tgt_id -eq $r1 ]; then